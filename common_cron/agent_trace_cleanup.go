@@ -0,0 +1,48 @@
+package common_cron
+
+import (
+	"context"
+	"log"
+	"wechat-robot-client/service"
+	"wechat-robot-client/vars"
+)
+
+type AgentTraceCleanupCron struct {
+	CronManager *CronManager
+}
+
+func NewAgentTraceCleanupCron(cronManager *CronManager) vars.CommonCronInstance {
+	return &AgentTraceCleanupCron{
+		CronManager: cronManager,
+	}
+}
+
+func (cron *AgentTraceCleanupCron) IsActive() bool {
+	return vars.AgentTraceRetentionDays > 0
+}
+
+func (cron *AgentTraceCleanupCron) Cron() error {
+	deleted, err := service.NewAgentTraceService(context.Background()).CleanupExpired(vars.AgentTraceRetentionDays)
+	if deleted > 0 {
+		log.Printf("已清理 %d 条过期的 AI 执行记录", deleted)
+	}
+	return err
+}
+
+func (cron *AgentTraceCleanupCron) Register() {
+	if !cron.IsActive() {
+		log.Println("AI 执行记录清理任务未启用")
+		return
+	}
+	err := cron.CronManager.AddJob(vars.AgentTraceCleanupCron, "30 4 * * *", func() {
+		log.Println("开始清理过期的 AI 执行记录")
+		if err := cron.Cron(); err != nil {
+			log.Printf("清理 AI 执行记录失败: %v", err)
+		}
+	})
+	if err != nil {
+		log.Printf("AI 执行记录清理任务注册失败: %v", err)
+		return
+	}
+	log.Println("AI 执行记录清理任务初始化成功")
+}
@@ -61,7 +61,7 @@ func (cron *GoodMorningCron) Cron() error {
 	}
 
 	crService := service.NewChatRoomService(context.Background())
-	msgService := service.NewMessageService(context.Background())
+	outboundService := service.NewOutboundMessageService(context.Background())
 	for _, setting := range chatRoomSettings {
 		summary, err := crService.GetChatRoomSummary(setting.ChatRoomID)
 		if err != nil {
@@ -80,12 +80,12 @@ func (cron *GoodMorningCron) Cron() error {
 			continue
 		}
 
-		_, err = msgService.MsgUploadImg(setting.ChatRoomID, image)
+		_, err = outboundService.EnqueueImage("每日早安", setting.ChatRoomID, image)
 		if err != nil {
-			log.Printf("群[%s]早安图片发送失败: %v", setting.ChatRoomID, err)
+			log.Printf("群[%s]早安图片入队失败: %v", setting.ChatRoomID, err)
 			continue
 		}
-		log.Printf("群[%s]早安图片发送成功", setting.ChatRoomID)
+		log.Printf("群[%s]早安图片已加入发送队列", setting.ChatRoomID)
 	}
 
 	return nil
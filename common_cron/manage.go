@@ -72,6 +72,9 @@ func (m *CronManager) Start() {
 		m.scheduler.StartAsync()
 		m.isRunning = true
 	}
+	// 清理过期的 AI 执行记录，不依赖登录状态
+	agentTraceCleanupCron := NewAgentTraceCleanupCron(m)
+	agentTraceCleanupCron.Register()
 	// 为空的时候，是从未扫码登陆的时候
 	if vars.RobotRuntime.WxID != "" {
 		// 为 nil 的时候，是从未扫码登陆的时候
@@ -101,6 +104,8 @@ func (m *CronManager) Start() {
 			chatRoomRankingMonthCron := NewChatRoomRankingMonthCron(m)
 			chatRoomRankingMonthCron.Register()
 		}
+		// 定时消息
+		service.NewScheduledMessageService(context.Background()).RegisterAll()
 	}
 }
 
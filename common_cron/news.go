@@ -3,9 +3,7 @@ package common_cron
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
-	"os"
 	"strings"
 	"wechat-robot-client/service"
 	"wechat-robot-client/vars"
@@ -66,48 +64,23 @@ func (cron *NewsCron) Cron() error {
 		return fmt.Errorf("获取每日早报失败: %s", newsResp.Msg)
 	}
 
-	msgService := service.NewMessageService(context.Background())
+	outboundService := service.NewOutboundMessageService(context.Background())
 	newsText := strings.Join(newsResp.News, "\n")
 
-	newsImage := newsResp.Image
-	resp, err := resty.New().R().SetDoNotParseResponse(true).Get(newsImage)
-	if err != nil {
-		return err
-	}
-	defer resp.RawBody().Close()
-	// 创建临时文件
-	tempFile, err := os.CreateTemp("", "news_image_*")
-	if err != nil {
-		return err
-	}
-	defer tempFile.Close()
-	defer os.Remove(tempFile.Name()) // 清理临时文件
-	// 将图片数据写入临时文件
-	_, err = io.Copy(tempFile, resp.RawBody())
-	if err != nil {
-		return err
-	}
-
 	for _, setting := range settings {
 		newsType := globalSettings.NewsType
 		if setting.NewsType != nil && *setting.NewsType != "" {
 			newsType = *setting.NewsType
 		}
 		if newsType == "text" {
-			err := msgService.SendTextMessage(setting.ChatRoomID, newsText)
+			_, err := outboundService.EnqueueText("每日早报", setting.ChatRoomID, newsText)
 			if err != nil {
-				log.Printf("[每日早报] 发送文本消息失败: %v", err)
+				log.Printf("[每日早报] 文本消息入队失败: %v", err)
 			}
 		} else {
-			// 重置文件指针到开始位置
-			_, err := tempFile.Seek(0, 0)
-			if err != nil {
-				log.Printf("[每日早报] 重置文件指针失败: %v", err)
-				continue
-			}
-			_, err = msgService.MsgUploadImg(setting.ChatRoomID, tempFile)
+			_, err := outboundService.EnqueueImageURL("每日早报", setting.ChatRoomID, newsResp.Image)
 			if err != nil {
-				log.Printf("[每日早报] 发送图片消息失败: %v", err)
+				log.Printf("[每日早报] 图片消息入队失败: %v", err)
 			}
 		}
 	}
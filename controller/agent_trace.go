@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"errors"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AgentTrace struct{}
+
+func NewAgentTraceController() *AgentTrace {
+	return &AgentTrace{}
+}
+
+func (a *AgentTrace) GetList(c *gin.Context) {
+	var req dto.AgentTraceListRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	pager := appx.InitPager(c)
+	list, total, err := service.NewAgentTraceService(c).GetList(req, pager)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponseList(list, total)
+}
+
+func (a *AgentTrace) GetDetail(c *gin.Context) {
+	var req dto.AgentTraceDetailRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	trace, err := service.NewAgentTraceService(c).GetDetail(req.ID)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(trace)
+}
+
+func (a *AgentTrace) Replay(c *gin.Context) {
+	var req dto.AgentTraceReplayRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	trace, err := service.NewAgentTraceService(c).Replay(req)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(trace)
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/service"
+)
+
+type AIProvider struct{}
+
+func NewAIProviderController() *AIProvider {
+	return &AIProvider{}
+}
+
+func (a *AIProvider) GetAIProviders(c *gin.Context) {
+	resp := appx.NewResponse(c)
+	data, err := service.NewAIProviderService(c).GetAIProviders()
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(data)
+}
+
+func (a *AIProvider) CreateAIProvider(c *gin.Context) {
+	var req model.AIProvider
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	if err := service.NewAIProviderService(c).CreateAIProvider(&req); err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(nil)
+}
+
+func (a *AIProvider) UpdateAIProvider(c *gin.Context) {
+	var req model.AIProvider
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	if err := service.NewAIProviderService(c).UpdateAIProvider(&req); err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(nil)
+}
+
+func (a *AIProvider) DeleteAIProvider(c *gin.Context) {
+	var req struct {
+		ID int64 `form:"id" json:"id" binding:"required"`
+	}
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	if err := service.NewAIProviderService(c).DeleteAIProvider(req.ID); err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(nil)
+}
@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"errors"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AIUsage struct{}
+
+func NewAIUsageController() *AIUsage {
+	return &AIUsage{}
+}
+
+func (a *AIUsage) GetList(c *gin.Context) {
+	var req dto.AIUsageListRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	pager := appx.InitPager(c)
+	list, total, err := service.NewAIUsageService(c).GetList(req, pager)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponseList(list, total)
+}
+
+func (a *AIUsage) GetDailySummary(c *gin.Context) {
+	var req dto.AIUsageListRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	list, err := service.NewAIUsageService(c).GetDailySummary(req)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(list)
+}
@@ -31,3 +31,19 @@ func (ch *ChatHistory) GetChatHistory(c *gin.Context) {
 	}
 	resp.ToResponseList(list, total)
 }
+
+func (ch *ChatHistory) GetRecalledMessages(c *gin.Context) {
+	var req dto.RecalledMessageRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	pager := appx.InitPager(c)
+	list, total, err := service.NewChatHistoryService(c).GetRecalledMessages(req, pager)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponseList(list, total)
+}
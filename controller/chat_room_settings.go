@@ -6,6 +6,7 @@ import (
 	"wechat-robot-client/model"
 	"wechat-robot-client/pkg/appx"
 	"wechat-robot-client/service"
+	"wechat-robot-client/vars"
 
 	"github.com/gin-gonic/gin"
 )
@@ -98,3 +99,21 @@ func (ct *ChatRoomSettings) SaveChatRoomSettings(c *gin.Context) {
 	}
 	resp.ToResponse(nil)
 }
+
+// GetCommands 获取全部已注册的指令，用于群聊配置中启用/禁用指令
+func (ct *ChatRoomSettings) GetCommands(c *gin.Context) {
+	resp := appx.NewResponse(c)
+	commands := vars.MessagePlugin.Commands.Commands()
+	result := make([]dto.CommandInfo, 0, len(commands))
+	for _, cmd := range commands {
+		result = append(result, dto.CommandInfo{
+			Name:         cmd.Name,
+			Aliases:      cmd.Aliases,
+			Usage:        cmd.Usage(),
+			Description:  cmd.Description,
+			Role:         cmd.Role.String(),
+			ChatRoomOnly: cmd.ChatRoomOnly,
+		})
+	}
+	resp.ToResponse(result)
+}
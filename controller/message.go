@@ -46,6 +46,21 @@ func (m *Message) MessageRevoke(c *gin.Context) {
 	resp.ToResponse(nil)
 }
 
+func (m *Message) GetIngestStats(c *gin.Context) {
+	resp := appx.NewResponse(c)
+	resp.ToResponse(service.GetMessageIngestStats())
+}
+
+func (m *Message) GetQueueStats(c *gin.Context) {
+	var req dto.MessageQueueStatsRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	resp.ToResponse(service.GetMessageDispatcher().Stats(req.Limit))
+}
+
 func (m *Message) SendTextMessage(c *gin.Context) {
 	var req dto.SendTextMessageRequest
 	resp := appx.NewResponse(c)
@@ -355,6 +370,35 @@ func (m *Message) SendAppMessage(c *gin.Context) {
 	resp.ToResponse(nil)
 }
 
+// SendQuoteMessage 引用一条消息进行回复
+func (m *Message) SendQuoteMessage(c *gin.Context) {
+	var req dto.SendQuoteMessageRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	err := service.NewMessageService(c).ReplyToMessageByID(req.MessageID, req.Content)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(nil)
+}
+
+// SendReplyMessage 按顺序发送多条不同类型的消息，返回每一条的发送结果
+func (m *Message) SendReplyMessage(c *gin.Context) {
+	var req dto.SendReplyRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	// 接口调用不支持文本转语音，语音消息需要提供音频地址
+	results := service.NewMessageService(c).SendReplyParts(req.ToWxID, req.Parts, nil)
+	resp.ToResponse(results)
+}
+
 func (m *Message) SendEmojiMessage(c *gin.Context) {
 	var req dto.SendEmojiMessageRequest
 	resp := appx.NewResponse(c)
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"errors"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OutboundMessage struct{}
+
+func NewOutboundMessageController() *OutboundMessage {
+	return &OutboundMessage{}
+}
+
+func (o *OutboundMessage) GetList(c *gin.Context) {
+	var req dto.OutboundMessageListRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	pager := appx.InitPager(c)
+	list, total, err := service.NewOutboundMessageService(c).GetList(req, pager)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponseList(list, total)
+}
+
+func (o *OutboundMessage) GetAttempts(c *gin.Context) {
+	var req dto.OutboundMessageAttemptsRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	attempts, err := service.NewOutboundMessageService(c).GetAttempts(req.ID)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(attempts)
+}
+
+func (o *OutboundMessage) Redrive(c *gin.Context) {
+	var req dto.OutboundMessageRedriveRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	affected, err := service.NewOutboundMessageService(c).Redrive(req.IDs)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(affected)
+}
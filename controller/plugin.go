@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"errors"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Plugin struct{}
+
+func NewPluginController() *Plugin {
+	return &Plugin{}
+}
+
+// GetPlugins 获取已注册的插件及启用状态
+func (p *Plugin) GetPlugins(c *gin.Context) {
+	var req dto.PluginListRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	plugins, err := service.NewPluginService(c).GetPlugins(req)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(plugins)
+}
+
+// TogglePlugin 启用/禁用插件
+func (p *Plugin) TogglePlugin(c *gin.Context) {
+	var req dto.PluginToggleRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	if req.ChatRoomID != "" && req.ContactID != "" {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	err := service.NewPluginService(c).TogglePlugin(req)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(nil)
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"errors"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RobotSession struct{}
+
+func NewRobotSessionController() *RobotSession {
+	return &RobotSession{}
+}
+
+func (r *RobotSession) GetState(c *gin.Context) {
+	resp := appx.NewResponse(c)
+	resp.ToResponse(service.NewRobotSessionService(c).GetStatus())
+}
+
+func (r *RobotSession) GetHistory(c *gin.Context) {
+	var req dto.RobotSessionHistoryRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	pager := appx.InitPager(c)
+	list, total, err := service.NewRobotSessionService(c).GetHistory(req, pager)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponseList(list, total)
+}
+
+func (r *RobotSession) Reconnect(c *gin.Context) {
+	resp := appx.NewResponse(c)
+	if err := service.NewRobotSessionService(c).Reconnect(); err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(nil)
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"errors"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScheduledMessage struct{}
+
+func NewScheduledMessageController() *ScheduledMessage {
+	return &ScheduledMessage{}
+}
+
+func (sm *ScheduledMessage) GetList(c *gin.Context) {
+	var req dto.ScheduledMessageListRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	pager := appx.InitPager(c)
+	list, total, err := service.NewScheduledMessageService(c).GetList(req, pager)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponseList(list, total)
+}
+
+func (sm *ScheduledMessage) Create(c *gin.Context) {
+	var req dto.ScheduledMessageRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	job, err := service.NewScheduledMessageService(c).Create(req)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(job)
+}
+
+func (sm *ScheduledMessage) Update(c *gin.Context) {
+	var req dto.ScheduledMessageRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil || req.ID == 0 {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	job, err := service.NewScheduledMessageService(c).Update(req)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(job)
+}
+
+func (sm *ScheduledMessage) Cancel(c *gin.Context) {
+	var req dto.ScheduledMessageIDRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	if err := service.NewScheduledMessageService(c).Cancel(req.ID); err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(nil)
+}
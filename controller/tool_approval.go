@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"errors"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ToolApproval struct{}
+
+func NewToolApprovalController() *ToolApproval {
+	return &ToolApproval{}
+}
+
+func (t *ToolApproval) GetList(c *gin.Context) {
+	var req dto.ToolApprovalListRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	pager := appx.InitPager(c)
+	list, total, err := service.NewToolApprovalService(c).GetList(req, pager)
+	if err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponseList(list, total)
+}
+
+func (t *ToolApproval) Decide(c *gin.Context) {
+	var req dto.ToolApprovalDecideRequest
+	resp := appx.NewResponse(c)
+	if ok, err := appx.BindAndValid(c, &req); !ok || err != nil {
+		resp.ToErrorResponse(errors.New("参数错误"))
+		return
+	}
+	if err := service.NewToolApprovalService(c).Decide(req.ID, *req.Approved, ""); err != nil {
+		resp.ToErrorResponse(err)
+		return
+	}
+	resp.ToResponse(nil)
+}
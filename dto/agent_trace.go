@@ -0,0 +1,18 @@
+package dto
+
+type AgentTraceListRequest struct {
+	MessageID int64  `form:"message_id" json:"message_id"`
+	ChatID    string `form:"chat_id" json:"chat_id"`
+	Status    string `form:"status" json:"status"`
+	ReplayOf  int64  `form:"replay_of" json:"replay_of"`
+}
+
+type AgentTraceDetailRequest struct {
+	ID int64 `form:"id" json:"id" binding:"required"`
+}
+
+// AgentTraceReplayRequest 用历史记录的输入重新执行一次，Model 为空时使用原来的模型
+type AgentTraceReplayRequest struct {
+	ID    int64  `form:"id" json:"id" binding:"required"`
+	Model string `form:"model" json:"model"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+// AIUsageListRequest 日期格式为 2006-01-02
+type AIUsageListRequest struct {
+	StartDate  string `form:"start_date" json:"start_date"`
+	EndDate    string `form:"end_date" json:"end_date"`
+	ChatID     string `form:"chat_id" json:"chat_id"`
+	SenderWxID string `form:"sender_wxid" json:"sender_wxid"`
+	Feature    string `form:"feature" json:"feature"`
+}
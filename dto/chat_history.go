@@ -7,3 +7,30 @@ type ChatHistoryRequest struct {
 	TimeStart      int64  `form:"time_start" json:"time_start"`
 	TimeEnd        int64  `form:"time_end" json:"time_end"`
 }
+
+type RecalledMessageRequest struct {
+	ChatRoomID string `form:"chat_room_id" json:"chat_room_id" binding:"required"`
+	SenderWxID string `form:"sender_wxid" json:"sender_wxid"`
+	TimeStart  int64  `form:"time_start" json:"time_start"`
+	TimeEnd    int64  `form:"time_end" json:"time_end"`
+}
+
+type RecalledMessage struct {
+	ID                 int64  `json:"id"`
+	MessageID          int64  `json:"message_id"`
+	MsgID              int64  `json:"msg_id"`
+	FromWxID           string `gorm:"column:from_wxid" json:"from_wxid"`
+	SenderWxID         string `gorm:"column:sender_wxid" json:"sender_wxid"`
+	SenderNickname     string `json:"sender_nickname"`
+	RecalledBy         string `json:"recalled_by"`
+	RecalledByNickname string `json:"recalled_by_nickname"`
+	Type               int    `json:"type"`
+	AppMsgType         int    `json:"app_msg_type"`
+	Content            string `json:"content"`
+	Transcript         string `json:"transcript"`
+	AttachmentUrl      string `json:"attachment_url"`
+	ReplaceMsg         string `json:"replace_msg"`
+	Notified           bool   `json:"notified"`
+	MessageCreatedAt   int64  `json:"message_created_at"`
+	RecalledAt         int64  `json:"recalled_at"`
+}
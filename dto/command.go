@@ -0,0 +1,10 @@
+package dto
+
+type CommandInfo struct {
+	Name         string   `json:"name"`
+	Aliases      []string `json:"aliases"`
+	Usage        string   `json:"usage"`
+	Description  string   `json:"description"`
+	Role         string   `json:"role"`
+	ChatRoomOnly bool     `json:"chat_room_only"`
+}
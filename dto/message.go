@@ -35,6 +35,11 @@ type SendAppMessageRequest struct {
 	XML  string `form:"xml" json:"xml" binding:"required"`
 }
 
+type SendQuoteMessageRequest struct {
+	MessageID int64  `form:"message_id" json:"message_id" binding:"required"`
+	Content   string `form:"content" json:"content" binding:"required"`
+}
+
 type SendEmojiMessageRequest struct {
 	SendMessageCommonRequest
 	Md5      string `form:"Md5" json:"Md5" binding:"required"`
@@ -80,3 +85,33 @@ type SendFileMessageRequest struct {
 	ChunkIndex      int64  `form:"chunk_index" json:"chunk_index"`
 	TotalChunks     int64  `form:"total_chunks" json:"total_chunks" binding:"required"`
 }
+
+type MessageIngestStats struct {
+	Received   int64 `json:"received"`   // 收到的同步消息数
+	Accepted   int64 `json:"accepted"`   // 去重后入库的消息数
+	Duplicated int64 `json:"duplicated"` // 因重复被丢弃的消息数
+	Fallback   int64 `json:"fallback"`   // Redis 不可用时回退到数据库去重的次数
+}
+
+type MessageQueueConversation struct {
+	FromWxID   string `json:"from_wxid"`
+	Pending    int    `json:"pending"`     // 等待处理的消息数
+	Running    bool   `json:"running"`     // 是否正在处理该会话的消息
+	OldestWait int64  `json:"oldest_wait"` // 最早一条待处理消息的等待时间(秒)
+}
+
+type MessageQueueStats struct {
+	Workers          int                        `json:"workers"`
+	Busy             int                        `json:"busy"`    // 正在处理消息的 worker 数量
+	Pending          int                        `json:"pending"` // 队列中等待处理的消息总数
+	Capacity         int                        `json:"capacity"`
+	ConversationSize int                        `json:"conversation_size"`
+	OverflowPolicy   string                     `json:"overflow_policy"`
+	Processed        int64                      `json:"processed"`
+	Dropped          int64                      `json:"dropped"`
+	Conversations    []MessageQueueConversation `json:"conversations"`
+}
+
+type MessageQueueStatsRequest struct {
+	Limit int `form:"limit" json:"limit"`
+}
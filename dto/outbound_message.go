@@ -0,0 +1,14 @@
+package dto
+
+type OutboundMessageListRequest struct {
+	ToWxID string `form:"to_wxid" json:"to_wxid"`
+	Status string `form:"status" json:"status"` // 为空时查询死信消息
+}
+
+type OutboundMessageRedriveRequest struct {
+	IDs []int64 `form:"ids" json:"ids" binding:"required"`
+}
+
+type OutboundMessageAttemptsRequest struct {
+	ID int64 `form:"id" json:"id" binding:"required"`
+}
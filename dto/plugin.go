@@ -0,0 +1,24 @@
+package dto
+
+// PluginListRequest 查询插件列表，chat_room_id 与 contact_id 都为空时查询全局默认开关
+type PluginListRequest struct {
+	ChatRoomID string `form:"chat_room_id" json:"chat_room_id"`
+	ContactID  string `form:"contact_id" json:"contact_id"`
+}
+
+// PluginToggleRequest 切换插件开关，name 为插件名称或 label:标签，enabled 为空时删除该开关配置
+type PluginToggleRequest struct {
+	ChatRoomID string `form:"chat_room_id" json:"chat_room_id"`
+	ContactID  string `form:"contact_id" json:"contact_id"`
+	Name       string `form:"name" json:"name" binding:"required"`
+	Enabled    *bool  `form:"enabled" json:"enabled"`
+}
+
+type PluginInfo struct {
+	Name         string   `json:"name"`
+	Labels       []string `json:"labels"`
+	Priority     int      `json:"priority"`
+	Enabled      bool     `json:"enabled"`       // 最终生效的开关
+	Switch       *bool    `json:"switch"`        // 当前群聊/好友的插件名称开关配置，未配置为空
+	GlobalSwitch *bool    `json:"global_switch"` // 全局默认的插件名称开关配置，未配置为空
+}
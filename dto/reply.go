@@ -0,0 +1,42 @@
+package dto
+
+type ReplyPartType string
+
+const (
+	ReplyPartTypeText  ReplyPartType = "text"
+	ReplyPartTypeImage ReplyPartType = "image"
+	ReplyPartTypeVideo ReplyPartType = "video"
+	ReplyPartTypeVoice ReplyPartType = "voice" // 有 url 时发送远程音频，否则将 text 转换为语音
+	ReplyPartTypeFile  ReplyPartType = "file"
+	ReplyPartTypeApp   ReplyPartType = "app"
+)
+
+// ReplyPart 结构化回复中的一条消息
+type ReplyPart struct {
+	Type           ReplyPartType `json:"type"`
+	Text           string        `json:"text,omitempty"`
+	URL            string        `json:"url,omitempty"`
+	AppType        int           `json:"app_type,omitempty"`
+	AppXML         string        `json:"app_xml,omitempty"`
+	At             []string      `json:"at,omitempty"`               // 文本消息需要@的微信ID
+	QuoteMessageID int64         `json:"quote_message_id,omitempty"` // 文本消息引用回复的消息，对应消息表主键ID
+}
+
+// StructuredReply AI 通过 reply 工具给出的结构化回复，各部分按顺序发送
+type StructuredReply struct {
+	Parts []ReplyPart `json:"parts"`
+}
+
+type SendReplyRequest struct {
+	ToWxID string      `form:"to_wxid" json:"to_wxid" binding:"required"`
+	Parts  []ReplyPart `form:"parts" json:"parts" binding:"required"`
+}
+
+// ReplyPartResult 结构化回复中每一部分的发送结果
+type ReplyPartResult struct {
+	Index     int           `json:"index"`
+	Type      ReplyPartType `json:"type"`
+	Success   bool          `json:"success"`
+	Error     string        `json:"error,omitempty"`
+	MessageID int64         `json:"message_id,omitempty"`
+}
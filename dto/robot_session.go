@@ -0,0 +1,24 @@
+package dto
+
+type RobotSessionHistoryRequest struct {
+	State     string `form:"state" json:"state"`
+	StartTime int64  `form:"start_time" json:"start_time"`
+	EndTime   int64  `form:"end_time" json:"end_time"`
+}
+
+// RobotSessionStatus 机器人会话的当前状态
+type RobotSessionStatus struct {
+	State    string `json:"state"`
+	Since    int64  `json:"since"`    // 进入当前状态的时间
+	Failures int    `json:"failures"` // 连续心跳失败次数
+	Attempts int    `json:"attempts"` // 本轮已尝试重连的次数
+}
+
+// RobotSessionWebhook 会话状态变更时推送给 webhook 的内容
+type RobotSessionWebhook struct {
+	Event     string `json:"event"`
+	FromState string `json:"from_state"`
+	ToState   string `json:"to_state"`
+	Reason    string `json:"reason"`
+	CreatedAt int64  `json:"created_at"`
+}
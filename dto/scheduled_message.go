@@ -0,0 +1,24 @@
+package dto
+
+type ScheduledMessageRequest struct {
+	ID           int64    `form:"id" json:"id"`
+	Name         string   `form:"name" json:"name"`
+	ToWxIDs      []string `form:"to_wxids" json:"to_wxids" binding:"required,min=1"`
+	Type         string   `form:"type" json:"type" binding:"required,oneof=text image file app"`
+	Content      string   `form:"content" json:"content"`
+	URL          string   `form:"url" json:"url"`
+	AppMsgType   int      `form:"app_msg_type" json:"app_msg_type"`
+	ScheduleType string   `form:"schedule_type" json:"schedule_type" binding:"required,oneof=once cron"`
+	SendAt       int64    `form:"send_at" json:"send_at"`
+	CronExpr     string   `form:"cron_expr" json:"cron_expr"`
+	Enabled      *bool    `form:"enabled" json:"enabled"`
+}
+
+type ScheduledMessageListRequest struct {
+	ScheduleType string `form:"schedule_type" json:"schedule_type"`
+	Enabled      *bool  `form:"enabled" json:"enabled"`
+}
+
+type ScheduledMessageIDRequest struct {
+	ID int64 `form:"id" json:"id" binding:"required"`
+}
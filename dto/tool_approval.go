@@ -0,0 +1,11 @@
+package dto
+
+type ToolApprovalListRequest struct {
+	Status string `form:"status" json:"status"`
+	ChatID string `form:"chat_id" json:"chat_id"`
+}
+
+type ToolApprovalDecideRequest struct {
+	ID       int64 `form:"id" json:"id" binding:"required"`
+	Approved *bool `form:"approved" json:"approved" binding:"required"`
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/openai/openai-go/v3"
 
+	"wechat-robot-client/dto"
+	"wechat-robot-client/pkg/airouter"
 	"wechat-robot-client/pkg/mcp"
 	"wechat-robot-client/pkg/robotctx"
 	"wechat-robot-client/pkg/skills"
@@ -19,7 +21,22 @@ type AgentService interface {
 	GetAllTools(robotCtx *robotctx.RobotContext) ([]openai.ChatCompletionToolUnionParam, error)
 	ChatWithTools(
 		robotCtx *robotctx.RobotContext,
-		client *openai.Client,
+		router *airouter.Router,
 		req openai.ChatCompletionNewParams,
-	) (openai.ChatCompletionMessage, error)
+		streamHandler ChatStreamHandler,
+	) (AgentReply, error)
+}
+
+// AgentReply 对话结果，Reply 只由 reply 工具设置，不为空时按顺序发送其中的各部分，不再发送 Content
+type AgentReply struct {
+	openai.ChatCompletionMessage
+	Reply *dto.StructuredReply
+}
+
+// ChatStreamHandler 接收流式对话过程中的回调，用于边生成边发送回复
+type ChatStreamHandler interface {
+	// OnContent 收到回复内容的增量
+	OnContent(delta string)
+	// OnToolCalls 本轮回复需要调用工具，在执行工具前回调
+	OnToolCalls()
 }
@@ -0,0 +1,9 @@
+package ai
+
+import "context"
+
+// ASRProvider 语音识别服务接口
+type ASRProvider interface {
+	// Transcribe 将音频转写为文本，filename 的扩展名用于识别音频格式
+	Transcribe(ctx context.Context, audio []byte, filename string) (string, error)
+}
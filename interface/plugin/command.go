@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommandPrefix 指令前缀
+const CommandPrefix = "#"
+
+// CommandRole 执行指令所需的角色
+type CommandRole int
+
+const (
+	RoleAnyone     CommandRole = iota // 任何人
+	RoleGroupAdmin                    // 群管理员，私聊中只有机器人主人可以执行
+	RoleRobotOwner                    // 机器人主人，即机器人自己的微信账号（从其他设备发送）
+)
+
+func (r CommandRole) String() string {
+	switch r {
+	case RoleGroupAdmin:
+		return "群管理员"
+	case RoleRobotOwner:
+		return "机器人主人"
+	default:
+		return "所有人"
+	}
+}
+
+// CommandArgType 指令参数类型
+type CommandArgType int
+
+const (
+	ArgString CommandArgType = iota // 单个参数，以空白分隔
+	ArgInt                          // 整数参数
+	ArgText                         // 剩余的全部文本，只能作为最后一个参数
+)
+
+// CommandArg 指令参数声明
+type CommandArg struct {
+	Name        string
+	Type        CommandArgType
+	Required    bool
+	Description string
+}
+
+// CommandArgs 解析后的指令参数，key 为参数名称
+type CommandArgs map[string]any
+
+func (a CommandArgs) Has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+func (a CommandArgs) String(name string) string {
+	if v, ok := a[name].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func (a CommandArgs) Int(name string) int {
+	if v, ok := a[name].(int); ok {
+		return v
+	}
+	return 0
+}
+
+// CommandHandler 指令处理函数，返回的错误会以文本形式回复给发送者
+type CommandHandler func(ctx *MessageContext, args CommandArgs) error
+
+// Command 声明式指令
+type Command struct {
+	Name         string   // 指令名称，不带 # 前缀
+	Aliases      []string // 指令别名
+	Args         []CommandArg
+	Role         CommandRole
+	Description  string
+	ChatRoomOnly bool // 仅群聊可用
+	RequireRefer bool // 需要引用一条消息
+	Handler      CommandHandler
+}
+
+// Usage 生成指令用法，必填参数使用 <>，可选参数使用 []
+func (c *Command) Usage() string {
+	var builder strings.Builder
+	builder.WriteString(CommandPrefix + c.Name)
+	for _, arg := range c.Args {
+		if arg.Required {
+			fmt.Fprintf(&builder, " <%s>", arg.Name)
+		} else {
+			fmt.Fprintf(&builder, " [%s]", arg.Name)
+		}
+	}
+	return builder.String()
+}
+
+// Names 指令名称及全部别名
+func (c *Command) Names() []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
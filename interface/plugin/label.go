@@ -0,0 +1,83 @@
+package plugin
+
+import "wechat-robot-client/model"
+
+// 消息分发使用的插件标签，每种消息类型（及应用消息子类型）对应一个标签
+const (
+	LabelText         = "text"
+	LabelImage        = "image"
+	LabelVoice        = "voice"
+	LabelVideo        = "video"
+	LabelEmoji        = "emoji"
+	LabelShareCard    = "share-card"
+	LabelLocation     = "location"
+	LabelVerify       = "verify"
+	LabelSystem       = "system"
+	LabelPrompt       = "prompt"
+	LabelPat          = "pat"
+	LabelRecall       = "recall" // 消息撤回
+	LabelRedEnvelopes = "red-envelopes"
+	LabelLink         = "link"
+	LabelFile         = "file"
+	LabelMusic        = "music"
+	LabelMiniProgram  = "mini-program"
+	LabelTransfer     = "transfer"
+	LabelChatHistory  = "chat-history"
+	LabelApp          = "app" // 其他应用消息
+)
+
+// MessageLabel 根据消息类型获取分发标签，无法分发的消息类型返回空字符串
+func MessageLabel(message *model.Message) string {
+	switch message.Type {
+	case model.MsgTypeText:
+		return LabelText
+	case model.MsgTypeImage:
+		return LabelImage
+	case model.MsgTypeVoice:
+		return LabelVoice
+	case model.MsgTypeVideo, model.MsgTypeMicroVideo:
+		return LabelVideo
+	case model.MsgTypeEmoticon:
+		return LabelEmoji
+	case model.MsgTypeShareCard:
+		return LabelShareCard
+	case model.MsgTypeLocation:
+		return LabelLocation
+	case model.MsgTypeVerify:
+		return LabelVerify
+	case model.MsgTypeSystem:
+		return LabelSystem
+	case model.MsgTypePrompt:
+		return LabelPrompt
+	case model.MsgTypeApp:
+		return AppMessageLabel(message.AppMsgType)
+	default:
+		return ""
+	}
+}
+
+// AppMessageLabel 应用消息子类型对应的分发标签，引用消息按文本消息处理，礼物消息和红包一起处理
+func AppMessageLabel(appMsgType model.AppMessageType) string {
+	switch appMsgType {
+	case model.AppMsgTypequote:
+		return LabelText
+	case model.AppMsgTypeUrl, model.AppMsgTypeVideo:
+		return LabelLink
+	case model.AppMsgTypeAttach:
+		return LabelFile
+	case model.AppMsgTypeAudio, model.AppMsgTypeMusic:
+		return LabelMusic
+	case model.AppMsgTypeMiniProgram, model.AppMsgTypeMiniProgramPage:
+		return LabelMiniProgram
+	case model.AppMsgTypeTransfers:
+		return LabelTransfer
+	case model.AppMsgTypeRedEnvelopes, model.AppMsgTypeEcsGift:
+		return LabelRedEnvelopes
+	case model.AppMsgTypeChatHistory:
+		return LabelChatHistory
+	case model.AppMsgTypeEmoji:
+		return LabelEmoji
+	default:
+		return LabelApp
+	}
+}
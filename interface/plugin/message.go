@@ -17,6 +17,7 @@ type MessageServiceIface interface {
 	SendTextMessage(toWxID, content string, at ...string) error
 	SendLongTextMessage(toWxID string, longText string) error
 	SendAppMessage(toWxID string, appMsgType int, appMsgXml string) error
+	ReplyToMessage(referMessage *model.Message, content string) error
 	MsgUploadImg(toWxID string, image io.Reader) (*model.Message, error)
 	SendImageMessageByLocalPath(toWxID string, imagePath string) error
 	SendImageMessageByRemoteURL(toWxID string, imageURL string) error
@@ -38,6 +39,7 @@ type MessageServiceIface interface {
 	ChatRoomAIDisabled(chatRoomID string) error
 	GetChatRoomMember(chatRoomID string, wechatID string) (*model.ChatRoomMember, error)
 	ToolsCompleted(toWxID, replyWxID string) error
+	SendReplyParts(toWxID string, parts []dto.ReplyPart, synthesize func(text string) ([]byte, string, error)) []dto.ReplyPartResult
 }
 
 type MessageContext struct {
@@ -48,13 +50,81 @@ type MessageContext struct {
 	Pat            bool
 	ReferMessage   *model.Message
 	MessageService MessageServiceIface
+
+	// 以下为按消息类型解析后的消息体，只有对应类型的消息才会填充
+	ShareCard     *robot.ShareCardMessage
+	Emoji         *robot.EmojiMessage // 表情类应用消息不会填充，消息体在 AppMessage.Emoji
+	Video         *robot.VideoSecretXml
+	Location      *robot.LocationMessage
+	AppMessage    *robot.AppMessage
+	SystemMessage *robot.SystemMessage
+	FriendVerify  *robot.NewFriendMessage
+	Recall        *model.MessageRecall // 撤回通知对应的撤回记录
+
+	senderMember       *model.ChatRoomMember
+	senderMemberLoaded bool
+}
+
+// GetSenderChatRoomMember 获取群聊消息发送者的群成员信息，同一条消息只查询一次
+func (ctx *MessageContext) GetSenderChatRoomMember() (*model.ChatRoomMember, error) {
+	if ctx.senderMemberLoaded {
+		return ctx.senderMember, nil
+	}
+	chatRoomMember, err := ctx.MessageService.GetChatRoomMember(ctx.Message.FromWxID, ctx.Message.SenderWxID)
+	if err != nil {
+		return nil, err
+	}
+	ctx.senderMember = chatRoomMember
+	ctx.senderMemberLoaded = true
+	return chatRoomMember, nil
+}
+
+// 插件优先级，数值越大越先执行
+const (
+	PriorityFallback = -100 // 兜底插件，例如 AI 聊天
+	PriorityDefault  = 0
+	PriorityParser   = 50  // 链接解析类插件
+	PriorityCommand  = 100 // 指令类插件
+)
+
+// RunResult 插件执行结果，决定插件链是否继续往下执行
+type RunResult int
+
+const (
+	RunContinue RunResult = iota // 继续执行后续插件
+	RunStop                      // 终止插件链，消息未被处理
+	RunConsumed                  // 消息已被当前插件认领处理，终止插件链
+)
+
+func (r RunResult) String() string {
+	switch r {
+	case RunContinue:
+		return "continue"
+	case RunStop:
+		return "stop"
+	case RunConsumed:
+		return "consumed"
+	default:
+		return "unknown"
+	}
 }
 
+// MessageHandler 消息插件，由分发器依次调用 PreAction、Run、PostAction
 type MessageHandler interface {
 	GetName() string
 	GetLabels() []string
+	GetPriority() int
 	Match(ctx *MessageContext) bool
+	// PreAction 执行前置检查，返回 false 时跳过当前插件，插件链继续
 	PreAction(ctx *MessageContext) bool
-	PostAction(ctx *MessageContext)
-	Run(ctx *MessageContext)
+	// PostAction 插件执行完成后调用，result 为 Run 的执行结果
+	PostAction(ctx *MessageContext, result RunResult)
+	Run(ctx *MessageContext) RunResult
+}
+
+// Interceptor 全局插件拦截器，以中间件的形式包裹插件的 PreAction/Run/PostAction，
+// 调用 next 继续执行，不调用 next 则直接以返回值作为插件的执行结果
+type Interceptor interface {
+	GetName() string
+	Intercept(handler MessageHandler, ctx *MessageContext, next func() RunResult) RunResult
 }
@@ -32,8 +32,12 @@ type Settings interface {
 	IsAIChatEnabled() bool
 	IsAIDrawingEnabled() bool
 	IsTTSEnabled() bool
+	IsAIStreamReplyEnabled() bool
+	GetAIReplyMode() model.AIReplyMode
 	IsShortVideoParsingEnabled() bool
 	IsAITrigger() bool
 	GetAITriggerWord() string
 	GetPatConfig() PatConfig
+	IsCommandEnabled(name string) bool
+	IsPluginEnabled(name string, labels []string) bool
 }
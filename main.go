@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"wechat-robot-client/model"
 	"wechat-robot-client/pkg/shutdown"
 	"wechat-robot-client/router"
+	"wechat-robot-client/service"
 	"wechat-robot-client/startup"
 	"wechat-robot-client/vars"
 
@@ -40,6 +42,12 @@ func main() {
 	if err := startup.InitWechatRobot(); err != nil {
 		log.Fatalf("启动微信机器人失败: %v", err)
 	}
+	// 启动出站消息发送队列
+	service.GetOutboundQueue().Start()
+	// 重启前等待审批的工具调用已经没有对话在等待了
+	if err := service.NewToolApprovalService(context.Background()).ExpireStale(); err != nil {
+		log.Printf("清理待审批的工具调用失败: %v", err)
+	}
 	// 初始化RAG & 记忆服务
 	if err := startup.InitRAGService(); err != nil {
 		log.Printf("[RAG] 初始化RAG服务失败（非致命）: %v", err)
@@ -82,6 +90,8 @@ func main() {
 	shutdownManager.Register(vars.RobotRuntime)
 	shutdownManager.Register(vars.CronManager)
 	shutdownManager.Register(vars.Agent)
+	shutdownManager.Register(service.GetMessageDispatcher())
+	shutdownManager.Register(service.GetOutboundQueue())
 	// 开始监听停止信号
 	shutdownManager.Start()
 	// 启动服务
@@ -0,0 +1,56 @@
+package model
+
+import "gorm.io/datatypes"
+
+type AgentTraceStatus string
+
+const (
+	AgentTraceStatusSuccess AgentTraceStatus = "success"
+	AgentTraceStatusFailed  AgentTraceStatus = "failed"
+)
+
+// AgentTrace 一次 AI 工具调用对话的完整记录，用于排查问题和回放
+type AgentTrace struct {
+	ID               int64            `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	MessageID        int64            `gorm:"not null;default:0;index;column:message_id;comment:触发对话的消息ID" json:"message_id"`
+	ChatID           string           `gorm:"type:varchar(64);not null;default:'';index;column:chat_id;comment:会话微信ID" json:"chat_id"`
+	SenderWxID       string           `gorm:"type:varchar(64);not null;default:'';column:sender_wxid;comment:发送者微信ID" json:"sender_wxid"`
+	Model            string           `gorm:"type:varchar(100);not null;default:'';column:model;comment:使用的模型" json:"model"`
+	ReplayOf         int64            `gorm:"not null;default:0;index;column:replay_of;comment:回放的原始记录ID，0表示不是回放" json:"replay_of"`
+	Status           AgentTraceStatus `gorm:"type:varchar(20);not null;default:'';index;column:status;comment:执行结果" json:"status"`
+	Error            string           `gorm:"type:text;column:error;comment:失败原因" json:"error"`
+	Input            datatypes.JSON   `gorm:"type:json;column:input;comment:输入的消息列表" json:"input"`
+	Tools            datatypes.JSON   `gorm:"type:json;column:tools;comment:提供给模型的工具名称列表" json:"tools"`
+	Steps            datatypes.JSON   `gorm:"type:json;column:steps;comment:每一轮模型输出和工具调用" json:"steps"`
+	Answer           string           `gorm:"type:mediumtext;column:answer;comment:最终回复" json:"answer"`
+	PromptTokens     int64            `gorm:"not null;default:0;column:prompt_tokens;comment:输入token数" json:"prompt_tokens"`
+	CompletionTokens int64            `gorm:"not null;default:0;column:completion_tokens;comment:输出token数" json:"completion_tokens"`
+	TotalTokens      int64            `gorm:"not null;default:0;column:total_tokens;comment:总token数" json:"total_tokens"`
+	DurationMs       int64            `gorm:"not null;default:0;column:duration_ms;comment:总耗时，单位毫秒" json:"duration_ms"`
+	CreatedAt        int64            `gorm:"autoCreateTime;not null;index;column:created_at" json:"created_at"`
+}
+
+func (AgentTrace) TableName() string {
+	return "agent_traces"
+}
+
+// AgentTraceStep 模型的一轮输出
+type AgentTraceStep struct {
+	Model            string               `json:"model,omitempty"` // 实际调用的模型，模型路由切换模型时和 AgentTrace.Model 不同
+	Content          string               `json:"content"`
+	Reasoning        string               `json:"reasoning,omitempty"`
+	ToolCalls        []AgentTraceToolCall `json:"tool_calls,omitempty"`
+	PromptTokens     int64                `json:"prompt_tokens"`
+	CompletionTokens int64                `json:"completion_tokens"`
+	LatencyMs        int64                `json:"latency_ms"`
+}
+
+// AgentTraceToolCall 一次工具调用
+type AgentTraceToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"encoding/json"
+
+	"gorm.io/datatypes"
+)
+
+// AIProvider OpenAI 兼容的服务商配置，模型路由通过名称引用
+type AIProvider struct {
+	ID        int64  `gorm:"column:id;primaryKey;autoIncrement;comment:AI服务商配置表主键ID" json:"id"`
+	Name      string `gorm:"column:name;type:varchar(64);not null;uniqueIndex;comment:服务商名称" json:"name" binding:"required"`
+	BaseURL   string `gorm:"column:base_url;type:varchar(255);not null;default:'';comment:服务商的基础URL地址" json:"base_url" binding:"required"`
+	APIKey    string `gorm:"column:api_key;type:varchar(255);not null;default:'';comment:服务商的API密钥" json:"api_key"`
+	Enabled   *bool  `gorm:"column:enabled;default:true;comment:是否启用" json:"enabled"`
+	Remark    string `gorm:"column:remark;type:varchar(255);not null;default:'';comment:备注" json:"remark"`
+	CreatedAt int64  `gorm:"autoCreateTime;not null;column:created_at" json:"created_at"`
+	UpdatedAt int64  `gorm:"autoUpdateTime;not null;column:updated_at" json:"updated_at"`
+}
+
+func (AIProvider) TableName() string {
+	return "ai_providers"
+}
+
+// AIRouteTarget 路由到的模型，Provider 为空时使用功能自己配置的服务商
+type AIRouteTarget struct {
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model"`
+}
+
+// AIRouting 模型路由配置，对 AI 对话、群聊总结、记忆提取和朋友圈评论都生效
+type AIRouting struct {
+	Fallbacks        []AIRouteTarget `json:"fallbacks,omitempty"`          // 首选模型失败后依次尝试的模型
+	Vision           *AIRouteTarget  `json:"vision,omitempty"`             // 输入包含图片时优先使用的模型
+	Tools            *AIRouteTarget  `json:"tools,omitempty"`              // 需要调用工具时优先使用的模型
+	LongContext      *AIRouteTarget  `json:"long_context,omitempty"`       // 输入超长时优先使用的模型
+	LongContextChars int             `json:"long_context_chars,omitempty"` // 输入超过多少字算超长
+	MaxRetries       *int            `json:"max_retries,omitempty"`        // 每个模型遇到限流、超时等错误时的重试次数，默认 2
+	RetryBackoff     int             `json:"retry_backoff,omitempty"`      // 第一次重试前等待的毫秒数，之后每次翻倍，默认 1000
+}
+
+// ParseAIRouting 解析模型路由配置，未配置时返回 nil
+func ParseAIRouting(data datatypes.JSON) (*AIRouting, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var routing AIRouting
+	if err := json.Unmarshal(data, &routing); err != nil {
+		return nil, err
+	}
+	return &routing, nil
+}
+
+// Targets 配置中引用的所有模型
+func (r *AIRouting) Targets() []*AIRouteTarget {
+	var targets []*AIRouteTarget
+	for i := range r.Fallbacks {
+		targets = append(targets, &r.Fallbacks[i])
+	}
+	for _, target := range []*AIRouteTarget{r.Vision, r.Tools, r.LongContext} {
+		if target != nil {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
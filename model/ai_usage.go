@@ -0,0 +1,98 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+
+	"gorm.io/datatypes"
+)
+
+type AIUsageFeature string
+
+const (
+	AIUsageFeatureChat      AIUsageFeature = "chat"      // AI 对话
+	AIUsageFeatureReplay    AIUsageFeature = "replay"    // 回放 AI 对话执行记录
+	AIUsageFeatureSummary   AIUsageFeature = "summary"   // 群聊总结
+	AIUsageFeatureMemory    AIUsageFeature = "memory"    // 长期记忆提取
+	AIUsageFeatureMoment    AIUsageFeature = "moment"    // 朋友圈评论
+	AIUsageFeatureEmbedding AIUsageFeature = "embedding" // 文本向量化
+	AIUsageFeatureOther     AIUsageFeature = "other"     // 其他
+)
+
+// AIUsage AI 接口用量，按天、会话、发送人、功能和模型汇总
+type AIUsage struct {
+	ID               int64          `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	Date             string         `gorm:"type:varchar(10);not null;default:'';uniqueIndex:uk_ai_usage,priority:1;column:date;comment:日期，格式 2006-01-02" json:"date"`
+	ChatID           string         `gorm:"type:varchar(64);not null;default:'';uniqueIndex:uk_ai_usage,priority:2;column:chat_id;comment:会话微信ID" json:"chat_id"`
+	SenderWxID       string         `gorm:"type:varchar(64);not null;default:'';uniqueIndex:uk_ai_usage,priority:3;column:sender_wxid;comment:发送人微信ID" json:"sender_wxid"`
+	Feature          AIUsageFeature `gorm:"type:varchar(20);not null;default:'';uniqueIndex:uk_ai_usage,priority:4;column:feature;comment:功能" json:"feature"`
+	Model            string         `gorm:"type:varchar(100);not null;default:'';uniqueIndex:uk_ai_usage,priority:5;column:model;comment:模型名称" json:"model"`
+	Calls            int64          `gorm:"not null;default:0;column:calls;comment:调用次数，AI 对话按一次提问计一次" json:"calls"`
+	PromptTokens     int64          `gorm:"not null;default:0;column:prompt_tokens;comment:输入 token 数" json:"prompt_tokens"`
+	CompletionTokens int64          `gorm:"not null;default:0;column:completion_tokens;comment:输出 token 数" json:"completion_tokens"`
+	TotalTokens      int64          `gorm:"not null;default:0;column:total_tokens;comment:总 token 数" json:"total_tokens"`
+	UpdatedAt        int64          `gorm:"autoUpdateTime;not null;column:updated_at" json:"updated_at"`
+}
+
+func (AIUsage) TableName() string {
+	return "ai_usages"
+}
+
+// DefaultAIQuotaMessage 超出额度时默认的回复
+const DefaultAIQuotaMessage = "今天的 AI 对话额度已经用完了，明天再来找我聊天吧~"
+
+// AIQuota AI 对话的每日额度，只统计 AI 对话功能的用量，0 表示不限制
+type AIQuota struct {
+	MemberDailyTokens   int64    `json:"member_daily_tokens,omitempty"`    // 每个群成员每天的 token 上限
+	MemberDailyCalls    int64    `json:"member_daily_calls,omitempty"`     // 每个群成员每天的提问次数上限
+	ChatRoomDailyTokens int64    `json:"chat_room_daily_tokens,omitempty"` // 每个群每天的 token 上限
+	ChatRoomDailyCalls  int64    `json:"chat_room_daily_calls,omitempty"`  // 每个群每天的提问次数上限
+	FriendDailyTokens   int64    `json:"friend_daily_tokens,omitempty"`    // 每个好友每天的 token 上限
+	FriendDailyCalls    int64    `json:"friend_daily_calls,omitempty"`     // 每个好友每天的提问次数上限
+	ScorePerCall        int64    `json:"score_per_call,omitempty"`         // 群成员超出个人额度后每次提问消耗的积分，0 表示不能用积分继续提问
+	Exempt              []string `json:"exempt,omitempty"`                 // 不受额度限制的微信ID
+	Message             string   `json:"message,omitempty"`                // 超出额度时的回复
+}
+
+// ParseAIQuota 解析 AI 用量额度，未配置时返回 nil
+func ParseAIQuota(data datatypes.JSON) (*AIQuota, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var quota AIQuota
+	if err := json.Unmarshal(data, &quota); err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+func (q *AIQuota) IsExempt(wechatID string) bool {
+	return slices.Contains(q.Exempt, wechatID)
+}
+
+func (q *AIQuota) GetMessage() string {
+	if q.Message != "" {
+		return q.Message
+	}
+	return DefaultAIQuotaMessage
+}
+
+// exceeded 用量达到上限，limit 为 0 表示不限制
+func exceeded(used, limit int64) bool {
+	return limit > 0 && used >= limit
+}
+
+// MemberExceeded 群成员今天的用量是否超出额度
+func (q *AIQuota) MemberExceeded(calls, tokens int64) bool {
+	return exceeded(calls, q.MemberDailyCalls) || exceeded(tokens, q.MemberDailyTokens)
+}
+
+// ChatRoomExceeded 群今天的用量是否超出额度
+func (q *AIQuota) ChatRoomExceeded(calls, tokens int64) bool {
+	return exceeded(calls, q.ChatRoomDailyCalls) || exceeded(tokens, q.ChatRoomDailyTokens)
+}
+
+// FriendExceeded 好友今天的用量是否超出额度
+func (q *AIQuota) FriendExceeded(calls, tokens int64) bool {
+	return exceeded(calls, q.FriendDailyCalls) || exceeded(tokens, q.FriendDailyTokens)
+}
@@ -6,6 +6,20 @@ import (
 	"gorm.io/datatypes"
 )
 
+type AntiRecallMode string
+
+const (
+	AntiRecallModeRepost  AntiRecallMode = "repost"  // 在群里重新发出被撤回的内容
+	AntiRecallModeForward AntiRecallMode = "forward" // 私聊转发给指定的管理员
+)
+
+type AIReplyMode string
+
+const (
+	AIReplyModeAt    AIReplyMode = "at"    // 发送文本消息并@提问的人
+	AIReplyModeQuote AIReplyMode = "quote" // 引用提问的消息进行回复
+)
+
 type ChatRoomSettings struct {
 	ID                        int64                `gorm:"column:id;primaryKey;autoIncrement;comment:群聊配置表主键ID" json:"id"`
 	ChatRoomID                string               `gorm:"column:chat_room_id;type:varchar(64);default:'';index:idx_chat_room_id;comment:群聊微信ID" json:"chat_room_id"`
@@ -22,6 +36,8 @@ type ChatRoomSettings struct {
 	TTSEnabled                *bool                `gorm:"column:tts_enabled;default:false;comment:是否启用AI文本转语音功能" json:"tts_enabled"`
 	TTSModel                  *string              `gorm:"column:tts_model;type:varchar(100);default:'';comment:文本转语音使用的AI模型名称" json:"tts_model"`
 	TTSSettings               datatypes.JSON       `gorm:"column:tts_settings;type:json;comment:文本转语音配置项" json:"tts_settings"`
+	AIStreamReplyEnabled      *bool                `gorm:"column:ai_stream_reply_enabled;default:false;comment:是否启用AI流式分段回复" json:"ai_stream_reply_enabled"`
+	AIReplyMode               *AIReplyMode         `gorm:"column:ai_reply_mode;type:enum('at','quote');comment:AI回复方式：at-@提问的人，quote-引用回复" json:"ai_reply_mode"`
 	ShortVideoParsingEnabled  *bool                `gorm:"column:short_video_parsing_enabled;not null;default:true;comment:是否启用短视频解析功能" json:"short_video_parsing_enabled"`
 	WxhbNotifyEnabled         *bool                `gorm:"column:wxhb_notify_enabled;default:false;comment:是否启用微信红包通知功能" json:"wxhb_notify_enabled"`
 	WxhbNotifyMemberList      *string              `gorm:"column:wxhb_notify_member_list;type:text;not null;comment:微信红包通知的成员列表，逗号分隔的微信ID" json:"wxhb_notify_member_list"`
@@ -49,6 +65,13 @@ type ChatRoomSettings struct {
 	MorningEnabled            *bool                `gorm:"column:morning_enabled;default:false;comment:是否启用早安问候功能" json:"morning_enabled"`
 	KnowledgeCategories       datatypes.JSON       `gorm:"column:knowledge_categories;type:json;comment:绑定的知识库分类编码列表" json:"knowledge_categories"`
 	MemoryExtractionBlacklist datatypes.JSON       `gorm:"column:memory_extraction_blacklist;type:json;comment:记忆提取黑名单群成员微信ID列表" json:"memory_extraction_blacklist"`
+	DisabledCommands          datatypes.JSON       `gorm:"column:disabled_commands;type:json;comment:禁用的指令名称列表" json:"disabled_commands"`
+	PluginSwitches            datatypes.JSON       `gorm:"column:plugin_switches;type:json;comment:插件启用开关，key为插件名称或label:标签" json:"plugin_switches"`
+	ToolPolicy                datatypes.JSON       `gorm:"column:tool_policy;type:json;comment:AI工具调用策略，为空时使用全局配置" json:"tool_policy"`
+	AIQuota                   datatypes.JSON       `gorm:"column:ai_quota;type:json;comment:AI用量额度，为空时使用全局配置" json:"ai_quota"`
+	AntiRecallEnabled         *bool                `gorm:"column:anti_recall_enabled;default:false;comment:是否启用防撤回功能" json:"anti_recall_enabled"`
+	AntiRecallMode            *AntiRecallMode      `gorm:"column:anti_recall_mode;type:enum('repost','forward');comment:防撤回方式：repost-群内重发，forward-私聊转发给管理员" json:"anti_recall_mode"`
+	AntiRecallNotifyList      datatypes.JSON       `gorm:"column:anti_recall_notify_list;type:json;comment:防撤回私聊转发的管理员微信ID列表" json:"anti_recall_notify_list"`
 }
 
 // TableName 设置表名
@@ -79,3 +102,27 @@ func (s *ChatRoomSettings) GetMemoryExtractionBlacklist() ([]string, error) {
 	}
 	return wxIDs, nil
 }
+
+// GetDisabledCommands 解析禁用的指令名称列表
+func (s *ChatRoomSettings) GetDisabledCommands() ([]string, error) {
+	if s.DisabledCommands == nil {
+		return nil, nil
+	}
+	var names []string
+	if err := json.Unmarshal(s.DisabledCommands, &names); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// GetAntiRecallNotifyList 解析防撤回私聊转发的管理员微信ID列表
+func (s *ChatRoomSettings) GetAntiRecallNotifyList() ([]string, error) {
+	if s.AntiRecallNotifyList == nil {
+		return nil, nil
+	}
+	var wxIDs []string
+	if err := json.Unmarshal(s.AntiRecallNotifyList, &wxIDs); err != nil {
+		return nil, err
+	}
+	return wxIDs, nil
+}
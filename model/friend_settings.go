@@ -19,6 +19,10 @@ type FriendSettings struct {
 	TTSEnabled            *bool          `gorm:"column:tts_enabled;default:false;comment:是否启用AI文本转语音功能" json:"tts_enabled"`
 	TTSModel              *string        `gorm:"column:tts_model;type:varchar(100);default:'';comment:文本转语音使用的AI模型名称" json:"tts_model"`
 	TTSSettings           datatypes.JSON `gorm:"column:tts_settings;type:json;comment:文本转语音AI配置项" json:"tts_settings"`
+	AIStreamReplyEnabled  *bool          `gorm:"column:ai_stream_reply_enabled;default:false;comment:是否启用AI流式分段回复" json:"ai_stream_reply_enabled"`
+	PluginSwitches        datatypes.JSON `gorm:"column:plugin_switches;type:json;comment:插件启用开关，key为插件名称或label:标签" json:"plugin_switches"`
+	ToolPolicy            datatypes.JSON `gorm:"column:tool_policy;type:json;comment:AI工具调用策略，为空时使用全局配置" json:"tool_policy"`
+	AIQuota               datatypes.JSON `gorm:"column:ai_quota;type:json;comment:AI用量额度，为空时使用全局配置" json:"ai_quota"`
 }
 
 // TableName 设置表名
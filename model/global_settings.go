@@ -50,6 +50,7 @@ type GlobalSettings struct {
 	TTSEnabled                *bool               `gorm:"column:tts_enabled;default:false;comment:是否启用AI文本转语音功能" json:"tts_enabled"`
 	TTSModel                  *string             `gorm:"column:tts_model;type:varchar(100);default:'';comment:文本转语音使用的AI模型名称" json:"tts_model"`
 	TTSSettings               datatypes.JSON      `gorm:"column:tts_settings;type:json;comment:文本转语音AI配置项" json:"tts_settings"`
+	AIStreamReplyEnabled      *bool               `gorm:"column:ai_stream_reply_enabled;default:false;comment:是否启用AI流式分段回复" json:"ai_stream_reply_enabled"`
 	PatEnabled                *bool               `gorm:"column:pat_enabled;default:false;comment:是否启用AI拍一拍功能" json:"pat_enabled"`
 	PatType                   PatType             `gorm:"column:pat_type;type:enum('text','voice');default:'text';comment:拍一拍方式：text-文本，voice-语音" json:"pat_type"`
 	PatText                   string              `gorm:"column:pat_text;type:varchar(255);default:''" json:"pat_text"`
@@ -84,6 +85,14 @@ type GlobalSettings struct {
 	ImageEmbeddingBaseURL     *string             `gorm:"column:image_embedding_base_url;type:varchar(255);default:'';comment:图片嵌入API地址(为空时复用ChatBaseURL)" json:"image_embedding_base_url"`
 	ImageEmbeddingAPIKey      *string             `gorm:"column:image_embedding_api_key;type:varchar(255);default:'';comment:图片嵌入API密钥(为空时复用ChatAPIKey)" json:"image_embedding_api_key"`
 	ImageEmbeddingDimension   *int                `gorm:"column:image_embedding_dimension;default:0;comment:图片嵌入向量维度" json:"image_embedding_dimension"`
+	ASREnabled                *bool               `gorm:"column:asr_enabled;default:false;comment:是否启用语音转文字功能" json:"asr_enabled"`
+	ASRBaseURL                *string             `gorm:"column:asr_base_url;type:varchar(255);default:'';comment:语音转文字API地址(为空时复用ChatBaseURL)" json:"asr_base_url"`
+	ASRAPIKey                 *string             `gorm:"column:asr_api_key;type:varchar(255);default:'';comment:语音转文字API密钥(为空时复用ChatAPIKey)" json:"asr_api_key"`
+	ASRModel                  *string             `gorm:"column:asr_model;type:varchar(100);default:'';comment:语音转文字模型名称(为空时使用whisper-1)" json:"asr_model"`
+	PluginSwitches            datatypes.JSON      `gorm:"column:plugin_switches;type:json;comment:插件启用开关默认值，key为插件名称或label:标签" json:"plugin_switches"`
+	ToolPolicy                datatypes.JSON      `gorm:"column:tool_policy;type:json;comment:默认的AI工具调用策略" json:"tool_policy"`
+	AIQuota                   datatypes.JSON      `gorm:"column:ai_quota;type:json;comment:默认的AI用量额度" json:"ai_quota"`
+	AIRouting                 datatypes.JSON      `gorm:"column:ai_routing;type:json;comment:AI模型路由和备用模型配置" json:"ai_routing"`
 }
 
 // TableName 设置表名
@@ -43,6 +43,8 @@ const (
 	AppMsgTypeCardTicket            AppMessageType = 16     // 名片消息
 	AppMsgTypeRealtimeShareLocation AppMessageType = 17     // 地理位置消息
 	AppMsgTypeChatHistory           AppMessageType = 19     // ChatHistory
+	AppMsgTypeMiniProgram           AppMessageType = 33     // 小程序
+	AppMsgTypeMiniProgramPage       AppMessageType = 36     // 小程序页面
 	AppMsgTypequote                 AppMessageType = 57     // 引用消息
 	AppMsgTypeAttachUploading       AppMessageType = 74     // 附件上传中
 	AppMsgTypeMusic                 AppMessageType = 76     // 音乐消息
@@ -54,8 +56,8 @@ const (
 
 type Message struct {
 	ID                 int64          `gorm:"primarykey" json:"id"`
-	MsgId              int64          `gorm:"column:msg_id;index;" json:"msg_id"`               // 消息Id
-	ClientMsgId        int64          `gorm:"column:client_msg_id;index;" json:"client_msg_id"` // 客户端消息Id
+	MsgId              int64          `gorm:"column:msg_id;uniqueIndex:uk_msg_id;" json:"msg_id"` // 消息Id
+	ClientMsgId        int64          `gorm:"column:client_msg_id;index;" json:"client_msg_id"`   // 客户端消息Id
 	IsChatRoom         bool           `gorm:"column:is_chat_room;default:false;comment:'消息是否来自群聊'" json:"is_chat_room"`
 	IsAtMe             bool           `gorm:"column:is_at_me;default:false;comment:'消息是否艾特我'" json:"is_at_me"`              // @所有人 好的
 	IsAIContext        bool           `gorm:"column:is_ai_context;default:false;comment:'消息是否是AI上下文'" json:"is_ai_context"` // @所有人 好的
@@ -65,11 +67,12 @@ type Message struct {
 	Content            string         `gorm:"column:content" json:"content"`                           // 内容
 	DisplayFullContent string         `gorm:"column:display_full_content" json:"display_full_content"` // 显示的完整内容
 	MessageSource      string         `gorm:"column:message_source" json:"message_source"`
-	FromWxID           string         `gorm:"column:from_wxid" json:"from_wxid"`           // 消息来源
-	SenderWxID         string         `gorm:"column:sender_wxid" json:"sender_wxid"`       // 消息发送者
-	ReplyWxID          string         `gorm:"column:reply_wxid" json:"reply_wxid"`         // AI回复的人
-	ToWxID             string         `gorm:"column:to_wxid" json:"to_wxid"`               // 接收者
-	AttachmentUrl      string         `gorm:"column:attachment_url" json:"attachment_url"` // 文件地址
+	FromWxID           string         `gorm:"column:from_wxid" json:"from_wxid"`             // 消息来源
+	SenderWxID         string         `gorm:"column:sender_wxid" json:"sender_wxid"`         // 消息发送者
+	ReplyWxID          string         `gorm:"column:reply_wxid" json:"reply_wxid"`           // AI回复的人
+	ToWxID             string         `gorm:"column:to_wxid" json:"to_wxid"`                 // 接收者
+	AttachmentUrl      string         `gorm:"column:attachment_url" json:"attachment_url"`   // 文件地址
+	Transcript         string         `gorm:"column:transcript;type:text" json:"transcript"` // 语音转写文本
 	CreatedAt          int64          `gorm:"column:created_at" json:"created_at"`
 	UpdatedAt          int64          `gorm:"column:updated_at" json:"updated_at"`
 	// 额外字段，通过联表查询填充，不参与建表
@@ -81,3 +84,11 @@ type Message struct {
 func (Message) TableName() string {
 	return "messages"
 }
+
+// TextContent 消息的文本内容，语音消息返回转写文本
+func (m *Message) TextContent() string {
+	if m.Type == MsgTypeVoice {
+		return m.Transcript
+	}
+	return m.Content
+}
@@ -0,0 +1,21 @@
+package model
+
+// MessageLocation 位置消息解析结果，latitude/longitude 对应位置消息的 x/y
+type MessageLocation struct {
+	ID         int64   `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	MessageID  int64   `gorm:"uniqueIndex:uniq_message_id;not null;column:message_id;comment:消息表主键ID" json:"message_id"`
+	MsgID      int64   `gorm:"not null;column:msg_id" json:"msg_id"`
+	FromWxID   string  `gorm:"type:varchar(64);index:idx_from_wxid_created_at,priority:1;not null;column:from_wxid" json:"from_wxid"`
+	SenderWxID string  `gorm:"type:varchar(64);not null;column:sender_wxid" json:"sender_wxid"`
+	Latitude   float64 `gorm:"not null;column:latitude;comment:纬度" json:"latitude"`
+	Longitude  float64 `gorm:"not null;column:longitude;comment:经度" json:"longitude"`
+	Scale      int     `gorm:"column:scale;default:0;comment:地图缩放级别" json:"scale"`
+	Label      string  `gorm:"type:varchar(255);column:label;default:'';comment:详细地址" json:"label"`
+	PoiName    string  `gorm:"type:varchar(255);column:poi_name;default:'';comment:地点名称" json:"poi_name"`
+	PoiID      string  `gorm:"type:varchar(128);column:poi_id;default:''" json:"poi_id"`
+	CreatedAt  int64   `gorm:"index:idx_from_wxid_created_at,priority:2;not null;column:created_at" json:"created_at"`
+}
+
+func (MessageLocation) TableName() string {
+	return "message_locations"
+}
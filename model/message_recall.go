@@ -0,0 +1,24 @@
+package model
+
+// MessageRecall 消息撤回记录，保存被撤回消息的快照
+type MessageRecall struct {
+	ID               int64          `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	MessageID        int64          `gorm:"uniqueIndex:uniq_message_id;not null;column:message_id;comment:被撤回消息的消息表主键ID" json:"message_id"`
+	MsgID            int64          `gorm:"not null;column:msg_id" json:"msg_id"`
+	FromWxID         string         `gorm:"type:varchar(64);index:idx_from_wxid_recalled_at,priority:1;not null;column:from_wxid;comment:消息来源，群聊ID或好友微信ID" json:"from_wxid"`
+	SenderWxID       string         `gorm:"type:varchar(64);not null;column:sender_wxid;comment:被撤回消息的发送者" json:"sender_wxid"`
+	RecalledBy       string         `gorm:"type:varchar(64);not null;column:recalled_by;comment:撤回操作人" json:"recalled_by"`
+	Type             MessageType    `gorm:"column:type" json:"type"`
+	AppMsgType       AppMessageType `gorm:"column:app_msg_type" json:"app_msg_type"`
+	Content          string         `gorm:"type:longtext;column:content" json:"content"`
+	Transcript       string         `gorm:"type:text;column:transcript" json:"transcript"`
+	AttachmentUrl    string         `gorm:"type:varchar(512);column:attachment_url;default:''" json:"attachment_url"`
+	ReplaceMsg       string         `gorm:"type:varchar(255);column:replace_msg;default:'';comment:撤回提示文案" json:"replace_msg"`
+	Notified         bool           `gorm:"column:notified;default:false;comment:是否已发送防撤回通知" json:"notified"`
+	MessageCreatedAt int64          `gorm:"not null;column:message_created_at;comment:被撤回消息的发送时间" json:"message_created_at"`
+	RecalledAt       int64          `gorm:"index:idx_from_wxid_recalled_at,priority:2;not null;column:recalled_at" json:"recalled_at"`
+}
+
+func (MessageRecall) TableName() string {
+	return "message_recalls"
+}
@@ -0,0 +1,64 @@
+package model
+
+import "gorm.io/datatypes"
+
+type OutboundMessageType string
+
+const (
+	OutboundMessageTypeText     OutboundMessageType = "text"
+	OutboundMessageTypeLongText OutboundMessageType = "long_text"
+	OutboundMessageTypeImageURL OutboundMessageType = "image_url"
+	OutboundMessageTypeImage    OutboundMessageType = "image_file" // 本地生成的图片，发送成功后删除
+	OutboundMessageTypeFileURL  OutboundMessageType = "file_url"
+	OutboundMessageTypeApp      OutboundMessageType = "app"
+)
+
+type OutboundMessageStatus string
+
+const (
+	OutboundMessageStatusPending OutboundMessageStatus = "pending" // 等待发送，包括等待重试
+	OutboundMessageStatusSending OutboundMessageStatus = "sending"
+	OutboundMessageStatusSent    OutboundMessageStatus = "sent"
+	OutboundMessageStatusDead    OutboundMessageStatus = "dead" // 重试次数用完，进入死信
+)
+
+// OutboundMessage 待发送的消息，同一个接收者的消息按入队顺序发送，失败后按指数退避重试
+type OutboundMessage struct {
+	ID            int64                 `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ToWxID        string                `gorm:"type:varchar(64);index:idx_to_wxid_status,priority:1;not null;column:to_wxid;comment:接收者，群聊ID或好友微信ID" json:"to_wxid"`
+	Type          OutboundMessageType   `gorm:"type:enum('text','long_text','image_url','image_file','file_url','app');not null;column:type" json:"type"`
+	Content       string                `gorm:"type:longtext;column:content;comment:文本内容或应用消息XML" json:"content"`
+	At            datatypes.JSON        `gorm:"type:json;column:at;comment:需要@的微信ID列表" json:"at"`
+	URL           string                `gorm:"type:varchar(1024);column:url;default:'';comment:图片或文件地址，本地图片为缓存文件路径" json:"url"`
+	AppMsgType    int                   `gorm:"column:app_msg_type;default:0" json:"app_msg_type"`
+	Source        string                `gorm:"type:varchar(64);column:source;default:'';comment:消息来源，如定时任务名称" json:"source"`
+	Status        OutboundMessageStatus `gorm:"type:enum('pending','sending','sent','dead');index:idx_to_wxid_status,priority:2;index:idx_status_next_attempt_at,priority:1;not null;default:'pending';column:status" json:"status"`
+	Attempts      int                   `gorm:"not null;default:0;column:attempts;comment:已尝试次数" json:"attempts"`
+	MaxAttempts   int                   `gorm:"not null;default:5;column:max_attempts;comment:最大尝试次数" json:"max_attempts"`
+	NextAttemptAt int64                 `gorm:"index:idx_status_next_attempt_at,priority:2;not null;default:0;column:next_attempt_at;comment:下次尝试时间" json:"next_attempt_at"`
+	LastError     string                `gorm:"type:text;column:last_error" json:"last_error"`
+	MessageID     int64                 `gorm:"not null;default:0;column:message_id;comment:发送成功后对应的消息表主键ID" json:"message_id"`
+	SentAt        int64                 `gorm:"not null;default:0;column:sent_at" json:"sent_at"`
+	CreatedAt     int64                 `gorm:"autoCreateTime;not null;column:created_at" json:"created_at"`
+	UpdatedAt     int64                 `gorm:"autoUpdateTime;not null;column:updated_at" json:"updated_at"`
+}
+
+func (OutboundMessage) TableName() string {
+	return "outbound_messages"
+}
+
+// OutboundMessageAttempt 每一次发送尝试的记录
+type OutboundMessageAttempt struct {
+	ID                int64  `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	OutboundMessageID int64  `gorm:"index:idx_outbound_message_id;not null;column:outbound_message_id" json:"outbound_message_id"`
+	Attempt           int    `gorm:"not null;column:attempt;comment:第几次尝试" json:"attempt"`
+	Success           bool   `gorm:"not null;default:false;column:success" json:"success"`
+	Error             string `gorm:"type:text;column:error" json:"error"`
+	MessageID         int64  `gorm:"not null;default:0;column:message_id;comment:发送成功后对应的消息表主键ID" json:"message_id"`
+	Duration          int64  `gorm:"not null;default:0;column:duration;comment:耗时(毫秒)" json:"duration"`
+	CreatedAt         int64  `gorm:"autoCreateTime;not null;column:created_at" json:"created_at"`
+}
+
+func (OutboundMessageAttempt) TableName() string {
+	return "outbound_message_attempts"
+}
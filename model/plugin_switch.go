@@ -0,0 +1,42 @@
+package model
+
+import (
+	"encoding/json"
+
+	"gorm.io/datatypes"
+)
+
+// PluginLabelKeyPrefix 按标签配置插件开关时 key 的前缀，例如 label:douyin
+const PluginLabelKeyPrefix = "label:"
+
+// PluginSwitches 插件启用开关，key 为插件名称或 "label:标签名"，value 为是否启用
+type PluginSwitches map[string]bool
+
+// ParsePluginSwitches 解析插件启用开关
+func ParsePluginSwitches(data datatypes.JSON) (PluginSwitches, error) {
+	switches := PluginSwitches{}
+	if len(data) == 0 {
+		return switches, nil
+	}
+	if err := json.Unmarshal(data, &switches); err != nil {
+		return nil, err
+	}
+	return switches, nil
+}
+
+// Lookup 查询插件开关，插件名称的配置优先于标签，任一标签被禁用则视为禁用；未配置时 ok 返回 false
+func (s PluginSwitches) Lookup(name string, labels []string) (enabled bool, ok bool) {
+	if v, exists := s[name]; exists {
+		return v, true
+	}
+	enabled = true
+	for _, label := range labels {
+		if v, exists := s[PluginLabelKeyPrefix+label]; exists {
+			ok = true
+			if !v {
+				return false, true
+			}
+		}
+	}
+	return enabled, ok
+}
@@ -0,0 +1,25 @@
+package model
+
+// RobotSessionState 机器人会话状态
+type RobotSessionState string
+
+const (
+	RobotSessionStateOnline       RobotSessionState = "online"        // 在线
+	RobotSessionStateDegraded     RobotSessionState = "degraded"      // 心跳失败，但还没有确认掉线
+	RobotSessionStateReconnecting RobotSessionState = "reconnecting"  // 正在尝试二次登录
+	RobotSessionStateAwaitingScan RobotSessionState = "awaiting_scan" // 已向手机推送登录确认，等待确认
+	RobotSessionStateOffline      RobotSessionState = "offline"       // 离线，需要重新扫码登录
+)
+
+// RobotSessionEvent 机器人会话状态变更记录
+type RobotSessionEvent struct {
+	ID        int64             `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	FromState RobotSessionState `gorm:"type:varchar(20);not null;default:'';column:from_state;comment:变更前的状态" json:"from_state"`
+	ToState   RobotSessionState `gorm:"type:varchar(20);not null;index;column:to_state;comment:变更后的状态" json:"to_state"`
+	Reason    string            `gorm:"type:varchar(512);not null;default:'';column:reason;comment:变更原因" json:"reason"`
+	CreatedAt int64             `gorm:"autoCreateTime;not null;index;column:created_at" json:"created_at"`
+}
+
+func (RobotSessionEvent) TableName() string {
+	return "robot_session_events"
+}
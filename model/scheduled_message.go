@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/json"
+
+	"gorm.io/datatypes"
+)
+
+type ScheduledMessageType string
+
+const (
+	ScheduledMessageTypeText  ScheduledMessageType = "text"
+	ScheduledMessageTypeImage ScheduledMessageType = "image"
+	ScheduledMessageTypeFile  ScheduledMessageType = "file"
+	ScheduledMessageTypeApp   ScheduledMessageType = "app"
+)
+
+type ScheduleType string
+
+const (
+	ScheduleTypeOnce ScheduleType = "once" // 在指定时间发送一次
+	ScheduleTypeCron ScheduleType = "cron" // 按 cron 表达式周期发送
+)
+
+// ScheduledMessage 定时消息，到点后交给出站消息队列发送
+type ScheduledMessage struct {
+	ID           int64                `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	Name         string               `gorm:"type:varchar(64);not null;default:'';column:name;comment:任务名称" json:"name"`
+	ToWxIDs      datatypes.JSON       `gorm:"type:json;column:to_wxids;comment:接收者微信ID列表" json:"to_wxids"`
+	Type         ScheduledMessageType `gorm:"type:enum('text','image','file','app');not null;column:type" json:"type"`
+	Content      string               `gorm:"type:longtext;column:content;comment:文本内容或应用消息XML" json:"content"`
+	URL          string               `gorm:"type:varchar(1024);column:url;default:'';comment:图片或文件地址" json:"url"`
+	AppMsgType   int                  `gorm:"column:app_msg_type;default:0" json:"app_msg_type"`
+	ScheduleType ScheduleType         `gorm:"type:enum('once','cron');not null;column:schedule_type" json:"schedule_type"`
+	SendAt       int64                `gorm:"not null;default:0;column:send_at;comment:单次发送的时间，精确到分钟" json:"send_at"`
+	CronExpr     string               `gorm:"type:varchar(64);not null;default:'';column:cron_expr;comment:周期发送的cron表达式" json:"cron_expr"`
+	Enabled      *bool                `gorm:"not null;default:true;column:enabled" json:"enabled"`
+	RunCount     int                  `gorm:"not null;default:0;column:run_count;comment:已执行次数" json:"run_count"`
+	LastRunAt    int64                `gorm:"not null;default:0;column:last_run_at" json:"last_run_at"`
+	LastError    string               `gorm:"type:text;column:last_error" json:"last_error"`
+	CreatedAt    int64                `gorm:"autoCreateTime;not null;column:created_at" json:"created_at"`
+	UpdatedAt    int64                `gorm:"autoUpdateTime;not null;column:updated_at" json:"updated_at"`
+}
+
+func (ScheduledMessage) TableName() string {
+	return "scheduled_messages"
+}
+
+func (m *ScheduledMessage) GetToWxIDs() ([]string, error) {
+	if m.ToWxIDs == nil {
+		return nil, nil
+	}
+	var wxIDs []string
+	if err := json.Unmarshal(m.ToWxIDs, &wxIDs); err != nil {
+		return nil, err
+	}
+	return wxIDs, nil
+}
+
+func (m *ScheduledMessage) IsEnabled() bool {
+	return m.Enabled != nil && *m.Enabled
+}
@@ -27,10 +27,25 @@ type SystemSettings struct {
 	AutoVerifyUser             *bool             `gorm:"column:auto_verify_user;default:false;comment:自动通过好友验证" json:"auto_verify_user"`
 	VerifyUserDelay            *int              `gorm:"column:verify_user_delay;default:60;comment:自动通过好友验证延迟时间(秒)" json:"verify_user_delay"`
 	AutoChatroomInvite         *bool             `gorm:"column:auto_chatroom_invite;default:false;comment:自动邀请进群" json:"auto_chatroom_invite"`
+	PacingEnabled              *bool             `gorm:"column:pacing_enabled;default:false;comment:启用防风控发送节奏(每日配额、随机抖动、打字延迟、风控自适应)" json:"pacing_enabled"`
+	PacingBudgets              datatypes.JSON    `gorm:"column:pacing_budgets;type:json;comment:各类操作的限速预算，键为 global/query/text/media/friend_request/friend/create_chat_room/group_invite/moments" json:"pacing_budgets"`
+	PacingJitterMin            *int              `gorm:"column:pacing_jitter_min;default:300;comment:随机抖动下限(毫秒)" json:"pacing_jitter_min"`
+	PacingJitterMax            *int              `gorm:"column:pacing_jitter_max;default:1500;comment:随机抖动上限(毫秒)" json:"pacing_jitter_max"`
+	PacingTypingDelay          *int              `gorm:"column:pacing_typing_delay;default:50;comment:文本消息每个字的打字延迟(毫秒)" json:"pacing_typing_delay"`
+	PacingMaxTypingDelay       *int              `gorm:"column:pacing_max_typing_delay;default:5000;comment:打字延迟上限(毫秒)" json:"pacing_max_typing_delay"`
+	PacingRiskBackoffFactor    *float64          `gorm:"column:pacing_risk_backoff_factor;default:2;comment:触发风控后限速间隔放大的倍数" json:"pacing_risk_backoff_factor"`
+	PacingRiskCooldown         *int              `gorm:"column:pacing_risk_cooldown;default:1800;comment:触发风控后恢复正常限速的时间(秒)" json:"pacing_risk_cooldown"`
 	CreatedAt                  int64             `gorm:"column:created_at;autoCreateTime;not null;comment:创建时间" json:"created_at"`
 	UpdatedAt                  int64             `gorm:"column:updated_at;autoUpdateTime;not null;comment:更新时间" json:"updated_at"`
 }
 
+// PacingBudget 单个操作类别的限速预算，未配置的字段使用默认值
+type PacingBudget struct {
+	Interval   *int `json:"interval"`    // 两次操作之间的最小间隔(毫秒)，0 表示不限制
+	Burst      *int `json:"burst"`       // 允许的突发次数
+	DailyQuota *int `json:"daily_quota"` // 每日配额，0 表示不限制
+}
+
 func (SystemSettings) TableName() string {
 	return "system_settings"
 }
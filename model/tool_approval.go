@@ -0,0 +1,33 @@
+package model
+
+import "gorm.io/datatypes"
+
+type ToolApprovalStatus string
+
+const (
+	ToolApprovalStatusPending  ToolApprovalStatus = "pending"  // 等待审批
+	ToolApprovalStatusApproved ToolApprovalStatus = "approved" // 已同意
+	ToolApprovalStatusRejected ToolApprovalStatus = "rejected" // 已拒绝
+	ToolApprovalStatusExpired  ToolApprovalStatus = "expired"  // 超时未审批
+)
+
+// ToolApproval AI 调用需要人工审批的工具时的审批记录
+type ToolApproval struct {
+	ID         int64              `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	MessageID  int64              `gorm:"not null;default:0;index;column:message_id;comment:触发工具调用的消息ID" json:"message_id"`
+	ChatID     string             `gorm:"type:varchar(64);not null;default:'';index;column:chat_id;comment:发起工具调用的会话微信ID" json:"chat_id"`
+	SenderWxID string             `gorm:"type:varchar(64);not null;default:'';column:sender_wxid;comment:发起工具调用的用户微信ID" json:"sender_wxid"`
+	ToolName   string             `gorm:"type:varchar(255);not null;default:'';column:tool_name;comment:工具名称" json:"tool_name"`
+	Arguments  string             `gorm:"type:text;column:arguments;comment:工具调用参数" json:"arguments"`
+	Approvers  datatypes.JSON     `gorm:"type:json;column:approvers;comment:审批人微信ID列表，为空时由群管理员或机器人主人审批" json:"approvers"`
+	Status     ToolApprovalStatus `gorm:"type:varchar(20);not null;default:'pending';index;column:status;comment:审批状态" json:"status"`
+	DecidedBy  string             `gorm:"type:varchar(64);not null;default:'';column:decided_by;comment:审批人微信ID" json:"decided_by"`
+	ExpiresAt  int64              `gorm:"not null;default:0;column:expires_at;comment:审批截止时间" json:"expires_at"`
+	DecidedAt  int64              `gorm:"not null;default:0;column:decided_at;comment:审批时间" json:"decided_at"`
+	CreatedAt  int64              `gorm:"autoCreateTime;not null;index;column:created_at" json:"created_at"`
+	UpdatedAt  int64              `gorm:"autoUpdateTime;not null;column:updated_at" json:"updated_at"`
+}
+
+func (ToolApproval) TableName() string {
+	return "tool_approvals"
+}
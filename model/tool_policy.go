@@ -0,0 +1,110 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+
+	"gorm.io/datatypes"
+)
+
+// 工具组，可以单独使用表示整类工具，也可以加上冒号和名称表示其中一组，例如 mcp:amap、skill:pdf
+const (
+	ToolGroupInternal = "internal" // 内置工具，internal:工具名称
+	ToolGroupMCP      = "mcp"      // MCP 工具，mcp:服务名称
+	ToolGroupSkill    = "skill"    // Skill 工具，skill:Skill名称
+)
+
+type ToolPolicyRole string
+
+const (
+	ToolPolicyRoleOwner  ToolPolicyRole = "owner"  // 群主
+	ToolPolicyRoleAdmin  ToolPolicyRole = "admin"  // 群管理员
+	ToolPolicyRoleMember ToolPolicyRole = "member" // 普通群成员
+	ToolPolicyRoleFriend ToolPolicyRole = "friend" // 私聊好友
+)
+
+const (
+	// DefaultToolApprovalTimeout 默认的工具审批超时时间，单位秒
+	DefaultToolApprovalTimeout = 300
+	// MaxToolApprovalTimeout 审批超时时间的上限，等待审批期间会占用一个消息处理协程
+	MaxToolApprovalTimeout = 600
+)
+
+// ToolPolicy AI 工具调用策略，Allow、Deny 和 RequireApproval 的元素可以是工具名称或者工具组
+type ToolPolicy struct {
+	Allow           []string                       `json:"allow,omitempty"`            // 允许的工具，为空表示不限制
+	Deny            []string                       `json:"deny,omitempty"`             // 禁止的工具，优先于 Allow
+	RequireApproval []string                       `json:"require_approval,omitempty"` // 调用前需要人工审批的工具
+	Approvers       []string                       `json:"approvers,omitempty"`        // 审批人微信ID，为空时由群管理员或机器人主人审批，只在外层策略生效
+	ApprovalTimeout int                            `json:"approval_timeout,omitempty"` // 审批超时时间，单位秒，只在外层策略生效
+	Roles           map[ToolPolicyRole]*ToolPolicy `json:"roles,omitempty"`            // 按角色单独配置的策略，和外层策略同时生效
+}
+
+// ParseToolPolicy 解析工具调用策略，未配置时返回 nil
+func ParseToolPolicy(data datatypes.JSON) (*ToolPolicy, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var policy ToolPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ToolGroupKey 生成工具组的 key，例如 mcp:amap
+func ToolGroupKey(group, name string) string {
+	return group + ":" + name
+}
+
+// Allows 判断 role 角色是否可以使用 keys 标识的工具，keys 为工具名称和所属的工具组
+func (p *ToolPolicy) Allows(role ToolPolicyRole, keys ...string) bool {
+	if p == nil {
+		return true
+	}
+	if !p.allows(keys) {
+		return false
+	}
+	if rolePolicy, ok := p.Roles[role]; ok && rolePolicy != nil {
+		return rolePolicy.allows(keys)
+	}
+	return true
+}
+
+// RequiresApproval 判断 role 角色调用 keys 标识的工具前是否需要人工审批
+func (p *ToolPolicy) RequiresApproval(role ToolPolicyRole, keys ...string) bool {
+	if p == nil {
+		return false
+	}
+	if containsAny(p.RequireApproval, keys) {
+		return true
+	}
+	if rolePolicy, ok := p.Roles[role]; ok && rolePolicy != nil {
+		return containsAny(rolePolicy.RequireApproval, keys)
+	}
+	return false
+}
+
+// GetApprovalTimeout 审批超时时间，未配置时使用默认值，超过上限时按上限处理
+func (p *ToolPolicy) GetApprovalTimeout() int {
+	if p == nil || p.ApprovalTimeout <= 0 {
+		return DefaultToolApprovalTimeout
+	}
+	return min(p.ApprovalTimeout, MaxToolApprovalTimeout)
+}
+
+func containsAny(list []string, keys []string) bool {
+	for _, key := range keys {
+		if slices.Contains(list, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ToolPolicy) allows(keys []string) bool {
+	if containsAny(p.Deny, keys) {
+		return false
+	}
+	return len(p.Allow) == 0 || containsAny(p.Allow, keys)
+}
@@ -0,0 +1,38 @@
+package airouter
+
+import (
+	"unicode/utf8"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// NewRequest 根据输入消息生成路由需要的调用特征
+func NewRequest(messages []openai.ChatCompletionMessageParamUnion, useTools bool) Request {
+	req := Request{UseTools: useTools}
+	for _, message := range messages {
+		switch content := message.GetContent().AsAny().(type) {
+		case *string:
+			req.ContextChars += utf8.RuneCountInString(*content)
+		case *[]openai.ChatCompletionContentPartTextParam:
+			for _, part := range *content {
+				req.ContextChars += utf8.RuneCountInString(part.Text)
+			}
+		case *[]openai.ChatCompletionContentPartUnionParam:
+			for _, part := range *content {
+				if text := part.GetText(); text != nil {
+					req.ContextChars += utf8.RuneCountInString(*text)
+				}
+				if part.OfImageURL != nil {
+					req.HasImage = true
+				}
+			}
+		case *[]openai.ChatCompletionAssistantMessageParamContentArrayOfContentPartUnion:
+			for _, part := range *content {
+				if text := part.GetText(); text != nil {
+					req.ContextChars += utf8.RuneCountInString(*text)
+				}
+			}
+		}
+	}
+	return req
+}
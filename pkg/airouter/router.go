@@ -0,0 +1,210 @@
+// Package airouter 在多个 OpenAI 兼容的服务商之间路由模型调用，
+// 按规则选择首选模型，可重试的错误退避重试，仍然失败时按顺序切换到备用模型。
+package airouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+)
+
+const (
+	DefaultMaxRetries = 2
+	DefaultBackoff    = time.Second
+	maxBackoff        = 30 * time.Second
+)
+
+// Endpoint 一个可以调用的模型：服务商地址、密钥和模型名称
+type Endpoint struct {
+	Provider string // 服务商名称，为空表示功能自己配置的服务商
+	BaseURL  string
+	APIKey   string
+	Model    string
+}
+
+func (e Endpoint) String() string {
+	provider := e.Provider
+	if provider == "" {
+		provider = "default"
+	}
+	return provider + "/" + e.Model
+}
+
+// Client 重试由路由控制，关闭 SDK 自带的重试
+func (e Endpoint) Client() openai.Client {
+	return openai.NewClient(
+		option.WithAPIKey(e.APIKey),
+		option.WithBaseURL(e.BaseURL),
+		option.WithMaxRetries(0),
+	)
+}
+
+// Request 本次调用的特征，用于选择首选模型
+type Request struct {
+	HasImage     bool // 输入包含图片
+	UseTools     bool // 需要调用工具
+	ContextChars int  // 输入的文本长度
+}
+
+// Router 一个功能的模型路由，Vision、Tools、LongContext 为空表示不启用对应的规则
+type Router struct {
+	Primary          Endpoint
+	Fallbacks        []Endpoint
+	Vision           *Endpoint // 输入包含图片时优先使用
+	Tools            *Endpoint // 需要调用工具时优先使用
+	LongContext      *Endpoint // 输入超过 LongContextChars 时优先使用
+	LongContextChars int
+	MaxRetries       int           // 每个模型遇到可重试错误时的重试次数
+	Backoff          time.Duration // 第一次重试的等待时间，之后每次翻倍
+}
+
+// Single 只有一个模型的路由，不切换模型，仍然会重试
+func Single(endpoint Endpoint) *Router {
+	return &Router{Primary: endpoint, MaxRetries: DefaultMaxRetries, Backoff: DefaultBackoff}
+}
+
+// Chain 按顺序返回本次调用要尝试的模型，规则选中的模型排在最前面，重复的模型只保留一个
+func (r *Router) Chain(req Request) []Endpoint {
+	var chain []Endpoint
+	seen := make(map[string]bool)
+	add := func(endpoint *Endpoint) {
+		if endpoint == nil || endpoint.Model == "" {
+			return
+		}
+		key := endpoint.BaseURL + "\x00" + endpoint.APIKey + "\x00" + endpoint.Model
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		chain = append(chain, *endpoint)
+	}
+	switch {
+	case req.HasImage && r.Vision != nil:
+		add(r.Vision)
+	case r.LongContextChars > 0 && req.ContextChars > r.LongContextChars && r.LongContext != nil:
+		add(r.LongContext)
+	case req.UseTools && r.Tools != nil:
+		add(r.Tools)
+	}
+	add(&r.Primary)
+	for i := range r.Fallbacks {
+		add(&r.Fallbacks[i])
+	}
+	return chain
+}
+
+// Do 依次调用 Chain 中的模型直到成功。call 返回 NoFallback 包装的错误时立即结束，
+// 例如流式回复已经发出了一部分内容，再换模型会重复发送
+func (r *Router) Do(ctx context.Context, req Request, call func(client *openai.Client, endpoint Endpoint) error) error {
+	chain := r.Chain(req)
+	if len(chain) == 0 {
+		return errors.New("没有配置可用的 AI 模型")
+	}
+	var errs []error
+	for _, endpoint := range chain {
+		client := endpoint.Client()
+		for attempt := 0; ; attempt++ {
+			err := call(&client, endpoint)
+			if err == nil {
+				return nil
+			}
+			var stop *noFallbackError
+			if errors.As(err, &stop) {
+				return stop.err
+			}
+			if ctx.Err() != nil {
+				return err
+			}
+			log.Printf("[AIRouter] 调用 %s 失败（第 %d 次）: %v", endpoint, attempt+1, err)
+			errs = append(errs, fmt.Errorf("%s: %w", endpoint, err))
+			if !IsRetryable(err) || attempt >= r.MaxRetries {
+				break
+			}
+			if !sleep(ctx, r.backoff(attempt, err)) {
+				return err
+			}
+		}
+	}
+	return &Error{Errs: errs}
+}
+
+func (r *Router) backoff(attempt int, err error) time.Duration {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) && apiErr.Response != nil {
+		if seconds, err := strconv.Atoi(apiErr.Response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			return min(time.Duration(seconds)*time.Second, maxBackoff)
+		}
+	}
+	backoff := r.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	return min(backoff<<attempt, maxBackoff)
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// IsRetryable 限流、超时、服务端错误和网络错误可以重试
+func IsRetryable(err error) bool {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		code := apiErr.StatusCode
+		return code == http.StatusRequestTimeout || code == http.StatusConflict || code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+type noFallbackError struct {
+	err error
+}
+
+func (e *noFallbackError) Error() string {
+	return e.err.Error()
+}
+
+func (e *noFallbackError) Unwrap() error {
+	return e.err
+}
+
+// NoFallback 包装的错误不再重试也不再切换模型
+func NoFallback(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &noFallbackError{err: err}
+}
+
+// Error 所有模型都调用失败，Error() 只返回给用户看的提示，原始错误已经记录在日志中，也可以通过 errors.As 获取
+type Error struct {
+	Errs []error
+}
+
+func (e *Error) Error() string {
+	return "AI 服务暂时不可用，请稍后再试。"
+}
+
+func (e *Error) Unwrap() []error {
+	return e.Errs
+}
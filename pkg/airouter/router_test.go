@@ -0,0 +1,126 @@
+package airouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+)
+
+func apiError(statusCode int) error {
+	return &openai.Error{
+		StatusCode: statusCode,
+		Request:    &http.Request{Method: http.MethodPost, URL: &url.URL{Path: "/chat/completions"}},
+		Response:   &http.Response{StatusCode: statusCode, Header: http.Header{}},
+	}
+}
+
+func TestRouterChain(t *testing.T) {
+	router := &Router{
+		Primary:          Endpoint{BaseURL: "a", Model: "chat"},
+		Fallbacks:        []Endpoint{{BaseURL: "b", Model: "backup"}, {BaseURL: "a", Model: "chat"}},
+		Vision:           &Endpoint{BaseURL: "a", Model: "vision"},
+		Tools:            &Endpoint{BaseURL: "a", Model: "tools"},
+		LongContext:      &Endpoint{BaseURL: "a", Model: "long"},
+		LongContextChars: 100,
+	}
+	cases := []struct {
+		req    Request
+		models []string
+	}{
+		{Request{}, []string{"chat", "backup"}},
+		{Request{HasImage: true, UseTools: true}, []string{"vision", "chat", "backup"}},
+		{Request{ContextChars: 101, UseTools: true}, []string{"long", "chat", "backup"}},
+		{Request{ContextChars: 100, UseTools: true}, []string{"tools", "chat", "backup"}},
+	}
+	for _, c := range cases {
+		chain := router.Chain(c.req)
+		var models []string
+		for _, endpoint := range chain {
+			models = append(models, endpoint.Model)
+		}
+		if len(models) != len(c.models) {
+			t.Fatalf("%+v: expected %v, got %v", c.req, c.models, models)
+		}
+		for i := range models {
+			if models[i] != c.models[i] {
+				t.Errorf("%+v: expected %v, got %v", c.req, c.models, models)
+				break
+			}
+		}
+	}
+}
+
+func TestRouterDo(t *testing.T) {
+	router := &Router{
+		Primary:    Endpoint{Model: "chat"},
+		Fallbacks:  []Endpoint{{Model: "backup"}},
+		MaxRetries: 1,
+		Backoff:    1,
+	}
+	var calls []string
+	err := router.Do(context.Background(), Request{}, func(client *openai.Client, endpoint Endpoint) error {
+		calls = append(calls, endpoint.Model)
+		if endpoint.Model == "chat" {
+			return apiError(http.StatusTooManyRequests)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected fallback to succeed, got %v", err)
+	}
+	if len(calls) != 3 || calls[2] != "backup" {
+		t.Errorf("expected chat to be retried once before backup, got %v", calls)
+	}
+
+	calls = nil
+	err = router.Do(context.Background(), Request{}, func(client *openai.Client, endpoint Endpoint) error {
+		calls = append(calls, endpoint.Model)
+		return apiError(http.StatusBadRequest)
+	})
+	var routerErr *Error
+	if !errors.As(err, &routerErr) || len(routerErr.Errs) != 2 {
+		t.Fatalf("expected router error with both failures, got %v", err)
+	}
+	if len(calls) != 2 {
+		t.Errorf("non retryable errors should not be retried, got %v", calls)
+	}
+
+	calls = nil
+	streamErr := errors.New("stream broken")
+	err = router.Do(context.Background(), Request{}, func(client *openai.Client, endpoint Endpoint) error {
+		calls = append(calls, endpoint.Model)
+		return NoFallback(streamErr)
+	})
+	if err != streamErr || len(calls) != 1 {
+		t.Errorf("NoFallback should stop immediately, got %v after %v", err, calls)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	if !IsRetryable(apiError(http.StatusTooManyRequests)) || !IsRetryable(apiError(http.StatusBadGateway)) {
+		t.Errorf("rate limit and server errors should be retryable")
+	}
+	if IsRetryable(apiError(http.StatusUnauthorized)) {
+		t.Errorf("auth errors should not be retryable")
+	}
+	if !IsRetryable(context.DeadlineExceeded) || IsRetryable(errors.New("bad")) {
+		t.Errorf("unexpected retryable result")
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	req := NewRequest([]openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("你好"),
+		openai.UserMessage([]openai.ChatCompletionContentPartUnionParam{
+			openai.TextContentPart("看图"),
+			openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: "https://example.com/a.png"}),
+		}),
+	}, true)
+	if !req.HasImage || !req.UseTools || req.ContextChars != 4 {
+		t.Errorf("unexpected request: %+v", req)
+	}
+}
@@ -163,6 +163,15 @@ func (m *MCPManager) ExecuteToolCall(ctx context.Context, robotCtx robotctx.Robo
 	return m.formatToolResult(result)
 }
 
+// ToolServerName 返回工具所属的 MCP 服务器名称，不是 MCP 工具时返回空字符串
+func (m *MCPManager) ToolServerName(fullName string) string {
+	serverName, _, err := m.parseToolName(fullName)
+	if err != nil {
+		return ""
+	}
+	return serverName
+}
+
 // parseToolName 解析工具名称
 func (m *MCPManager) parseToolName(fullName string) (serverName, toolName string, err error) {
 	// 工具名称格式：serverName__toolName
@@ -0,0 +1,112 @@
+package openaitools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"gorm.io/gorm"
+
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/robotctx"
+	"wechat-robot-client/repository"
+)
+
+const lastSharedLocationLimit = 3
+
+type LastSharedLocationTool struct {
+	db *gorm.DB
+}
+
+type lastSharedLocationToolArgs struct {
+	MemberName string `json:"member_name"`
+}
+
+func NewLastSharedLocationTool(db *gorm.DB) OpenAITool {
+	return &LastSharedLocationTool{db: db}
+}
+
+func (t *LastSharedLocationTool) GetOpenAITool(robotCtx *robotctx.RobotContext) *openai.ChatCompletionToolUnionParam {
+	if t.db == nil {
+		return nil
+	}
+	tool := openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        "get_last_shared_location",
+		Description: openai.String("查询当前会话中最近分享过的位置（地点名称、详细地址、经纬度、分享人和分享时间）。适用于用户询问「刚才发的位置在哪」「上次约的地点是哪里」「某人分享的位置」等问题。"),
+		Parameters: openai.FunctionParameters{
+			"type": "object",
+			"properties": map[string]any{
+				"member_name": map[string]string{
+					"type":        "string",
+					"description": "只查询某个群成员分享的位置时传入其昵称、备注、微信号或微信ID，不限定分享人时不传。",
+				},
+			},
+		},
+	})
+	return &tool
+}
+
+func (t *LastSharedLocationTool) BuildSystemPrompt(ctx context.Context, robotCtx *robotctx.RobotContext) (string, error) {
+	if t.db == nil {
+		return "", nil
+	}
+	return "最近分享位置查询工具", nil
+}
+
+func (t *LastSharedLocationTool) ExecuteToolCall(ctx context.Context, robotCtx *robotctx.RobotContext, toolCall openai.ChatCompletionMessageToolCallUnion) (string, bool, error) {
+	var args lastSharedLocationToolArgs
+	if toolCall.Function.Arguments != "" {
+		if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+			return "", false, fmt.Errorf("解析参数失败: %w", err)
+		}
+	}
+	if t.db == nil || robotCtx == nil || robotCtx.FromWxID == "" {
+		return "位置查询工具不可用", false, nil
+	}
+
+	isChatRoom := strings.HasSuffix(robotCtx.FromWxID, "@chatroom")
+	var members []*model.ChatRoomMember
+	var senderWxIDs []string
+	if isChatRoom {
+		var err error
+		members, err = repository.NewChatRoomMemberRepo(ctx, t.db).GetChatRoomMembers(robotCtx.FromWxID)
+		if err != nil {
+			return "", false, fmt.Errorf("查询群成员失败: %w", err)
+		}
+		if memberName := strings.TrimSpace(args.MemberName); memberName != "" {
+			resolved := resolveChatRoomMember(members, memberName)
+			if len(resolved.Members) == 0 {
+				return fmt.Sprintf("未找到群成员 %q", memberName), false, nil
+			}
+			for _, member := range resolved.Members {
+				senderWxIDs = append(senderWxIDs, member.WechatID)
+			}
+		}
+	}
+
+	locations, err := repository.NewMessageLocationRepo(ctx, t.db).GetLatest(robotCtx.FromWxID, lastSharedLocationLimit, senderWxIDs...)
+	if err != nil {
+		return "", false, fmt.Errorf("查询位置失败: %w", err)
+	}
+	if len(locations) == 0 {
+		return "当前会话中还没有人分享过位置", false, nil
+	}
+
+	memberByWxID := buildChatRoomMemberByWxID(members)
+	var sb strings.Builder
+	sb.WriteString("当前会话最近分享的位置（按时间倒序）：\n")
+	for _, location := range locations {
+		sender := location.SenderWxID
+		if member, ok := memberByWxID[location.SenderWxID]; ok {
+			sender = firstNonEmpty(member.Remark, member.Nickname, member.Alias, member.WechatID)
+		}
+		fmt.Fprintf(&sb, "- 分享人：%s，时间：%s\n", sender, time.Unix(location.CreatedAt, 0).Format("2006-01-02 15:04"))
+		writeNonEmptyLine(&sb, "  地点名称", location.PoiName)
+		writeNonEmptyLine(&sb, "  详细地址", location.Label)
+		fmt.Fprintf(&sb, "  经纬度：%.6f,%.6f\n", location.Latitude, location.Longitude)
+	}
+	return strings.TrimSpace(sb.String()), false, nil
+}
@@ -37,6 +37,8 @@ func (m *OpenAIToolsManager) Initialize() error {
 	m.tools["search_document"] = NewSearchKnowledgeTool(m.KnowledgeService)
 	m.tools["search_chat_room_memory"] = NewSearchChatRoomMemoryTool(m.db)
 	m.tools["search_memory"] = NewSearchMemoryTool()
+	m.tools["get_last_shared_location"] = NewLastSharedLocationTool(m.db)
+	m.tools[ReplyToolName] = NewReplyTool()
 	return nil
 }
 
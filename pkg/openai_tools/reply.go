@@ -0,0 +1,144 @@
+package openaitools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/pkg/robotctx"
+)
+
+// ReplyToolName 结构化回复工具的名称
+const ReplyToolName = "reply"
+
+// ReplyTool 结构化回复工具，AI 需要发送图片、视频、语音、文件等多种消息时调用
+// 工具调用会立即结束对话，回复通过 BuildReply 直接交给 AI 聊天插件按顺序发送，不经过模型输出的文本
+type ReplyTool struct{}
+
+type replyToolPart struct {
+	Type     dto.ReplyPartType `json:"type"`
+	Text     string            `json:"text"`
+	URL      string            `json:"url"`
+	AppType  int               `json:"app_type"`
+	AppXML   string            `json:"app_xml"`
+	AtSender bool              `json:"at_sender"`
+	Quote    bool              `json:"quote"`
+}
+
+type replyToolArgs struct {
+	Parts []replyToolPart `json:"parts"`
+}
+
+func NewReplyTool() OpenAITool {
+	return &ReplyTool{}
+}
+
+func (t *ReplyTool) GetOpenAITool(robotCtx *robotctx.RobotContext) *openai.ChatCompletionToolUnionParam {
+	if robotCtx == nil || robotCtx.FromWxID == "" {
+		return nil
+	}
+	tool := openai.ChatCompletionFunctionTool(openai.FunctionDefinitionParam{
+		Name:        ReplyToolName,
+		Description: openai.String("以多条消息的形式回复用户，可以混合发送文本、图片、视频、语音、文件和应用消息，各部分按顺序发送。调用后本轮对话立即结束，不要再输出其他内容。"),
+		Parameters: openai.FunctionParameters{
+			"type": "object",
+			"properties": map[string]any{
+				"parts": map[string]any{
+					"type":        "array",
+					"description": "按发送顺序排列的消息列表",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"type": map[string]any{
+								"type":        "string",
+								"enum":        []string{"text", "image", "video", "voice", "file", "app"},
+								"description": "消息类型",
+							},
+							"text": map[string]string{
+								"type":        "string",
+								"description": "text 类型的文本内容；voice 类型不传 url 时，会将该文本转换为语音",
+							},
+							"url": map[string]string{
+								"type":        "string",
+								"description": "image、video、voice、file 类型的远程地址",
+							},
+							"app_type": map[string]string{
+								"type":        "integer",
+								"description": "app 类型的应用消息类型",
+							},
+							"app_xml": map[string]string{
+								"type":        "string",
+								"description": "app 类型的应用消息 XML 内容",
+							},
+							"at_sender": map[string]string{
+								"type":        "boolean",
+								"description": "text 类型在群聊中是否@提问的人",
+							},
+							"quote": map[string]string{
+								"type":        "boolean",
+								"description": "text 类型是否引用回复用户当前这条消息",
+							},
+						},
+						"required": []string{"type"},
+					},
+				},
+			},
+			"required": []string{"parts"},
+		},
+	})
+	return &tool
+}
+
+func (t *ReplyTool) BuildSystemPrompt(ctx context.Context, robotCtx *robotctx.RobotContext) (string, error) {
+	return "结构化回复工具：普通文本回复直接输出即可，需要发送图片、视频、语音、文件或多条消息时调用 reply 工具", nil
+}
+
+// ExecuteToolCall 返回结构化回复的 JSON，只用于执行记录，发送使用 BuildReply 的结果
+func (t *ReplyTool) ExecuteToolCall(ctx context.Context, robotCtx *robotctx.RobotContext, toolCall openai.ChatCompletionMessageToolCallUnion) (string, bool, error) {
+	reply, err := BuildReply(robotCtx, toolCall)
+	if err != nil {
+		return "", false, err
+	}
+	replyBytes, err := json.Marshal(reply)
+	if err != nil {
+		return "", false, err
+	}
+	return string(replyBytes), true, nil
+}
+
+// BuildReply 把 reply 工具的参数转换为结构化回复
+func BuildReply(robotCtx *robotctx.RobotContext, toolCall openai.ChatCompletionMessageToolCallUnion) (*dto.StructuredReply, error) {
+	var args replyToolArgs
+	if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err != nil {
+		return nil, fmt.Errorf("解析参数失败: %w", err)
+	}
+	if len(args.Parts) == 0 {
+		return nil, errors.New("参数 parts 不能为空")
+	}
+
+	reply := &dto.StructuredReply{}
+	for _, part := range args.Parts {
+		replyPart := dto.ReplyPart{
+			Type:    part.Type,
+			Text:    part.Text,
+			URL:     part.URL,
+			AppType: part.AppType,
+			AppXML:  part.AppXML,
+		}
+		if part.Type == dto.ReplyPartTypeText {
+			if part.AtSender && strings.HasSuffix(robotCtx.FromWxID, "@chatroom") && robotCtx.SenderWxID != "" {
+				replyPart.At = []string{robotCtx.SenderWxID}
+			}
+			if part.Quote && robotCtx.MessageID > 0 {
+				replyPart.QuoteMessageID = robotCtx.MessageID
+			}
+		}
+		reply.Parts = append(reply.Parts, replyPart)
+	}
+	return reply, nil
+}
@@ -0,0 +1,56 @@
+package openaitools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/pkg/robotctx"
+)
+
+func TestReplyToolExecuteToolCall(t *testing.T) {
+	robotCtx := &robotctx.RobotContext{
+		FromWxID:   "123@chatroom",
+		SenderWxID: "wxid_sender",
+		MessageID:  42,
+	}
+	toolCall := openai.ChatCompletionMessageToolCallUnion{}
+	toolCall.Function.Name = "reply"
+	toolCall.Function.Arguments = `{"parts":[
+		{"type":"text","text":"看图","at_sender":true},
+		{"type":"image","url":"https://example.com/a.png"},
+		{"type":"text","text":"就是这个","quote":true}
+	]}`
+
+	result, immediately, err := NewReplyTool().ExecuteToolCall(context.Background(), robotCtx, toolCall)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !immediately {
+		t.Fatal("reply tool should end the conversation immediately")
+	}
+	var reply dto.StructuredReply
+	if err := json.Unmarshal([]byte(result), &reply); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(reply.Parts) != 3 {
+		t.Fatalf("unexpected reply: %+v", reply)
+	}
+	if len(reply.Parts[0].At) != 1 || reply.Parts[0].At[0] != "wxid_sender" {
+		t.Fatalf("first part should @ the sender: %+v", reply.Parts[0])
+	}
+	if reply.Parts[1].Type != dto.ReplyPartTypeImage || reply.Parts[1].URL != "https://example.com/a.png" {
+		t.Fatalf("unexpected image part: %+v", reply.Parts[1])
+	}
+	if reply.Parts[2].QuoteMessageID != 42 {
+		t.Fatalf("third part should quote the current message: %+v", reply.Parts[2])
+	}
+
+	toolCall.Function.Arguments = `{"parts":[]}`
+	if _, _, err := NewReplyTool().ExecuteToolCall(context.Background(), robotCtx, toolCall); err == nil {
+		t.Fatal("empty parts should be rejected")
+	}
+}
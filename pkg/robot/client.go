@@ -13,9 +13,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-resty/resty/v2"
-	"golang.org/x/time/rate"
 )
 
 type ClientResponse[T any] struct {
@@ -35,35 +35,25 @@ func (c ClientResponse[T]) CheckError(err error) error {
 	if err != nil {
 		return err
 	}
-	if c.Success {
+	if c.Success || c.Code == 0 {
 		return nil
 	}
-	switch c.Code {
-	case 0:
-		return nil
-	case -7:
-		return errors.New("已退出登录")
-	case -1, -2, -3, -4, -5, -6, -8, -9, -10, -11, -12, -13:
-		return errors.New(c.Message)
-	}
-	return nil
+	return NewClientError(c.Code, c.Message)
 }
 
 type Client struct {
-	client      *resty.Client
-	Domain      WechatDomain
-	Proxy       ProxyInfo
-	limiter     *rate.Limiter
-	autoLimiter *rate.Limiter
+	client *resty.Client
+	Domain WechatDomain
+	Proxy  ProxyInfo
+	pacer  *Pacer
 }
 
 func NewClient(domain WechatDomain, proxy ProxyInfo) *Client {
 	return &Client{
-		client:      resty.New(),
-		Domain:      domain,
-		Proxy:       proxy,
-		limiter:     rate.NewLimiter(rate.Every(time.Second), 1),
-		autoLimiter: rate.NewLimiter(rate.Every(15*time.Second), 1),
+		client: resty.New(),
+		Domain: domain,
+		Proxy:  proxy,
+		pacer:  NewPacer(DefaultPacingConfig()),
 	}
 }
 
@@ -71,6 +61,11 @@ func (c *Client) SetProxy(proxy ProxyInfo) {
 	c.Proxy = proxy
 }
 
+// SetPacing 更新限速配置
+func (c *Client) SetPacing(config PacingConfig) {
+	c.pacer.SetConfig(config)
+}
+
 func (c *Client) IsRunning() bool {
 	timeout := time.Second * 1
 	conn, err := net.DialTimeout("tcp", string(c.Domain), timeout)
@@ -82,9 +77,10 @@ func (c *Client) IsRunning() bool {
 }
 
 func (c *Client) GetProfile(wxid string) (resp GetProfileResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionQuery, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[GetProfileResponse]
 	_, err = c.client.R().
 		SetHeader("Content-Type", "application/json").
@@ -115,14 +111,14 @@ func (c *Client) GetCachedInfo(wxid string) (resp LoginData, err error) {
 func (c *Client) BaseResponseErrCheck(baseResponse *BaseResponse) (err error) {
 	if baseResponse != nil && baseResponse.Ret != 0 {
 		if baseResponse.ErrMsg != nil && baseResponse.ErrMsg.String != nil && *baseResponse.ErrMsg.String != "" {
-			err = errors.New(*baseResponse.ErrMsg.String)
+			err = NewClientError(baseResponse.Ret, *baseResponse.ErrMsg.String)
 			return
 		} else {
 			switch baseResponse.Ret {
 			case -104:
-				err = errors.New("发送内容过大")
+				err = NewClientError(baseResponse.Ret, "发送内容过大")
 			default:
-				err = fmt.Errorf("未知的错误代码: %d", baseResponse.Ret)
+				err = NewClientError(baseResponse.Ret, "")
 			}
 		}
 	}
@@ -295,7 +291,7 @@ func (c *Client) LoginData62SMSApply(req Data62LoginRequest) (resp UnifyAuthResp
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -355,7 +351,7 @@ func (c *Client) LoginA16Data(req A16LoginRequest) (resp UnifyAuthResponse, err
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -430,9 +426,10 @@ func (c *Client) SyncMessage(wxid string) (messageResponse SyncMessage, err erro
 
 // MessageRevoke 撤回消息
 func (c *Client) MessageRevoke(req MessageRevokeRequest) (err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionText, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[MessageRevokeResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -445,9 +442,10 @@ func (c *Client) MessageRevoke(req MessageRevokeRequest) (err error) {
 
 // SendTextMessage 发送文本消息
 func (c *Client) SendTextMessage(req SendTextMessageRequest) (newMessages SendTextMessageResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionText, utf8.RuneCountInString(req.Content)); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[SendTextMessageResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -460,9 +458,10 @@ func (c *Client) SendTextMessage(req SendTextMessageRequest) (newMessages SendTe
 }
 
 func (c *Client) MsgSendGroupMassMsgText(req MsgSendGroupMassMsgTextRequest) (newMessages MsgSendGroupMassMsgTextResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionText, utf8.RuneCountInString(req.Content)); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[MsgSendGroupMassMsgTextResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -470,7 +469,7 @@ func (c *Client) MsgSendGroupMassMsgText(req MsgSendGroupMassMsgTextRequest) (ne
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -485,9 +484,10 @@ func (c *Client) MsgSendGroupMassMsgText(req MsgSendGroupMassMsgTextRequest) (ne
 
 // MsgUploadImg  发送图片消息
 func (c *Client) MsgUploadImg(wxid, toWxid, base64 string) (imageMessage MsgUploadImgResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionMedia, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[MsgUploadImgResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -506,9 +506,10 @@ func (c *Client) MsgUploadImg(wxid, toWxid, base64 string) (imageMessage MsgUplo
 // SendImageMessageStream 分片上传图片
 func (c *Client) SendImageMessageStream(req SendImageMessageStreamRequest, file io.Reader, fileHeader *multipart.FileHeader) (imageMessage *MsgUploadImgResponse, err error) {
 	if req.StartPos == 0 {
-		if err = c.limiter.Wait(context.Background()); err != nil {
+		if err = c.pacer.Wait(context.Background(), ActionMedia, 0); err != nil {
 			return
 		}
+		defer func() { c.pacer.Observe(err) }()
 	}
 
 	var requestBody bytes.Buffer
@@ -574,7 +575,7 @@ func (c *Client) SendImageMessageStream(req SendImageMessageStreamRequest, file
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(&result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -591,9 +592,10 @@ func (c *Client) SendImageMessageStream(req SendImageMessageStreamRequest, file
 
 // MsgSendVideo 发送视频消息
 func (c *Client) MsgSendVideo(req MsgSendVideoRequest) (videoMessage MsgSendVideoResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionMedia, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[MsgSendVideoResponse]
 	req.Base64 = "data:video/mp4;base64," + req.Base64
 	req.ImageBase64 = "data:image/jpeg;base64," + req.ImageBase64
@@ -654,9 +656,10 @@ func (c *Client) GenVideoStreamFormData(req MsgSendVideoStreamRequest, file io.R
 // MsgSendVideoThumbStream 分片上传视频缩略图
 func (c *Client) MsgSendVideoThumbStream(req MsgSendVideoStreamRequest, file io.Reader, fileHeader *multipart.FileHeader) (videoMessage *MsgSendVideoResponse, err error) {
 	if req.StartPos == 0 {
-		if err = c.limiter.Wait(context.Background()); err != nil {
+		if err = c.pacer.Wait(context.Background(), ActionMedia, 0); err != nil {
 			return
 		}
+		defer func() { c.pacer.Observe(err) }()
 	}
 
 	var requestBody *bytes.Buffer
@@ -747,7 +750,7 @@ func (c *Client) MsgSendVideoStream(req MsgSendVideoStreamRequest, file io.Reade
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -764,9 +767,10 @@ func (c *Client) MsgSendVideoStream(req MsgSendVideoStreamRequest, file io.Reade
 
 // MsgSendVoice 发送音频消息
 func (c *Client) MsgSendVoice(req MsgSendVoiceRequest) (voiceMessage MsgSendVoiceResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionMedia, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[MsgSendVoiceResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -781,9 +785,10 @@ func (c *Client) MsgSendVoice(req MsgSendVoiceRequest) (voiceMessage MsgSendVoic
 // ToolsSendFile  上传文件
 func (c *Client) ToolsSendFile(req SendFileMessageRequest, file io.Reader, fileHeader *multipart.FileHeader) (fileMessage *SendFileMessageResponse, err error) {
 	if req.StartPos == 0 {
-		if err = c.limiter.Wait(context.Background()); err != nil {
+		if err = c.pacer.Wait(context.Background(), ActionMedia, 0); err != nil {
 			return
 		}
+		defer func() { c.pacer.Observe(err) }()
 	}
 
 	var requestBody bytes.Buffer
@@ -853,7 +858,7 @@ func (c *Client) ToolsSendFile(req SendFileMessageRequest, file io.Reader, fileH
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -870,9 +875,10 @@ func (c *Client) ToolsSendFile(req SendFileMessageRequest, file io.Reader, fileH
 
 // GetAppMsgExt 阅读公众号文章
 func (c *Client) GetAppMsgExt(req GetAppMsgExtRequest) (mp string, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionQuery, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[string]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -886,9 +892,10 @@ func (c *Client) GetAppMsgExt(req GetAppMsgExtRequest) (mp string, err error) {
 
 // SendApp 发送App消息
 func (c *Client) SendApp(req SendAppRequest) (appMessage SendAppResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionText, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[SendAppResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -902,9 +909,10 @@ func (c *Client) SendApp(req SendAppRequest) (appMessage SendAppResponse, err er
 
 // SendEmoji 发送表情消息
 func (c *Client) SendEmoji(req SendEmojiRequest) (emojiMessage SendEmojiResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionMedia, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[SendEmojiResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -918,9 +926,10 @@ func (c *Client) SendEmoji(req SendEmojiRequest) (emojiMessage SendEmojiResponse
 
 // ShareLink 发送分享链接消息
 func (c *Client) ShareLink(req ShareLinkRequest) (shareLinkMessage ShareLinkResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionText, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[ShareLinkResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -934,9 +943,10 @@ func (c *Client) ShareLink(req ShareLinkRequest) (shareLinkMessage ShareLinkResp
 
 // SendCDNFile 转发文件消息（转发，并非上传）
 func (c *Client) SendCDNFile(req SendCDNAttachmentRequest) (cdnFileMessage SendCDNFileResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionMedia, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[SendCDNFileResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -950,9 +960,10 @@ func (c *Client) SendCDNFile(req SendCDNAttachmentRequest) (cdnFileMessage SendC
 
 // SendCDNImg 转发图片消息（转发，并非上传）
 func (c *Client) SendCDNImg(req SendCDNAttachmentRequest) (cdnImageMessage SendCDNImgResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionMedia, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[SendCDNImgResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -966,9 +977,10 @@ func (c *Client) SendCDNImg(req SendCDNAttachmentRequest) (cdnImageMessage SendC
 
 // SendCDNVideo 转发视频消息（转发，并非上传）
 func (c *Client) SendCDNVideo(req SendCDNAttachmentRequest) (cdnVideoMessage SendCDNVideoResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionMedia, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[SendCDNVideoResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -981,9 +993,10 @@ func (c *Client) SendCDNVideo(req SendCDNAttachmentRequest) (cdnVideoMessage Sen
 }
 
 func (c *Client) FriendGetFriendstate(Wxid, UserName string) (resp MMBizJsApiGetUserOpenIdResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionQuery, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[MMBizJsApiGetUserOpenIdResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -994,7 +1007,7 @@ func (c *Client) FriendGetFriendstate(Wxid, UserName string) (resp MMBizJsApiGet
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -1008,9 +1021,10 @@ func (c *Client) FriendGetFriendstate(Wxid, UserName string) (resp MMBizJsApiGet
 }
 
 func (c *Client) FriendSearch(req FriendSearchRequest) (resp SearchContactResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionQuery, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[SearchContactResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1018,7 +1032,7 @@ func (c *Client) FriendSearch(req FriendSearchRequest) (resp SearchContactRespon
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -1032,9 +1046,10 @@ func (c *Client) FriendSearch(req FriendSearchRequest) (resp SearchContactRespon
 }
 
 func (c *Client) FriendSendRequest(req FriendSendRequestParam) (resp VerifyUserResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionFriendRequest, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[VerifyUserResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1042,7 +1057,7 @@ func (c *Client) FriendSendRequest(req FriendSendRequestParam) (resp VerifyUserR
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -1056,9 +1071,10 @@ func (c *Client) FriendSendRequest(req FriendSendRequestParam) (resp VerifyUserR
 }
 
 func (c *Client) FriendSetRemarks(wxid, toWxid, remarks string) (resp OplogResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionQuery, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[OplogResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1112,9 +1128,10 @@ func (c *Client) GetContactDetail(wxid, chatRoomID string, towxids []string) (re
 
 // FriendPassVerify 通过好友验证
 func (c *Client) FriendPassVerify(req FriendPassVerifyRequest) (verifyUserResponse VerifyUserResponse, err error) {
-	if err = c.autoLimiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionFriend, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[VerifyUserResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1122,7 +1139,7 @@ func (c *Client) FriendPassVerify(req FriendPassVerifyRequest) (verifyUserRespon
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -1203,9 +1220,10 @@ func (c *Client) DownloadFile(req DownloadFileRequest) (filebase64 string, err e
 }
 
 func (c *Client) CreateChatRoom(wxid string, contactIDs []string) (createChatRoomResp CreateChatRoomResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionCreateRoom, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[CreateChatRoomResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1221,9 +1239,10 @@ func (c *Client) CreateChatRoom(wxid string, contactIDs []string) (createChatRoo
 }
 
 func (c *Client) GroupAddChatRoomMember(wxid, chatRoomName string, contactIDs []string) (memberResp InviteChatRoomMemberResponse, err error) {
-	if err = c.autoLimiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionGroupInvite, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[InviteChatRoomMemberResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1240,9 +1259,10 @@ func (c *Client) GroupAddChatRoomMember(wxid, chatRoomName string, contactIDs []
 }
 
 func (c *Client) GroupInviteChatRoomMember(wxid, chatRoomName string, contactIDs []string) (memberResp InviteChatRoomMemberResponse, err error) {
-	if err = c.autoLimiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionGroupInvite, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[InviteChatRoomMemberResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1259,9 +1279,10 @@ func (c *Client) GroupInviteChatRoomMember(wxid, chatRoomName string, contactIDs
 }
 
 func (c *Client) GroupConsentToJoin(wxid, Url string) (QID string, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionQuery, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[string]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1277,9 +1298,10 @@ func (c *Client) GroupConsentToJoin(wxid, Url string) (QID string, err error) {
 }
 
 func (c *Client) GetChatRoomMemberDetail(wxid, QID string) (chatRoomMember []ChatRoomMember, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionQuery, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[ChatRoomMemberDetail]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1372,9 +1394,10 @@ func (c *Client) GroupQuit(wxid, QID string) (err error) {
 
 // FriendCircleComment 朋友圈评论
 func (c *Client) FriendCircleComment(req FriendCircleCommentRequest) (resp SnsCommentResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionMoments, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[SnsCommentResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1382,7 +1405,7 @@ func (c *Client) FriendCircleComment(req FriendCircleCommentRequest) (resp SnsCo
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -1404,7 +1427,7 @@ func (c *Client) FriendCircleGetDetail(req FriendCircleGetDetailRequest) (resp S
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -1426,7 +1449,7 @@ func (c *Client) FriendCircleGetIdDetail(req FriendCircleGetIdDetailRequest) (re
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -1492,7 +1515,7 @@ func (c *Client) FriendCircleUpload(wxid string, base64 string) (resp FriendCirc
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -1520,9 +1543,10 @@ func (c *Client) FriendCircleCdnSnsUploadVideo(req FriendCircleCdnSnsUploadVideo
 
 // 朋友圈操作
 func (c *Client) FriendCircleOperation(req FriendCircleOperationRequest) (resp SnsObjectOpResponse, err error) {
-	if err = c.limiter.Wait(context.Background()); err != nil {
+	if err = c.pacer.Wait(context.Background(), ActionMoments, 0); err != nil {
 		return
 	}
+	defer func() { c.pacer.Observe(err) }()
 	var result ClientResponse[SnsObjectOpResponse]
 	_, err = c.client.R().
 		SetResult(&result).
@@ -1530,7 +1554,7 @@ func (c *Client) FriendCircleOperation(req FriendCircleOperationRequest) (resp S
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -1565,7 +1589,7 @@ func (c *Client) FriendCircleMessages(req FriendCircleMessagesRequest) (resp Fri
 	if err = result.CheckError(err); err != nil {
 		err2 := c.BaseResponseErrCheck(result.Data.BaseResponse)
 		if err2 != nil {
-			err = fmt.Errorf("%s\n%s", err.Error(), err2.Error())
+			err = errors.Join(err, err2)
 			return
 		}
 		return
@@ -0,0 +1,103 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// 协议服务返回的错误类别，通过 errors.Is 判断
+var (
+	ErrLoggedOut    = errors.New("已退出登录")
+	ErrRiskControl  = errors.New("触发风控")
+	ErrRateLimited  = errors.New("操作过于频繁")
+	ErrNotFound     = errors.New("目标不存在")
+	ErrInvalidParam = errors.New("参数错误")
+	ErrServer       = errors.New("协议服务错误")
+)
+
+// errorCodes 已知含义的错误码，优先于错误信息归类
+var errorCodes = map[int]error{
+	-7:   ErrLoggedOut,
+	-104: ErrInvalidParam, // 发送内容过大
+}
+
+// errorKeywords 错误码无法归类时按错误信息中的关键词归类，靠前的类别优先，都不匹配的按协议服务错误处理
+var errorKeywords = []struct {
+	kind     error
+	keywords []string
+}{
+	{ErrLoggedOut, []string{"退出登录", "未登录", "登录失效", "已离线", "掉线"}},
+	{ErrRateLimited, []string{"操作过于频繁", "操作太频繁", "请求过于频繁", "频率过快"}},
+	{ErrRiskControl, []string{"风控", "安全风险", "存在风险", "账号异常", "帐号异常", "被限制登录", "功能被限制", "限制使用", "被封禁"}},
+	{ErrNotFound, []string{"不存在", "未找到", "找不到"}},
+	{ErrInvalidParam, []string{"参数错误", "参数无效", "参数不正确", "参数不能为空", "内容过大"}},
+}
+
+// ClientError 协议服务返回的错误，Unwrap 返回所属的错误类别
+type ClientError struct {
+	Code    int
+	Message string
+	Kind    error
+}
+
+// NewClientError 根据错误码和错误信息创建错误并归类
+func NewClientError(code int, message string) *ClientError {
+	e := &ClientError{Code: code, Message: message, Kind: ErrServer}
+	if kind, ok := errorCodes[code]; ok {
+		e.Kind = kind
+		return e
+	}
+	for _, item := range errorKeywords {
+		for _, keyword := range item.keywords {
+			if strings.Contains(message, keyword) {
+				e.Kind = item.kind
+				return e
+			}
+		}
+	}
+	return e
+}
+
+func (e *ClientError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.Kind != ErrServer {
+		return e.Kind.Error()
+	}
+	return fmt.Sprintf("未知的错误代码: %d", e.Code)
+}
+
+func (e *ClientError) Unwrap() error {
+	return e.Kind
+}
+
+// Retryable 稍后重试是否可能成功，退出登录、风控、参数错误等需要人工介入或者修改请求
+func (e *ClientError) Retryable() bool {
+	return e.Kind == ErrRateLimited || e.Kind == ErrServer
+}
+
+// IsRetryable 错误是否可以重试，协议服务的错误按类别判断，网络错误可以重试，其余错误不重试。
+// 合并的多个错误中只要有一个需要人工介入就不重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, kind := range []error{ErrLoggedOut, ErrRiskControl, ErrNotFound, ErrInvalidParam} {
+		if errors.Is(err, kind) {
+			return false
+		}
+	}
+	var clientErr *ClientError
+	if errors.As(err, &clientErr) {
+		return clientErr.Retryable()
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrDailyQuotaExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
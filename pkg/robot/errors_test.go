@@ -0,0 +1,81 @@
+package robot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientResponseCheckError(t *testing.T) {
+	cases := []struct {
+		resp      ClientResponse[any]
+		kind      error
+		retryable bool
+	}{
+		{ClientResponse[any]{Code: -7}, ErrLoggedOut, false},
+		{ClientResponse[any]{Code: -1, Message: "操作过于频繁，请稍后再试"}, ErrRateLimited, true},
+		{ClientResponse[any]{Code: -2, Message: "账号存在安全风险"}, ErrRiskControl, false},
+		{ClientResponse[any]{Code: -3, Message: "联系人不存在"}, ErrNotFound, false},
+		{ClientResponse[any]{Code: -2, Message: "账号异常，部分功能被限制"}, ErrRiskControl, false},
+		{ClientResponse[any]{Code: -104, Message: "发送内容过大"}, ErrInvalidParam, false},
+		{ClientResponse[any]{Code: -1, Message: "服务器内部异常"}, ErrServer, true},
+		{ClientResponse[any]{Code: -1, Message: "上传文件大小超过限制"}, ErrServer, true},
+		{ClientResponse[any]{Code: 1001, Message: "Unknown"}, ErrServer, true},
+	}
+	for _, c := range cases {
+		err := c.resp.CheckError(nil)
+		if !errors.Is(err, c.kind) {
+			t.Errorf("code %d: expected %v, got %v", c.resp.Code, c.kind, err)
+		}
+		if IsRetryable(fmt.Errorf("wrapped: %w", err)) != c.retryable {
+			t.Errorf("code %d: expected retryable=%v", c.resp.Code, c.retryable)
+		}
+	}
+	if err := (ClientResponse[any]{Code: 0}).CheckError(nil); err != nil {
+		t.Errorf("code 0 should succeed, got %v", err)
+	}
+	if err := (ClientResponse[any]{Success: true, Code: -1}).CheckError(nil); err != nil {
+		t.Errorf("success response should succeed, got %v", err)
+	}
+	if err := (ClientResponse[any]{Code: -7}).CheckError(nil); err.Error() != "已退出登录" {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+func TestClientErrorJoinedWithBaseResponse(t *testing.T) {
+	errMsg := "账号存在安全风险"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ClientResponse[SearchContactResponse]{
+			Code:    -1,
+			Message: "搜索联系人失败",
+			Data: SearchContactResponse{
+				BaseResponse: &BaseResponse{Ret: -2, ErrMsg: &SKBuiltinStringT{String: &errMsg}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(WechatDomain(strings.TrimPrefix(server.URL, "http://")), ProxyInfo{})
+	_, err := client.FriendSearch(FriendSearchRequest{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !errors.Is(err, ErrRiskControl) {
+		t.Errorf("expected risk control error to survive joining, got %v", err)
+	}
+	var clientErr *ClientError
+	if !errors.As(err, &clientErr) || clientErr.Code != -1 {
+		t.Errorf("expected the first client error, got %v", err)
+	}
+	if IsRetryable(err) {
+		t.Errorf("joined error should not be retryable, got %v", err)
+	}
+	if err.Error() != "搜索联系人失败\n"+errMsg {
+		t.Errorf("unexpected message: %q", err.Error())
+	}
+}
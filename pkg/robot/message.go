@@ -2,6 +2,8 @@ package robot
 
 import (
 	"encoding/xml"
+	"fmt"
+	"strings"
 	"wechat-robot-client/model"
 )
 
@@ -92,6 +94,32 @@ type NewFriendMessage struct {
 	BrandList         BrandList `xml:"brandlist"`
 }
 
+// ShareCardMessage 名片消息，个人名片的 username 为加密的 v3 用户名，配合 antispamticket 添加好友
+type ShareCardMessage struct {
+	XMLName         xml.Name `xml:"msg"`
+	Username        string   `xml:"username,attr"`
+	Nickname        string   `xml:"nickname,attr"`
+	Alias           string   `xml:"alias,attr"`
+	BigHeadImgURL   string   `xml:"bigheadimgurl,attr"`
+	SmallHeadImgURL string   `xml:"smallheadimgurl,attr"`
+	Province        string   `xml:"province,attr"`
+	City            string   `xml:"city,attr"`
+	Sign            string   `xml:"sign,attr"`
+	Sex             int      `xml:"sex,attr"`
+	CertFlag        int      `xml:"certflag,attr"`
+	CertInfo        string   `xml:"certinfo,attr"`
+	Scene           int      `xml:"scene,attr"`
+	RegionCode      string   `xml:"regionCode,attr"`
+	AntispamTicket  string   `xml:"antispamticket,attr"`
+	BrandIconURL    string   `xml:"brandIconUrl,attr"`
+	BrandHomeURL    string   `xml:"brandHomeUrl,attr"`
+}
+
+// IsOfficialAccount 是否是公众号名片
+func (c ShareCardMessage) IsOfficialAccount() bool {
+	return c.CertFlag != 0 || strings.HasPrefix(c.Username, "gh_")
+}
+
 type BrandList struct {
 	XMLName xml.Name `xml:"brandlist"`
 	Count   string   `xml:"count,attr"`
@@ -136,6 +164,39 @@ type EmojiMessage struct {
 	Desc              string `xml:"desc,attr"`
 }
 
+type XMLLocationMessage struct {
+	XMLName  xml.Name        `xml:"msg"`
+	Location LocationMessage `xml:"location"`
+}
+
+// LocationMessage 位置消息，x 为纬度，y 为经度
+type LocationMessage struct {
+	X            float64 `xml:"x,attr"`
+	Y            float64 `xml:"y,attr"`
+	Scale        int     `xml:"scale,attr"`
+	Label        string  `xml:"label,attr"`
+	MapType      string  `xml:"maptype,attr"`
+	PoiName      string  `xml:"poiname,attr"`
+	PoiID        string  `xml:"poiid,attr"`
+	FromUsername string  `xml:"fromusername,attr"`
+}
+
+// Describe 位置的可读描述，优先使用地点名称
+func (l LocationMessage) Describe() string {
+	poiName := strings.TrimSpace(l.PoiName)
+	label := strings.TrimSpace(l.Label)
+	switch {
+	case poiName != "" && label != "" && poiName != label:
+		return fmt.Sprintf("%s（%s）", poiName, label)
+	case poiName != "":
+		return poiName
+	case label != "":
+		return label
+	default:
+		return fmt.Sprintf("纬度%.6f，经度%.6f", l.X, l.Y)
+	}
+}
+
 type XmlMessage struct {
 	XMLName      xml.Name   `xml:"msg"`
 	AppMsg       AppMessage `xml:"appmsg"`
@@ -0,0 +1,236 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ActionClass 限速的操作类别，同一类别共享发送预算和每日配额
+type ActionClass string
+
+const (
+	ActionGlobal        ActionClass = "global"           // 所有操作共享的预算，每次操作都要同时满足全局和所属类别的预算
+	ActionQuery         ActionClass = "query"            // 查询类接口
+	ActionText          ActionClass = "text"             // 文本、应用消息
+	ActionMedia         ActionClass = "media"            // 图片、视频、语音、文件、表情
+	ActionFriendRequest ActionClass = "friend_request"   // 主动添加好友
+	ActionFriend        ActionClass = "friend"           // 通过好友验证
+	ActionCreateRoom    ActionClass = "create_chat_room" // 建群
+	ActionGroupInvite   ActionClass = "group_invite"     // 拉人进群
+	ActionMoments       ActionClass = "moments"          // 朋友圈点赞、评论
+)
+
+// ErrDailyQuotaExceeded 当天的操作次数已经用完
+var ErrDailyQuotaExceeded = errors.New("今日操作次数已达上限")
+
+// 触发风控后限速倍数的上限
+const maxRiskPenalty = 16
+
+// ActionBudget 单个操作类别的预算
+type ActionBudget struct {
+	Interval   time.Duration // 两次操作之间的最小间隔，0 表示不限制
+	Burst      int           // 允许的突发次数
+	DailyQuota int           // 每日配额，0 表示不限制
+}
+
+// PacingConfig 客户端限速配置
+// Enabled 为 false 时只按各类别的间隔限速，不启用每日配额、随机抖动、打字延迟和风控自适应
+type PacingConfig struct {
+	Enabled            bool
+	Budgets            map[ActionClass]ActionBudget
+	JitterMin          time.Duration // 随机抖动的下限，查询类接口不抖动
+	JitterMax          time.Duration // 随机抖动的上限
+	TypingDelayPerChar time.Duration // 文本消息每个字的打字延迟
+	MaxTypingDelay     time.Duration // 打字延迟的上限
+	RiskBackoffFactor  float64       // 触发风控后限速间隔放大的倍数
+	RiskCooldown       time.Duration // 触发风控后多久恢复正常限速
+}
+
+// DefaultPacingConfig 默认配置，沿用原先的固定限速：所有操作共享每秒一次的全局限速，
+// 通过好友验证和拉人进群另外各自限制为每 15 秒一次
+func DefaultPacingConfig() PacingConfig {
+	return PacingConfig{
+		Budgets: map[ActionClass]ActionBudget{
+			ActionGlobal:        {Interval: time.Second, Burst: 1},
+			ActionQuery:         {},
+			ActionText:          {},
+			ActionMedia:         {},
+			ActionFriendRequest: {},
+			ActionFriend:        {Interval: 15 * time.Second, Burst: 1},
+			ActionCreateRoom:    {},
+			ActionGroupInvite:   {Interval: 15 * time.Second, Burst: 1},
+			ActionMoments:       {},
+		},
+		JitterMin:          300 * time.Millisecond,
+		JitterMax:          1500 * time.Millisecond,
+		TypingDelayPerChar: 50 * time.Millisecond,
+		MaxTypingDelay:     5 * time.Second,
+		RiskBackoffFactor:  2,
+		RiskCooldown:       30 * time.Minute,
+	}
+}
+
+// IsRiskControlError 是否是触发微信风控或者操作频繁导致的错误
+func IsRiskControlError(err error) bool {
+	return errors.Is(err, ErrRiskControl) || errors.Is(err, ErrRateLimited)
+}
+
+// Pacer 按操作类别对请求限速，模拟真人的操作节奏，触发风控后自动收紧限速
+type Pacer struct {
+	mu        sync.Mutex
+	config    PacingConfig
+	limiters  map[ActionClass]*rate.Limiter
+	used      map[ActionClass]int
+	day       string
+	penalty   float64
+	riskUntil time.Time
+}
+
+func NewPacer(config PacingConfig) *Pacer {
+	p := &Pacer{
+		limiters: make(map[ActionClass]*rate.Limiter),
+		used:     make(map[ActionClass]int),
+		penalty:  1,
+	}
+	p.SetConfig(config)
+	return p
+}
+
+// SetConfig 更新配置，已有的限速器保留当前的令牌，只调整速率
+func (p *Pacer) SetConfig(config PacingConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = config
+	if !config.Enabled {
+		p.penalty = 1
+	}
+	for class, budget := range config.Budgets {
+		burst := max(budget.Burst, 1)
+		limiter, ok := p.limiters[class]
+		if !ok {
+			p.limiters[class] = rate.NewLimiter(p.limit(budget), burst)
+			continue
+		}
+		limiter.SetLimit(p.limit(budget))
+		limiter.SetBurst(burst)
+	}
+}
+
+func (p *Pacer) limit(budget ActionBudget) rate.Limit {
+	if budget.Interval <= 0 {
+		return rate.Inf
+	}
+	return rate.Every(time.Duration(float64(budget.Interval) * p.penalty))
+}
+
+func (p *Pacer) applyPenalty() {
+	for class, limiter := range p.limiters {
+		limiter.SetLimit(p.limit(p.config.Budgets[class]))
+	}
+}
+
+// Wait 等待 class 类别和全局的发送预算，textLen 为文本消息的字数，用于计算打字延迟
+func (p *Pacer) Wait(ctx context.Context, class ActionClass, textLen int) error {
+	classes := []ActionClass{class, ActionGlobal}
+	p.mu.Lock()
+	if p.penalty > 1 && time.Now().After(p.riskUntil) {
+		p.penalty = 1
+		p.applyPenalty()
+		log.Printf("[Pacer] 风控冷却结束，恢复正常限速")
+	}
+	if p.config.Enabled {
+		today := time.Now().Format(time.DateOnly)
+		if p.day != today {
+			p.day = today
+			p.used = make(map[ActionClass]int)
+		}
+		// 全局和类别的配额都有剩余才计数，避免一方用完时另一方被白白扣减
+		for _, c := range classes {
+			if quota := p.config.Budgets[c].DailyQuota; quota > 0 && p.used[c] >= quota {
+				p.mu.Unlock()
+				return fmt.Errorf("%w: %s", ErrDailyQuotaExceeded, c)
+			}
+		}
+		for _, c := range classes {
+			if p.config.Budgets[c].DailyQuota > 0 {
+				p.used[c]++
+			}
+		}
+	}
+	limiters := make([]*rate.Limiter, 0, len(classes))
+	for _, c := range classes {
+		if limiter := p.limiters[c]; limiter != nil {
+			limiters = append(limiters, limiter)
+		}
+	}
+	delay := p.humanDelay(class, textLen)
+	p.mu.Unlock()
+
+	for _, limiter := range limiters {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// humanDelay 随机抖动加上按字数计算的打字延迟
+func (p *Pacer) humanDelay(class ActionClass, textLen int) time.Duration {
+	if !p.config.Enabled || class == ActionQuery {
+		return 0
+	}
+	var delay time.Duration
+	if p.config.JitterMax > p.config.JitterMin {
+		delay = p.config.JitterMin + time.Duration(rand.Int63n(int64(p.config.JitterMax-p.config.JitterMin)))
+	} else {
+		delay = p.config.JitterMin
+	}
+	if class == ActionText && p.config.TypingDelayPerChar > 0 {
+		typing := time.Duration(textLen) * p.config.TypingDelayPerChar
+		if p.config.MaxTypingDelay > 0 {
+			typing = min(typing, p.config.MaxTypingDelay)
+		}
+		delay += typing
+	}
+	return delay
+}
+
+// Observe 根据请求结果调整限速，触发风控时放大所有类别的限速间隔
+func (p *Pacer) Observe(err error) {
+	if !IsRiskControlError(err) {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.config.Enabled || p.config.RiskBackoffFactor <= 1 {
+		return
+	}
+	p.penalty = min(p.penalty*p.config.RiskBackoffFactor, maxRiskPenalty)
+	p.riskUntil = time.Now().Add(p.config.RiskCooldown)
+	p.applyPenalty()
+	log.Printf("[Pacer] 疑似触发风控(%v)，限速间隔放大到 %.0f 倍，持续到 %s", err, p.penalty, p.riskUntil.Format(time.DateTime))
+}
+
+// Penalty 当前的限速倍数，1 表示正常
+func (p *Pacer) Penalty() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.penalty
+}
@@ -0,0 +1,95 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPacer(t *testing.T) {
+	config := DefaultPacingConfig()
+	config.Enabled = true
+	config.JitterMin = 0
+	config.JitterMax = 0
+	config.TypingDelayPerChar = 0
+	config.RiskCooldown = 50 * time.Millisecond
+	config.Budgets[ActionGlobal] = ActionBudget{}
+	config.Budgets[ActionText] = ActionBudget{Interval: 0, DailyQuota: 2}
+	pacer := NewPacer(config)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := pacer.Wait(ctx, ActionText, 10); err != nil {
+			t.Fatalf("wait %d: %v", i, err)
+		}
+	}
+	if err := pacer.Wait(ctx, ActionText, 10); !errors.Is(err, ErrDailyQuotaExceeded) {
+		t.Fatalf("daily quota should be exceeded, got %v", err)
+	}
+
+	pacer.Observe(NewClientError(-1, "参数不能为空"))
+	if pacer.Penalty() != 1 {
+		t.Fatalf("non risk error should not tighten limits, got %v", pacer.Penalty())
+	}
+	pacer.Observe(NewClientError(-1, "操作过于频繁，请稍后再试"))
+	pacer.Observe(NewClientError(-1, "操作过于频繁，请稍后再试"))
+	if pacer.Penalty() != 4 {
+		t.Fatalf("risk errors should tighten limits, got %v", pacer.Penalty())
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := pacer.Wait(ctx, ActionQuery, 0); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if pacer.Penalty() != 1 {
+		t.Fatalf("limits should relax after cooldown, got %v", pacer.Penalty())
+	}
+}
+
+func TestPacerGlobalBudget(t *testing.T) {
+	config := DefaultPacingConfig()
+	config.Enabled = true
+	config.JitterMin = 0
+	config.JitterMax = 0
+	config.TypingDelayPerChar = 0
+	config.Budgets[ActionGlobal] = ActionBudget{Interval: 50 * time.Millisecond, Burst: 1, DailyQuota: 3}
+	config.Budgets[ActionMedia] = ActionBudget{DailyQuota: 1}
+	pacer := NewPacer(config)
+	ctx := context.Background()
+
+	start := time.Now()
+	for _, class := range []ActionClass{ActionText, ActionMedia, ActionQuery} {
+		if err := pacer.Wait(ctx, class, 0); err != nil {
+			t.Fatalf("wait %s: %v", class, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("different classes should share the global interval, took %v", elapsed)
+	}
+	if err := pacer.Wait(ctx, ActionMedia, 0); !errors.Is(err, ErrDailyQuotaExceeded) {
+		t.Fatalf("media quota should be exceeded, got %v", err)
+	}
+	if err := pacer.Wait(ctx, ActionText, 0); !errors.Is(err, ErrDailyQuotaExceeded) {
+		t.Fatalf("global quota should be exceeded, got %v", err)
+	}
+}
+
+func TestPacerHumanDelay(t *testing.T) {
+	config := DefaultPacingConfig()
+	config.Enabled = true
+	config.JitterMin = 100 * time.Millisecond
+	config.JitterMax = 200 * time.Millisecond
+	config.TypingDelayPerChar = 10 * time.Millisecond
+	config.MaxTypingDelay = 300 * time.Millisecond
+	pacer := NewPacer(config)
+
+	if delay := pacer.humanDelay(ActionQuery, 100); delay != 0 {
+		t.Fatalf("query actions should not be delayed, got %v", delay)
+	}
+	if delay := pacer.humanDelay(ActionMedia, 100); delay < 100*time.Millisecond || delay >= 200*time.Millisecond {
+		t.Fatalf("media delay should only contain jitter, got %v", delay)
+	}
+	if delay := pacer.humanDelay(ActionText, 100); delay < 400*time.Millisecond || delay >= 500*time.Millisecond {
+		t.Fatalf("typing delay should be capped, got %v", delay)
+	}
+}
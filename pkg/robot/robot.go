@@ -33,7 +33,7 @@ type Robot struct {
 	Status            model.RobotStatus
 	DeviceID          string
 	DeviceName        string
-	Client            *Client
+	Client            Transport
 	LoginTime         int64
 	HeartbeatContext  context.Context
 	HeartbeatCancel   func()
@@ -17,6 +17,25 @@ func TestXmlDecoder(t *testing.T) {
 	}
 }
 
+func TestXmlDecoderLocation(t *testing.T) {
+	var robot = &Robot{}
+	var locationXml XMLLocationMessage
+	msg := `<?xml version="1.0"?>
+<msg>
+	<location x="22.543096" y="114.057865" scale="15" label="广东省深圳市福田区福华一路" maptype="roadmap" poiname="市民中心" poiid="qqmap_123" fromusername="wxid_abc" />
+</msg>`
+	if err := robot.XmlDecoder(msg, &locationXml); err != nil {
+		t.Fatalf("XmlDecoder failed: %v", err)
+	}
+	location := locationXml.Location
+	if location.X != 22.543096 || location.Y != 114.057865 || location.Scale != 15 {
+		t.Errorf("unexpected coordinate: %+v", location)
+	}
+	if got := location.Describe(); got != "市民中心（广东省深圳市福田区福华一路）" {
+		t.Errorf("unexpected describe: %s", got)
+	}
+}
+
 func TestDownloadImage(t *testing.T) {
 	var robot = &Robot{
 		WxID: "wxid_7bpstqonj92212",
@@ -138,3 +157,29 @@ func TestDownloadFile(t *testing.T) {
 	}
 	fmt.Println(filename)
 }
+
+// memoryTransport 只实现用到的方法，其余方法调用时会因为嵌入的接口为空而 panic
+type memoryTransport struct {
+	Transport
+	sent []SendTextMessageRequest
+}
+
+func (m *memoryTransport) SendTextMessage(req SendTextMessageRequest) (SendTextMessageResponse, error) {
+	m.sent = append(m.sent, req)
+	return SendTextMessageResponse{}, nil
+}
+
+func TestRobotWithMemoryTransport(t *testing.T) {
+	transport := &memoryTransport{}
+	robot := &Robot{WxID: "wxid_robot", Client: transport}
+	if _, err := robot.SendTextMessage("123@chatroom", "hello", "wxid_a", "wxid_b"); err != nil {
+		t.Fatalf("SendTextMessage failed: %v", err)
+	}
+	if len(transport.sent) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(transport.sent))
+	}
+	req := transport.sent[0]
+	if req.Wxid != "wxid_robot" || req.ToWxid != "123@chatroom" || req.Content != "hello" || req.At != "wxid_a,wxid_b" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+}
@@ -0,0 +1,125 @@
+package robottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenAIServer 模拟 OpenAI 兼容的对话补全接口，按顺序返回预设的文本回复，支持流式和非流式请求
+type OpenAIServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	replies  []string
+	requests [][]byte
+}
+
+// DefaultOpenAIReply 预设回复用完后返回的内容
+const DefaultOpenAIReply = "好的"
+
+func NewOpenAIServer(replies ...string) *OpenAIServer {
+	s := &OpenAIServer{replies: replies}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// BaseURL 对话补全接口的基础地址，对应配置里的 chat_base_url
+func (s *OpenAIServer) BaseURL() string {
+	return s.URL + "/v1"
+}
+
+// AddReply 追加预设回复
+func (s *OpenAIServer) AddReply(replies ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, replies...)
+}
+
+// Requests 返回收到的全部请求体
+func (s *OpenAIServer) Requests() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.requests...)
+}
+
+func (s *OpenAIServer) nextReply(body []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, body)
+	if len(s.replies) == 0 {
+		return DefaultOpenAIReply
+	}
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return reply
+}
+
+func (s *OpenAIServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/chat/completions") {
+		http.NotFound(w, r)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	var req struct {
+		Model  string `json:"model"`
+		Stream bool   `json:"stream"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reply := s.nextReply(body)
+	created := time.Now().Unix()
+
+	if !req.Stream {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":      "chatcmpl-robottest",
+			"object":  "chat.completion",
+			"created": created,
+			"model":   req.Model,
+			"choices": []map[string]any{{
+				"index":         0,
+				"message":       map[string]any{"role": "assistant", "content": reply},
+				"finish_reason": "stop",
+			}},
+			"usage": map[string]any{"prompt_tokens": 0, "completion_tokens": 0, "total_tokens": 0},
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	writeChunk := func(delta map[string]any, finishReason any) {
+		chunk, _ := json.Marshal(map[string]any{
+			"id":      "chatcmpl-robottest",
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   req.Model,
+			"choices": []map[string]any{{
+				"index":         0,
+				"delta":         delta,
+				"finish_reason": finishReason,
+			}},
+		})
+		fmt.Fprintf(w, "data: %s\n\n", chunk)
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+	// 按行拆分成多个分片，模拟真实的流式输出
+	for index, piece := range strings.SplitAfter(reply, "\n") {
+		delta := map[string]any{"content": piece}
+		if index == 0 {
+			delta["role"] = "assistant"
+		}
+		writeChunk(delta, nil)
+	}
+	writeChunk(map[string]any{}, "stop")
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
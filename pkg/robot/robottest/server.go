@@ -0,0 +1,304 @@
+// Package robottest 提供模拟的微信 iPad 协议服务端和 OpenAI 兼容接口，用于端到端测试
+package robottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/robot"
+)
+
+// Call 一次对服务端接口的调用记录
+type Call struct {
+	Path  string
+	Query url.Values
+	Body  []byte
+}
+
+// Decode 将请求体解析到 v
+func (c Call) Decode(v any) error {
+	return json.Unmarshal(c.Body, v)
+}
+
+// HandlerFunc 自定义接口的处理函数，返回值作为响应的 Data，返回错误时响应失败
+type HandlerFunc func(call Call) (any, error)
+
+// Server 模拟的微信 iPad 协议服务端
+// 入站消息通过 PushMessage 预先写入，由下一次消息同步返回；所有接口调用都会被记录下来供断言
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	inbound  []robot.Message
+	calls    []Call
+	contacts map[string]robot.Contact
+	members  map[string][]robot.ChatRoomMember
+	handlers map[string]HandlerFunc
+	msgID    int64
+}
+
+func NewServer() *Server {
+	s := &Server{
+		contacts: make(map[string]robot.Contact),
+		members:  make(map[string][]robot.ChatRoomMember),
+		handlers: make(map[string]HandlerFunc),
+		msgID:    time.Now().UnixNano() / 1000,
+	}
+	s.handlers[robot.MsgSync] = s.handleSync
+	s.handlers[robot.MsgSendTxt] = s.handleSendText
+	s.handlers[robot.MsgUploadImg] = s.handleUploadImg
+	s.handlers[robot.MsgSendApp] = s.handleSendApp
+	s.handlers[robot.FriendGetContactList] = s.handleGetContactList
+	s.handlers[robot.FriendGetContactDetail] = s.handleGetContactDetail
+	s.handlers[robot.GroupGetChatRoomMemberDetail] = s.handleGetChatRoomMemberDetail
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Domain 服务端地址，用于创建 robot.Client
+func (s *Server) Domain() robot.WechatDomain {
+	return robot.WechatDomain(strings.TrimPrefix(s.URL, "http://"))
+}
+
+// NewClient 创建连接到该服务端的客户端
+func (s *Server) NewClient() *robot.Client {
+	return robot.NewClient(s.Domain(), robot.ProxyInfo{})
+}
+
+// Handle 注册或者覆盖接口的处理函数，path 为 robot 包中的接口路径常量
+func (s *Server) Handle(path string, handler HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[path] = handler
+}
+
+func (s *Server) nextMsgID() int64 {
+	s.msgID++
+	return s.msgID
+}
+
+// PushMessage 写入一条入站消息，MsgId、NewMsgId、CreateTime 为空时自动生成
+func (s *Server) PushMessage(message robot.Message) robot.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if message.MsgId == 0 {
+		message.MsgId = s.nextMsgID()
+	}
+	if message.NewMsgId == 0 {
+		message.NewMsgId = s.nextMsgID()
+	}
+	if message.CreateTime == 0 {
+		message.CreateTime = time.Now().Unix()
+	}
+	s.inbound = append(s.inbound, message)
+	return message
+}
+
+// PushText 写入一条文本消息，群聊消息的内容需要带上 "发送者:\n" 前缀，和真实服务端保持一致
+func (s *Server) PushText(fromWxID, toWxID, content string) robot.Message {
+	return s.PushMessage(robot.Message{
+		FromUserName: builtinString(fromWxID),
+		ToUserName:   builtinString(toWxID),
+		Content:      builtinString(content),
+		MsgType:      model.MsgTypeText,
+	})
+}
+
+// AddContact 添加联系人，联系人列表和联系人详情接口会返回该联系人
+func (s *Server) AddContact(contact robot.Contact) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if contact.UserName.String == nil {
+		return
+	}
+	s.contacts[*contact.UserName.String] = contact
+}
+
+// SetChatRoomMembers 设置群成员，群成员详情接口会返回这些成员
+func (s *Server) SetChatRoomMembers(chatRoomID string, members []robot.ChatRoomMember) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members[chatRoomID] = members
+}
+
+// Calls 返回指定接口的调用记录，path 为空时返回全部
+func (s *Server) Calls(path string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var calls []Call
+	for _, call := range s.calls {
+		if path == "" || call.Path == path {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// WaitCalls 等待指定接口的调用次数达到 n，超时后返回当前的调用记录
+func (s *Server) WaitCalls(path string, n int, timeout time.Duration) []Call {
+	deadline := time.Now().Add(timeout)
+	for {
+		calls := s.Calls(path)
+		if len(calls) >= n || time.Now().After(deadline) {
+			return calls
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// Reset 清空入站消息和调用记录
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inbound = nil
+	s.calls = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	call := Call{
+		Path:  strings.TrimPrefix(r.URL.Path, "/api"),
+		Query: r.URL.Query(),
+		Body:  body,
+	}
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	handler := s.handlers[call.Path]
+	s.mu.Unlock()
+
+	var data any
+	var err error
+	if handler != nil {
+		data, err = handler(call)
+	}
+	result := robot.ClientResponse[any]{Success: true, Data: data}
+	if err != nil {
+		result = robot.ClientResponse[any]{Code: -1, Message: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) handleSync(call Call) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := s.inbound
+	s.inbound = nil
+	return robot.SyncMessage{AddMsgs: messages}, nil
+}
+
+func (s *Server) handleSendText(call Call) (any, error) {
+	var req robot.SendTextMessageRequest
+	if err := call.Decode(&req); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return robot.SendTextMessageResponse{
+		List: []robot.TextMessageResponse{{
+			ToUsetName:  builtinString(req.ToWxid),
+			MsgId:       s.nextMsgID(),
+			ClientMsgid: s.nextMsgID(),
+			Createtime:  time.Now().Unix(),
+			Servertime:  time.Now().Unix(),
+			Type:        req.Type,
+			NewMsgId:    s.nextMsgID(),
+		}},
+		Count: 1,
+	}, nil
+}
+
+func (s *Server) handleUploadImg(call Call) (any, error) {
+	var req robot.MsgUploadImgRequest
+	if err := call.Decode(&req); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return robot.MsgUploadImgResponse{
+		Msgid:        s.nextMsgID(),
+		FromUserName: builtinString(req.Wxid),
+		ToUserName:   builtinString(req.ToWxid),
+		TotalLen:     int64(len(req.Base64)),
+		DataLen:      int64(len(req.Base64)),
+		CreateTime:   time.Now().Unix(),
+		Newmsgid:     s.nextMsgID(),
+	}, nil
+}
+
+func (s *Server) handleSendApp(call Call) (any, error) {
+	var req robot.SendAppRequest
+	if err := call.Decode(&req); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return robot.SendAppResponse{
+		FromUserName: req.Wxid,
+		ToUserName:   req.ToWxid,
+		Type:         req.Type,
+		MsgId:        s.nextMsgID(),
+		ClientMsgId:  fmt.Sprint(s.nextMsgID()),
+		CreateTime:   time.Now().Unix(),
+		NewMsgId:     s.nextMsgID(),
+		Content:      req.Xml,
+	}, nil
+}
+
+func (s *Server) handleGetContactList(call Call) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var resp robot.GetContactListResponse
+	for wxid := range s.contacts {
+		resp.ContactUsernameList = append(resp.ContactUsernameList, wxid)
+	}
+	return resp, nil
+}
+
+func (s *Server) handleGetContactDetail(call Call) (any, error) {
+	var req robot.GetContactDetailRequest
+	if err := call.Decode(&req); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var resp robot.GetContactResponse
+	for _, wxid := range strings.Split(req.Towxids, ",") {
+		contact, ok := s.contacts[wxid]
+		if !ok {
+			continue
+		}
+		resp.ContactList = append(resp.ContactList, contact)
+	}
+	resp.ContactCount = len(resp.ContactList)
+	return resp, nil
+}
+
+func (s *Server) handleGetChatRoomMemberDetail(call Call) (any, error) {
+	var req robot.ChatRoomRequestBase
+	if err := call.Decode(&req); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	members := s.members[req.QID]
+	return robot.ChatRoomMemberDetail{
+		ChatroomUserName: req.QID,
+		NewChatroomData: robot.NewChatroomData{
+			ChatRoomMember: members,
+			MemberCount:    len(members),
+		},
+	}, nil
+}
+
+func builtinString(s string) robot.SKBuiltinStringT {
+	return robot.SKBuiltinStringT{String: &s}
+}
@@ -0,0 +1,104 @@
+package robottest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+
+	"wechat-robot-client/pkg/robot"
+)
+
+var errTest = errors.New("消息已过期")
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := server.NewClient()
+
+	pushed := server.PushText("wxid_friend", "wxid_robot", "你好")
+	syncResp, err := client.SyncMessage("wxid_robot")
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if len(syncResp.AddMsgs) != 1 || syncResp.AddMsgs[0].NewMsgId != pushed.NewMsgId || *syncResp.AddMsgs[0].Content.String != "你好" {
+		t.Fatalf("unexpected sync result: %+v", syncResp.AddMsgs)
+	}
+	if syncResp, _ = client.SyncMessage("wxid_robot"); len(syncResp.AddMsgs) != 0 {
+		t.Fatalf("inbound messages should be drained after sync, got %d", len(syncResp.AddMsgs))
+	}
+
+	sent, err := client.SendTextMessage(robot.SendTextMessageRequest{Wxid: "wxid_robot", ToWxid: "wxid_friend", Content: "在的", Type: 1})
+	if err != nil {
+		t.Fatalf("send text: %v", err)
+	}
+	if len(sent.List) != 1 || sent.List[0].NewMsgId == 0 {
+		t.Fatalf("unexpected send result: %+v", sent)
+	}
+	calls := server.Calls(robot.MsgSendTxt)
+	var req robot.SendTextMessageRequest
+	if len(calls) != 1 || calls[0].Decode(&req) != nil || req.Content != "在的" {
+		t.Fatalf("send text call should be recorded: %+v", calls)
+	}
+
+	nickname := "好友"
+	wxid := "wxid_friend"
+	server.AddContact(robot.Contact{UserName: robot.SKBuiltinStringT{String: &wxid}, NickName: robot.SKBuiltinStringT{String: &nickname}})
+	contacts, err := client.GetContactDetail("wxid_robot", "", []string{wxid, "wxid_unknown"})
+	if err != nil || len(contacts.ContactList) != 1 || *contacts.ContactList[0].NickName.String != nickname {
+		t.Fatalf("unexpected contact detail: %+v, %v", contacts, err)
+	}
+
+	server.SetChatRoomMembers("123@chatroom", []robot.ChatRoomMember{{UserName: wxid, NickName: nickname}})
+	members, err := client.GetChatRoomMemberDetail("wxid_robot", "123@chatroom")
+	if err != nil || len(members) != 1 || members[0].UserName != wxid {
+		t.Fatalf("unexpected chat room members: %+v, %v", members, err)
+	}
+
+	server.Handle(robot.MsgRevoke, func(call Call) (any, error) {
+		return nil, errTest
+	})
+	if err := client.MessageRevoke(robot.MessageRevokeRequest{}); err == nil || err.Error() != errTest.Error() {
+		t.Fatalf("custom handler error should be returned, got %v", err)
+	}
+}
+
+func TestOpenAIServer(t *testing.T) {
+	server := NewOpenAIServer("第一句。\n第二句。")
+	defer server.Close()
+	client := openai.NewClient(option.WithBaseURL(server.BaseURL()), option.WithAPIKey("test"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	params := openai.ChatCompletionNewParams{
+		Model:    "fake",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("你好")},
+	}
+
+	stream := client.Chat.Completions.NewStreaming(ctx, params)
+	acc := openai.ChatCompletionAccumulator{}
+	chunks := 0
+	for stream.Next() {
+		acc.AddChunk(stream.Current())
+		chunks++
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if chunks < 2 || acc.Choices[0].Message.Content != "第一句。\n第二句。" {
+		t.Fatalf("unexpected stream result: %d chunks, %q", chunks, acc.Choices[0].Message.Content)
+	}
+
+	completion, err := client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		t.Fatalf("completion: %v", err)
+	}
+	if completion.Choices[0].Message.Content != DefaultOpenAIReply {
+		t.Fatalf("unexpected default reply: %q", completion.Choices[0].Message.Content)
+	}
+	if len(server.Requests()) != 2 {
+		t.Fatalf("requests should be recorded, got %d", len(server.Requests()))
+	}
+}
@@ -0,0 +1,119 @@
+package robot
+
+import (
+	"io"
+	"mime/multipart"
+)
+
+// Transport 机器人与协议服务之间的通信接口，Client 是基于 iPad 协议 HTTP 服务的实现
+// 接入其他协议后端时实现该接口即可，service 层不需要改动
+type Transport interface {
+	LoginTransport
+	SyncTransport
+	MessageTransport
+	ContactTransport
+	ChatRoomTransport
+	MomentsTransport
+	// SetProxy 设置登录使用的代理
+	SetProxy(proxy ProxyInfo)
+	// SetPacing 设置发送限速
+	SetPacing(config PacingConfig)
+}
+
+// LoginTransport 登录、登出及账号信息
+type LoginTransport interface {
+	IsRunning() bool
+	GetProfile(wxid string) (resp GetProfileResponse, err error)
+	GetCachedInfo(wxid string) (resp LoginData, err error)
+	LoginTwiceAutoAuth(wxid string) (err error)
+	AwakenLogin(wxid string) (resp QrCode, err error)
+	GetQrCode(loginType, deviceId, deviceName string) (resp GetQRCode, err error)
+	LoginGetQRMac(deviceId, deviceName string) (resp GetQRCode, err error)
+	CheckLoginUuid(uuid string) (resp CheckUuid, err error)
+	LoginYPayVerificationcode(req VerificationCodeRequest) (err error)
+	LoginNewDeviceVerify(ticket string) (resp SilderOCR, err error)
+	LoginGet62Data(wxid string) (resp string, err error)
+	LoginGetA16Data(wxid string) (resp string, err error)
+	LoginData62SMSApply(req Data62LoginRequest) (resp UnifyAuthResponse, err error)
+	LoginData62SMSAgain(req LoginData62SMSAgainRequest) (resp string, err error)
+	LoginData62SMSVerify(req LoginData62SMSVerifyRequest) (resp string, err error)
+	LoginA16Data(req A16LoginRequest) (resp UnifyAuthResponse, err error)
+	Logout(wxid string) (err error)
+	WxappQrcodeAuthLogin(wxid, Url string) (err error)
+}
+
+// SyncTransport 心跳与消息同步
+type SyncTransport interface {
+	AutoHeartBeat(wxid string) (err error)
+	CloseAutoHeartBeat(wxid string) (err error)
+	Heartbeat(wxid string) (err error)
+	SyncMessage(wxid string) (messageResponse SyncMessage, err error)
+}
+
+// MessageTransport 消息发送、撤回及附件下载
+type MessageTransport interface {
+	MessageRevoke(req MessageRevokeRequest) (err error)
+	SendTextMessage(req SendTextMessageRequest) (newMessages SendTextMessageResponse, err error)
+	MsgSendGroupMassMsgText(req MsgSendGroupMassMsgTextRequest) (newMessages MsgSendGroupMassMsgTextResponse, err error)
+	MsgUploadImg(wxid, toWxid, base64 string) (imageMessage MsgUploadImgResponse, err error)
+	SendImageMessageStream(req SendImageMessageStreamRequest, file io.Reader, fileHeader *multipart.FileHeader) (imageMessage *MsgUploadImgResponse, err error)
+	MsgSendVideo(req MsgSendVideoRequest) (videoMessage MsgSendVideoResponse, err error)
+	MsgSendVideoThumbStream(req MsgSendVideoStreamRequest, file io.Reader, fileHeader *multipart.FileHeader) (videoMessage *MsgSendVideoResponse, err error)
+	MsgSendVideoStream(req MsgSendVideoStreamRequest, file io.Reader, fileHeader *multipart.FileHeader) (videoMessage *MsgSendVideoResponse, err error)
+	MsgSendVoice(req MsgSendVoiceRequest) (voiceMessage MsgSendVoiceResponse, err error)
+	ToolsSendFile(req SendFileMessageRequest, file io.Reader, fileHeader *multipart.FileHeader) (fileMessage *SendFileMessageResponse, err error)
+	GetAppMsgExt(req GetAppMsgExtRequest) (mp string, err error)
+	SendApp(req SendAppRequest) (appMessage SendAppResponse, err error)
+	SendEmoji(req SendEmojiRequest) (emojiMessage SendEmojiResponse, err error)
+	ShareLink(req ShareLinkRequest) (shareLinkMessage ShareLinkResponse, err error)
+	SendCDNFile(req SendCDNAttachmentRequest) (cdnFileMessage SendCDNFileResponse, err error)
+	SendCDNImg(req SendCDNAttachmentRequest) (cdnImageMessage SendCDNImgResponse, err error)
+	SendCDNVideo(req SendCDNAttachmentRequest) (cdnVideoMessage SendCDNVideoResponse, err error)
+	CdnDownloadImg(wxid, aeskey, cdnmidimgurl string) (imgbase64 string, err error)
+	DownloadVideo(req DownloadVideoRequest) (videobase64 string, err error)
+	DownloadVoice(req DownloadVoiceRequest) (voicebase64 string, err error)
+	DownloadFile(req DownloadFileRequest) (filebase64 string, err error)
+}
+
+// ContactTransport 联系人
+type ContactTransport interface {
+	FriendGetFriendstate(Wxid, UserName string) (resp MMBizJsApiGetUserOpenIdResponse, err error)
+	FriendSearch(req FriendSearchRequest) (resp SearchContactResponse, err error)
+	FriendSendRequest(req FriendSendRequestParam) (resp VerifyUserResponse, err error)
+	FriendSetRemarks(wxid, toWxid, remarks string) (resp OplogResponse, err error)
+	GetContactList(wxid string) (wxids []string, err error)
+	GetContactDetail(wxid, chatRoomID string, towxids []string) (resp GetContactResponse, err error)
+	FriendPassVerify(req FriendPassVerifyRequest) (verifyUserResponse VerifyUserResponse, err error)
+	FriendDelete(Wxid, ToWxid string) (oplogResponse OplogResponse, err error)
+}
+
+// ChatRoomTransport 群聊
+type ChatRoomTransport interface {
+	CreateChatRoom(wxid string, contactIDs []string) (createChatRoomResp CreateChatRoomResponse, err error)
+	GroupAddChatRoomMember(wxid, chatRoomName string, contactIDs []string) (memberResp InviteChatRoomMemberResponse, err error)
+	GroupInviteChatRoomMember(wxid, chatRoomName string, contactIDs []string) (memberResp InviteChatRoomMemberResponse, err error)
+	GroupConsentToJoin(wxid, Url string) (QID string, err error)
+	GetChatRoomMemberDetail(wxid, QID string) (chatRoomMember []ChatRoomMember, err error)
+	GroupSetChatRoomName(wxid, QID, Content string) (err error)
+	GroupSetChatRoomRemarks(wxid, QID, Content string) (err error)
+	GroupSetChatRoomAnnouncement(wxid, QID, Content string) (err error)
+	GroupDelChatRoomMember(wxid, QID string, ToWxids []string) (err error)
+	GroupQuit(wxid, QID string) (err error)
+}
+
+// MomentsTransport 朋友圈
+type MomentsTransport interface {
+	FriendCircleComment(req FriendCircleCommentRequest) (resp SnsCommentResponse, err error)
+	FriendCircleGetDetail(req FriendCircleGetDetailRequest) (resp SnsUserPageResponse, err error)
+	FriendCircleGetIdDetail(req FriendCircleGetIdDetailRequest) (resp SnsObjectDetailResponse, err error)
+	FriendCircleGetList(wxid, Fristpagemd5 string, Maxid string) (Moments GetListResponse, err error)
+	FriendCircleDownFriendCircleMedia(wxid, Url, Key string) (mediaBase64 string, err error)
+	FriendCircleUpload(wxid string, base64 string) (resp FriendCircleUploadResponse, err error)
+	FriendCircleCdnSnsUploadVideo(req FriendCircleCdnSnsUploadVideoRequest) (resp CdnSnsVideoUploadResponse, err error)
+	FriendCircleOperation(req FriendCircleOperationRequest) (resp SnsObjectOpResponse, err error)
+	FriendCirclePrivacySettings(req FriendCirclePrivacySettingsRequest) (resp OplogResponse, err error)
+	FriendCircleMessages(req FriendCircleMessagesRequest) (resp FriendCircleMessagesResponse, err error)
+	FriendCircleMmSnsSync(wxid, synckey string) (resp SyncMessage, err error)
+}
+
+var _ Transport = (*Client)(nil)
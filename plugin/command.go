@@ -0,0 +1,240 @@
+package plugin
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"wechat-robot-client/interface/plugin"
+)
+
+// HelpCommandName 自动生成的帮助指令
+const HelpCommandName = "帮助"
+
+// CommandRegistry 指令注册表，本身作为一个插件参与插件链
+type CommandRegistry struct {
+	commands []*plugin.Command
+	index    map[string]*plugin.Command
+	// RobotWxID 返回当前登录的机器人微信ID，用于判断机器人主人
+	RobotWxID func() string
+}
+
+var _ plugin.MessageHandler = (*CommandRegistry)(nil)
+
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{
+		index: make(map[string]*plugin.Command),
+	}
+}
+
+// Register 注册指令，名称或别名冲突的指令会被忽略
+func (r *CommandRegistry) Register(cmd *plugin.Command) {
+	for _, name := range cmd.Names() {
+		if _, ok := r.index[name]; ok || name == HelpCommandName {
+			log.Printf("指令[%s]注册失败: 名称[%s]已存在", cmd.Name, name)
+			return
+		}
+	}
+	for _, name := range cmd.Names() {
+		r.index[name] = cmd
+	}
+	r.commands = append(r.commands, cmd)
+}
+
+// Commands 获取全部已注册的指令，按注册顺序排列
+func (r *CommandRegistry) Commands() []*plugin.Command {
+	return r.commands
+}
+
+// Lookup 根据名称或别名查找指令
+func (r *CommandRegistry) Lookup(name string) *plugin.Command {
+	return r.index[name]
+}
+
+// ParseCommandLine 将 "#指令 参数" 拆分为指令名称和参数文本
+func ParseCommandLine(content string) (name, rest string, ok bool) {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, plugin.CommandPrefix) {
+		return "", "", false
+	}
+	content = strings.TrimPrefix(content, plugin.CommandPrefix)
+	idx := strings.IndexFunc(content, unicode.IsSpace)
+	if idx < 0 {
+		return content, "", content != ""
+	}
+	return content[:idx], strings.TrimSpace(content[idx:]), true
+}
+
+// ParseCommandArgs 按指令声明解析参数
+func ParseCommandArgs(cmd *plugin.Command, input string) (plugin.CommandArgs, error) {
+	args := plugin.CommandArgs{}
+	rest := strings.TrimSpace(input)
+	for _, arg := range cmd.Args {
+		if rest == "" {
+			if arg.Required {
+				return nil, fmt.Errorf("缺少参数 <%s>", arg.Name)
+			}
+			continue
+		}
+		if arg.Type == plugin.ArgText {
+			args[arg.Name] = rest
+			rest = ""
+			continue
+		}
+		value := rest
+		rest = ""
+		if idx := strings.IndexFunc(value, unicode.IsSpace); idx >= 0 {
+			value, rest = value[:idx], strings.TrimSpace(value[idx:])
+		}
+		switch arg.Type {
+		case plugin.ArgInt:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("参数 <%s> 必须是整数", arg.Name)
+			}
+			args[arg.Name] = n
+		default:
+			args[arg.Name] = value
+		}
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("多余的参数: %s", rest)
+	}
+	return args, nil
+}
+
+func (r *CommandRegistry) GetName() string {
+	return "Command"
+}
+
+func (r *CommandRegistry) GetLabels() []string {
+	return []string{"text", "chat"}
+}
+
+func (r *CommandRegistry) GetPriority() int {
+	return plugin.PriorityCommand
+}
+
+func (r *CommandRegistry) isAvailable(ctx *plugin.MessageContext, cmd *plugin.Command) bool {
+	if cmd.ChatRoomOnly && !ctx.Message.IsChatRoom {
+		return false
+	}
+	return ctx.Settings == nil || ctx.Settings.IsCommandEnabled(cmd.Name)
+}
+
+func (r *CommandRegistry) Match(ctx *plugin.MessageContext) bool {
+	name, _, ok := ParseCommandLine(ctx.MessageContent)
+	if !ok {
+		return false
+	}
+	if name == HelpCommandName {
+		return true
+	}
+	cmd := r.Lookup(name)
+	return cmd != nil && r.isAvailable(ctx, cmd)
+}
+
+func (r *CommandRegistry) PreAction(ctx *plugin.MessageContext) bool {
+	return true
+}
+
+func (r *CommandRegistry) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
+}
+
+func (r *CommandRegistry) Run(ctx *plugin.MessageContext) plugin.RunResult {
+	name, rest, _ := ParseCommandLine(ctx.MessageContent)
+	if name == HelpCommandName {
+		r.reply(ctx, r.helpText(ctx, rest))
+		return plugin.RunConsumed
+	}
+	cmd := r.Lookup(name)
+	if !r.hasRole(ctx, cmd.Role) {
+		r.reply(ctx, "您配使用这个指令吗？")
+		return plugin.RunConsumed
+	}
+	if cmd.RequireRefer && ctx.ReferMessage == nil {
+		r.reply(ctx, fmt.Sprintf("请引用一条消息后发送 %s", cmd.Usage()))
+		return plugin.RunConsumed
+	}
+	args, err := ParseCommandArgs(cmd, rest)
+	if err != nil {
+		r.reply(ctx, fmt.Sprintf("%s\n用法: %s", err.Error(), cmd.Usage()))
+		return plugin.RunConsumed
+	}
+	if err := cmd.Handler(ctx, args); err != nil {
+		r.reply(ctx, err.Error())
+	}
+	return plugin.RunConsumed
+}
+
+func (r *CommandRegistry) isRobotOwner(ctx *plugin.MessageContext) bool {
+	return r.RobotWxID != nil && ctx.Message.SenderWxID == r.RobotWxID()
+}
+
+func (r *CommandRegistry) hasRole(ctx *plugin.MessageContext, role plugin.CommandRole) bool {
+	switch role {
+	case plugin.RoleRobotOwner:
+		return r.isRobotOwner(ctx)
+	case plugin.RoleGroupAdmin:
+		if r.isRobotOwner(ctx) {
+			return true
+		}
+		// 私聊中没有群管理员，只有机器人主人可以执行
+		if !ctx.Message.IsChatRoom {
+			return false
+		}
+		chatRoomMember, err := ctx.GetSenderChatRoomMember()
+		if err != nil {
+			log.Printf("获取群成员信息失败: %v", err)
+			return false
+		}
+		return chatRoomMember != nil && chatRoomMember.IsAdmin != nil && *chatRoomMember.IsAdmin
+	default:
+		return true
+	}
+}
+
+func (r *CommandRegistry) helpText(ctx *plugin.MessageContext, name string) string {
+	name = strings.TrimPrefix(strings.TrimSpace(name), plugin.CommandPrefix)
+	if name != "" {
+		cmd := r.Lookup(name)
+		if cmd == nil || !r.isAvailable(ctx, cmd) {
+			return fmt.Sprintf("未找到指令[%s]", name)
+		}
+		var builder strings.Builder
+		fmt.Fprintf(&builder, "用法: %s\n说明: %s\n权限: %s", cmd.Usage(), cmd.Description, cmd.Role)
+		if len(cmd.Aliases) > 0 {
+			fmt.Fprintf(&builder, "\n别名: %s", strings.Join(cmd.Aliases, "、"))
+		}
+		if cmd.RequireRefer {
+			builder.WriteString("\n需要引用一条消息")
+		}
+		for _, arg := range cmd.Args {
+			fmt.Fprintf(&builder, "\n- %s: %s", arg.Name, arg.Description)
+		}
+		return builder.String()
+	}
+	var builder strings.Builder
+	builder.WriteString("可用指令:")
+	for _, cmd := range r.commands {
+		if !r.isAvailable(ctx, cmd) {
+			continue
+		}
+		fmt.Fprintf(&builder, "\n%s - %s", cmd.Usage(), cmd.Description)
+		if cmd.Role != plugin.RoleAnyone {
+			fmt.Fprintf(&builder, "（%s）", cmd.Role)
+		}
+	}
+	fmt.Fprintf(&builder, "\n\n发送 %s%s <指令名称> 查看详细用法", plugin.CommandPrefix, HelpCommandName)
+	return builder.String()
+}
+
+func (r *CommandRegistry) reply(ctx *plugin.MessageContext, content string) {
+	if ctx.Message.IsChatRoom {
+		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, content, ctx.Message.SenderWxID)
+		return
+	}
+	ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, content)
+}
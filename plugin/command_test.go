@@ -0,0 +1,83 @@
+package plugin
+
+import (
+	"testing"
+
+	"wechat-robot-client/interface/plugin"
+	"wechat-robot-client/model"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	cases := []struct {
+		content string
+		name    string
+		rest    string
+		ok      bool
+	}{
+		{content: "#帮助", name: "帮助", ok: true},
+		{content: "  #切换聊天模型   gpt-4o  ", name: "切换聊天模型", rest: "gpt-4o", ok: true},
+		{content: "#录入知识库 产品 手册", name: "录入知识库", rest: "产品 手册", ok: true},
+		{content: "#", ok: false},
+		{content: "你好 #帮助", ok: false},
+	}
+	for _, c := range cases {
+		name, rest, ok := ParseCommandLine(c.content)
+		if name != c.name || rest != c.rest || ok != c.ok {
+			t.Errorf("ParseCommandLine(%q) = (%q, %q, %t), want (%q, %q, %t)", c.content, name, rest, ok, c.name, c.rest, c.ok)
+		}
+	}
+}
+
+func TestParseCommandArgs(t *testing.T) {
+	cmd := &plugin.Command{
+		Name: "测试",
+		Args: []plugin.CommandArg{
+			{Name: "名称", Type: plugin.ArgString, Required: true},
+			{Name: "次数", Type: plugin.ArgInt},
+			{Name: "备注", Type: plugin.ArgText},
+		},
+	}
+	if usage := cmd.Usage(); usage != "#测试 <名称> [次数] [备注]" {
+		t.Errorf("unexpected usage: %s", usage)
+	}
+
+	args, err := ParseCommandArgs(cmd, "abc 3 多个 单词")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args.String("名称") != "abc" || args.Int("次数") != 3 || args.String("备注") != "多个 单词" {
+		t.Errorf("unexpected args: %v", args)
+	}
+
+	args, err = ParseCommandArgs(cmd, "abc")
+	if err != nil || args.Has("次数") {
+		t.Errorf("optional args should be absent, got %v, %v", args, err)
+	}
+
+	if _, err := ParseCommandArgs(cmd, ""); err == nil {
+		t.Error("expected error for missing required arg")
+	}
+	if _, err := ParseCommandArgs(cmd, "abc x"); err == nil {
+		t.Error("expected error for invalid int arg")
+	}
+
+	noArgs := &plugin.Command{Name: "无参数"}
+	if _, err := ParseCommandArgs(noArgs, "多余"); err == nil {
+		t.Error("expected error for extra args")
+	}
+}
+
+func TestCommandRoleInPrivateChat(t *testing.T) {
+	r := &CommandRegistry{RobotWxID: func() string { return "wxid_robot" }}
+	ctx := &plugin.MessageContext{Message: &model.Message{FromWxID: "wxid_friend", SenderWxID: "wxid_friend"}}
+	if r.hasRole(ctx, plugin.RoleGroupAdmin) {
+		t.Error("friends should not run group admin commands in private chat")
+	}
+	if !r.hasRole(ctx, plugin.RoleAnyone) {
+		t.Error("anyone commands should be allowed")
+	}
+	ctx.Message.SenderWxID = "wxid_robot"
+	if !r.hasRole(ctx, plugin.RoleGroupAdmin) {
+		t.Error("robot owner should run group admin commands in private chat")
+	}
+}
@@ -1,15 +1,67 @@
 package plugin
 
-import "wechat-robot-client/interface/plugin"
+import (
+	"slices"
+	"sort"
+
+	"wechat-robot-client/interface/plugin"
+)
 
 type MessagePlugin struct {
-	Plugins []plugin.MessageHandler
+	Plugins      []plugin.MessageHandler
+	Interceptors []plugin.Interceptor
+	Commands     *CommandRegistry
 }
 
 func NewMessagePlugin() *MessagePlugin {
-	return &MessagePlugin{}
+	mp := &MessagePlugin{
+		Commands: NewCommandRegistry(),
+	}
+	mp.Register(mp.Commands)
+	return mp
 }
 
+// Register 注册插件，插件按优先级从高到低排列，同优先级保持注册顺序
 func (mp *MessagePlugin) Register(handler plugin.MessageHandler) {
 	mp.Plugins = append(mp.Plugins, handler)
+	sort.SliceStable(mp.Plugins, func(i, j int) bool {
+		return mp.Plugins[i].GetPriority() > mp.Plugins[j].GetPriority()
+	})
+}
+
+// Use 注册全局拦截器，先注册的拦截器位于外层
+func (mp *MessagePlugin) Use(interceptors ...plugin.Interceptor) {
+	mp.Interceptors = append(mp.Interceptors, interceptors...)
+}
+
+// Execute 执行单个插件：全局拦截器 -> PreAction -> Run -> PostAction
+// PreAction 返回 false 时跳过该插件，返回 RunContinue
+func (mp *MessagePlugin) Execute(handler plugin.MessageHandler, ctx *plugin.MessageContext) plugin.RunResult {
+	next := func() plugin.RunResult {
+		if !handler.PreAction(ctx) {
+			return plugin.RunContinue
+		}
+		result := handler.Run(ctx)
+		handler.PostAction(ctx, result)
+		return result
+	}
+	for i := len(mp.Interceptors) - 1; i >= 0; i-- {
+		interceptor := mp.Interceptors[i]
+		inner := next
+		next = func() plugin.RunResult {
+			return interceptor.Intercept(handler, ctx, inner)
+		}
+	}
+	return next()
+}
+
+// GetPluginsByLabel 获取带有指定标签的插件，返回结果按优先级排列
+func (mp *MessagePlugin) GetPluginsByLabel(label string) []plugin.MessageHandler {
+	handlers := make([]plugin.MessageHandler, 0, len(mp.Plugins))
+	for _, handler := range mp.Plugins {
+		if slices.Contains(handler.GetLabels(), label) {
+			handlers = append(handlers, handler)
+		}
+	}
+	return handlers
 }
@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"testing"
+
+	"wechat-robot-client/interface/plugin"
+)
+
+type testHandler struct {
+	name     string
+	labels   []string
+	priority int
+}
+
+func (h *testHandler) GetName() string                                                { return h.name }
+func (h *testHandler) GetLabels() []string                                            { return h.labels }
+func (h *testHandler) GetPriority() int                                               { return h.priority }
+func (h *testHandler) Match(ctx *plugin.MessageContext) bool                          { return true }
+func (h *testHandler) PreAction(ctx *plugin.MessageContext) bool                      { return true }
+func (h *testHandler) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {}
+func (h *testHandler) Run(ctx *plugin.MessageContext) plugin.RunResult                { return plugin.RunContinue }
+
+func TestGetPluginsByLabelOrder(t *testing.T) {
+	mp := NewMessagePlugin()
+	mp.Register(&testHandler{name: "ai", labels: []string{"test", "chat"}, priority: plugin.PriorityFallback})
+	mp.Register(&testHandler{name: "cmd1", labels: []string{"test"}, priority: plugin.PriorityCommand})
+	mp.Register(&testHandler{name: "parser", labels: []string{"test"}, priority: plugin.PriorityParser})
+	mp.Register(&testHandler{name: "cmd2", labels: []string{"test"}, priority: plugin.PriorityCommand})
+	mp.Register(&testHandler{name: "pat", labels: []string{"pat"}, priority: plugin.PriorityDefault})
+
+	got := mp.GetPluginsByLabel("test")
+	want := []string{"cmd1", "cmd2", "parser", "ai"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d plugins, got %d", len(want), len(got))
+	}
+	for i, h := range got {
+		if h.GetName() != want[i] {
+			t.Errorf("index %d: expected %s, got %s", i, want[i], h.GetName())
+		}
+	}
+}
+
+type lifecycleHandler struct {
+	testHandler
+	allow      bool
+	calls      []string
+	postResult plugin.RunResult
+}
+
+func (h *lifecycleHandler) PreAction(ctx *plugin.MessageContext) bool {
+	h.calls = append(h.calls, "pre")
+	return h.allow
+}
+
+func (h *lifecycleHandler) Run(ctx *plugin.MessageContext) plugin.RunResult {
+	h.calls = append(h.calls, "run")
+	return plugin.RunConsumed
+}
+
+func (h *lifecycleHandler) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
+	h.calls = append(h.calls, "post")
+	h.postResult = result
+}
+
+type recordInterceptor struct {
+	name  string
+	calls *[]string
+	stop  bool
+}
+
+func (i *recordInterceptor) GetName() string { return i.name }
+
+func (i *recordInterceptor) Intercept(handler plugin.MessageHandler, ctx *plugin.MessageContext, next func() plugin.RunResult) plugin.RunResult {
+	*i.calls = append(*i.calls, i.name)
+	if i.stop {
+		return plugin.RunStop
+	}
+	return next()
+}
+
+func TestExecuteLifecycle(t *testing.T) {
+	var order []string
+	mp := NewMessagePlugin()
+	mp.Use(&recordInterceptor{name: "outer", calls: &order}, &recordInterceptor{name: "inner", calls: &order})
+
+	h := &lifecycleHandler{allow: true}
+	if result := mp.Execute(h, &plugin.MessageContext{}); result != plugin.RunConsumed {
+		t.Fatalf("expected consumed, got %s", result)
+	}
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("unexpected interceptor order: %v", order)
+	}
+	if len(h.calls) != 3 || h.postResult != plugin.RunConsumed {
+		t.Errorf("unexpected lifecycle: %v, post result %s", h.calls, h.postResult)
+	}
+
+	h = &lifecycleHandler{allow: false}
+	if result := mp.Execute(h, &plugin.MessageContext{}); result != plugin.RunContinue {
+		t.Fatalf("expected continue when PreAction rejects, got %s", result)
+	}
+	if len(h.calls) != 1 {
+		t.Errorf("Run/PostAction should be skipped, got %v", h.calls)
+	}
+
+	mp.Use(&recordInterceptor{name: "stop", calls: &order, stop: true})
+	h = &lifecycleHandler{allow: true}
+	if result := mp.Execute(h, &plugin.MessageContext{}); result != plugin.RunStop {
+		t.Fatalf("expected stop from interceptor, got %s", result)
+	}
+	if len(h.calls) != 0 {
+		t.Errorf("handler should not run, got %v", h.calls)
+	}
+}
@@ -21,6 +21,10 @@ func (p *AIAttachUploadPlugin) GetLabels() []string {
 	return []string{"text", "internal", "chat"}
 }
 
+func (p *AIAttachUploadPlugin) GetPriority() int {
+	return plugin.PriorityDefault
+}
+
 func (p *AIAttachUploadPlugin) Match(ctx *plugin.MessageContext) bool {
 	return ctx.ReferMessage != nil
 }
@@ -29,7 +33,7 @@ func (p *AIAttachUploadPlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *AIAttachUploadPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *AIAttachUploadPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
@@ -61,17 +65,18 @@ func (p *AIAttachUploadPlugin) SendMessage(ctx *plugin.MessageContext, aiReplyTe
 	}
 }
 
-func (p *AIAttachUploadPlugin) Run(ctx *plugin.MessageContext) {
+func (p *AIAttachUploadPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	if ctx.ReferMessage.AttachmentUrl == "" {
 		fileURL, err := p.GetOSSFileURL(ctx)
 		if err != nil {
 			log.Printf("文件上传失败: %v", err)
 			p.SendMessage(ctx, fmt.Sprintf("文件上传失败: %v，你可能没开启自动上传文件，请前往机器人详情 -> OSS 设置手动开启", err))
-			return
+			return plugin.RunStop
 		}
 		if fileURL == "" {
 			p.SendMessage(ctx, "文件上传失败: 文件URL为空，你可能没开启自动上传文件，请前往机器人详情 -> OSS 设置手动开启")
-			return
+			return plugin.RunStop
 		}
 	}
+	return plugin.RunContinue
 }
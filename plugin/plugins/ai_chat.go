@@ -12,6 +12,8 @@ import (
 
 	"github.com/openai/openai-go/v3"
 
+	"wechat-robot-client/dto"
+	"wechat-robot-client/interface/ai"
 	"wechat-robot-client/interface/plugin"
 	"wechat-robot-client/model"
 	"wechat-robot-client/pkg/robotctx"
@@ -45,30 +47,6 @@ type CallToolResult struct {
 	AttachmentURLList []string   `json:"attachment_url_list,omitempty" jsonschema:"附件消息的URL"`
 }
 
-type structuredReplyType string
-
-const (
-	structuredReplyTypeText   structuredReplyType = "text"
-	structuredReplyTypeImage  structuredReplyType = "image"
-	structuredReplyTypeVideo  structuredReplyType = "video"
-	structuredReplyTypeVoice  structuredReplyType = "voice"
-	structuredReplyTypeFile   structuredReplyType = "file"
-	structuredReplyTypeAppMsg structuredReplyType = "appmsg"
-)
-
-type structuredReplyBlock struct {
-	Start   int
-	End     int
-	Type    structuredReplyType
-	Content string
-	AppType int
-}
-
-type structuredReplyPattern struct {
-	Type    structuredReplyType
-	Pattern *regexp.Regexp
-}
-
 var thinkTagRegexp = regexp.MustCompile(`(?s)<think>.*?</think>|<thinking>.*?</thinking>`)
 
 type AIChatPlugin struct{}
@@ -77,6 +55,16 @@ func NewAIChatPlugin() plugin.MessageHandler {
 	return &AIChatPlugin{}
 }
 
+// runPlugin 执行嵌套调用的子插件：PreAction -> Run -> PostAction
+func runPlugin(handler plugin.MessageHandler, ctx *plugin.MessageContext) plugin.RunResult {
+	if !handler.PreAction(ctx) {
+		return plugin.RunContinue
+	}
+	result := handler.Run(ctx)
+	handler.PostAction(ctx, result)
+	return result
+}
+
 func (p *AIChatPlugin) GetName() string {
 	return "AIChat"
 }
@@ -85,6 +73,10 @@ func (p *AIChatPlugin) GetLabels() []string {
 	return []string{"text", "internal", "chat"}
 }
 
+func (p *AIChatPlugin) GetPriority() int {
+	return plugin.PriorityFallback
+}
+
 func (p *AIChatPlugin) Match(ctx *plugin.MessageContext) bool {
 	return true
 }
@@ -97,7 +89,9 @@ func (p *AIChatPlugin) PreAction(ctx *plugin.MessageContext) bool {
 			if !match {
 				return false
 			}
-			imageUpload.Run(ctx)
+			if runPlugin(imageUpload, ctx) != plugin.RunContinue {
+				return false
+			}
 			err := ctx.MessageService.SetMessageIsInContext(ctx.ReferMessage)
 			if err != nil {
 				log.Printf("更新消息上下文失败: %v", err)
@@ -110,7 +104,9 @@ func (p *AIChatPlugin) PreAction(ctx *plugin.MessageContext) bool {
 			if !match {
 				return false
 			}
-			emojiUpload.Run(ctx)
+			if runPlugin(emojiUpload, ctx) != plugin.RunContinue {
+				return false
+			}
 			err := ctx.MessageService.SetMessageIsInContext(ctx.ReferMessage)
 			if err != nil {
 				log.Printf("更新消息上下文失败: %v", err)
@@ -123,7 +119,9 @@ func (p *AIChatPlugin) PreAction(ctx *plugin.MessageContext) bool {
 			if !match {
 				return false
 			}
-			voiceUpload.Run(ctx)
+			if runPlugin(voiceUpload, ctx) != plugin.RunContinue {
+				return false
+			}
 			err := ctx.MessageService.SetMessageIsInContext(ctx.ReferMessage)
 			if err != nil {
 				log.Printf("更新消息上下文失败: %v", err)
@@ -136,7 +134,9 @@ func (p *AIChatPlugin) PreAction(ctx *plugin.MessageContext) bool {
 			if !match {
 				return false
 			}
-			videoUpload.Run(ctx)
+			if runPlugin(videoUpload, ctx) != plugin.RunContinue {
+				return false
+			}
 			err := ctx.MessageService.SetMessageIsInContext(ctx.ReferMessage)
 			if err != nil {
 				log.Printf("更新消息上下文失败: %v", err)
@@ -149,7 +149,9 @@ func (p *AIChatPlugin) PreAction(ctx *plugin.MessageContext) bool {
 			if !match {
 				return false
 			}
-			attachUpload.Run(ctx)
+			if runPlugin(attachUpload, ctx) != plugin.RunContinue {
+				return false
+			}
 			err := ctx.MessageService.SetMessageIsInContext(ctx.ReferMessage)
 			if err != nil {
 				log.Printf("更新消息上下文失败: %v", err)
@@ -160,7 +162,7 @@ func (p *AIChatPlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *AIChatPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *AIChatPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
@@ -169,12 +171,45 @@ func (p *AIChatPlugin) SendMessage(ctx *plugin.MessageContext, aiReplyText strin
 		return
 	}
 	if ctx.Message.IsChatRoom {
+		if ctx.Settings.GetAIReplyMode() == model.AIReplyModeQuote {
+			err := ctx.MessageService.ReplyToMessage(ctx.Message, aiReplyText)
+			if err == nil {
+				return
+			}
+			log.Printf("引用回复失败，改为@回复: %v", err)
+		}
 		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, aiReplyText, ctx.Message.SenderWxID)
 	} else {
 		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, aiReplyText)
 	}
 }
 
+// sendStructuredReply 按顺序发送结构化回复，发送失败的部分汇总后告知用户
+func (p *AIChatPlugin) sendStructuredReply(ctx *plugin.MessageContext, reply dto.StructuredReply) {
+	voiceTimbre := ctx.Settings.GetPatConfig().PatVoiceTimbre
+	results := ctx.MessageService.SendReplyParts(ctx.Message.FromWxID, reply.Parts, func(text string) ([]byte, string, error) {
+		return synthesizeSpeech(ctx, text, voiceTimbre)
+	})
+	var failures []string
+	var textSent bool
+	for _, result := range results {
+		if !result.Success {
+			failures = append(failures, fmt.Sprintf("第 %d 条消息(%s)发送失败: %s", result.Index+1, result.Type, result.Error))
+			continue
+		}
+		if result.Type == dto.ReplyPartTypeText {
+			textSent = true
+		}
+	}
+	if len(failures) > 0 {
+		p.SendMessage(ctx, strings.Join(failures, "\n"))
+	}
+	// 没有发送文本时，补一条助手消息结束本轮 AI 上下文
+	if !textSent {
+		_ = ctx.MessageService.ToolsCompleted(ctx.Message.FromWxID, ctx.Message.SenderWxID)
+	}
+}
+
 func (p *AIChatPlugin) setChatMessageTextContent(message *openai.ChatCompletionMessageParamUnion, text string) {
 	switch {
 	case message.OfDeveloper != nil:
@@ -282,16 +317,12 @@ func (p *AIChatPlugin) chatMessageText(message openai.ChatCompletionMessageParam
 	}
 }
 
-func (p *AIChatPlugin) Run(ctx *plugin.MessageContext) {
-	if !p.PreAction(ctx) {
-		return
-	}
-
+func (p *AIChatPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	aiTriggerWord := ctx.Settings.GetAITriggerWord()
 	aiMessages, err := ctx.MessageService.GetAIMessageContext(ctx.Message)
 	if err != nil {
 		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, err.Error())
-		return
+		return plugin.RunConsumed
 	}
 	if ctx.Message.IsChatRoom {
 		for index := range aiMessages {
@@ -303,7 +334,14 @@ func (p *AIChatPlugin) Run(ctx *plugin.MessageContext) {
 	if ctx.ReferMessage != nil {
 		refMessageID = ctx.ReferMessage.ID
 	}
-	aiReply, err := aiChatService.Chat(robotctx.RobotContext{
+	// 开启流式分段回复时，边生成边发送
+	var streamReplier *service.AIStreamReplier
+	var streamHandler ai.ChatStreamHandler
+	if ctx.Settings.IsAIStreamReplyEnabled() {
+		streamReplier = service.NewAIStreamReplier(ctx.Context, ctx.Message, ctx.Settings.GetAIReplyMode())
+		streamHandler = streamReplier
+	}
+	aiReply, err := aiChatService.ChatStream(robotctx.RobotContext{
 		WeChatClientPort: vars.WechatClientPort,
 		RobotID:          vars.RobotRuntime.RobotID,
 		RobotCode:        vars.RobotRuntime.RobotCode,
@@ -317,10 +355,24 @@ func (p *AIChatPlugin) Run(ctx *plugin.MessageContext) {
 		SenderWxID:       ctx.Message.SenderWxID,
 		MessageID:        ctx.Message.ID,
 		RefMessageID:     refMessageID,
-	}, aiMessages)
+	}, aiMessages, streamHandler)
 	if err != nil {
+		if streamReplier != nil {
+			streamReplier.Close()
+		}
 		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, err.Error())
-		return
+		return plugin.RunConsumed
+	}
+	// reply 工具给出的结构化回复
+	if aiReply.Reply != nil {
+		if streamReplier != nil {
+			streamReplier.Close()
+		}
+		p.sendStructuredReply(ctx, *aiReply.Reply)
+		return plugin.RunConsumed
+	}
+	if streamReplier != nil && streamReplier.Finish(aiReply.Content) {
+		return plugin.RunConsumed
 	}
 	var aiReplyText string
 	if aiReply.Content != "" {
@@ -353,11 +405,11 @@ func (p *AIChatPlugin) Run(ctx *plugin.MessageContext) {
 
 	if aiReplyText == vars.AIEnded {
 		_ = ctx.MessageService.ToolsCompleted(ctx.Message.FromWxID, ctx.Message.SenderWxID)
-		return
+		return plugin.RunConsumed
 	}
 
-	// 检测是否是 MCP 工具调用结果
 	if strings.HasPrefix(strings.TrimSpace(aiReplyText), "{") {
+		// 检测是否是 MCP 工具调用结果
 		var callToolResult CallToolResult
 		err = json.Unmarshal([]byte(aiReplyText), &callToolResult)
 		if err == nil && callToolResult.IsCallToolResult {
@@ -425,19 +477,19 @@ func (p *AIChatPlugin) Run(ctx *plugin.MessageContext) {
 						}
 						_ = ctx.MessageService.ToolsCompleted(ctx.Message.FromWxID, ctx.Message.SenderWxID)
 					}
-					return
+					return plugin.RunConsumed
 				}
 				ossSettingService := service.NewOSSSettingService(ctx.Context)
 				ossSettings, err := ossSettingService.GetOSSSettingService()
 				if err != nil {
 					p.SendMessage(ctx, "获取 OSS 配置失败，请联系管理员")
-					return
+					return plugin.RunConsumed
 				}
 				if ossSettings.AutoUploadImage != nil && *ossSettings.AutoUploadImage {
 					err := ossSettingService.UploadImageToOSSFromEncryptUrl(ossSettings, ctx.ReferMessage, imageURL)
 					if err != nil {
 						p.SendMessage(ctx, "上传图片到 OSS 失败，请联系管理员")
-						return
+						return plugin.RunConsumed
 					}
 					if strings.HasSuffix(ctx.ReferMessage.AttachmentUrl, "gif") {
 						p.SendMessage(ctx, fmt.Sprintf("表情下载地址: %s", ctx.ReferMessage.AttachmentUrl))
@@ -455,9 +507,10 @@ func (p *AIChatPlugin) Run(ctx *plugin.MessageContext) {
 			default:
 				p.SendMessage(ctx, "暂不支持的操作类型。")
 			}
-			return
+			return plugin.RunConsumed
 		}
 	}
 
 	p.SendMessage(ctx, aiReplyText)
+	return plugin.RunConsumed
 }
@@ -21,6 +21,10 @@ func (p *AIEmojiUploadPlugin) GetLabels() []string {
 	return []string{"text", "internal", "chat"}
 }
 
+func (p *AIEmojiUploadPlugin) GetPriority() int {
+	return plugin.PriorityDefault
+}
+
 func (p *AIEmojiUploadPlugin) Match(ctx *plugin.MessageContext) bool {
 	return ctx.ReferMessage != nil
 }
@@ -29,7 +33,7 @@ func (p *AIEmojiUploadPlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *AIEmojiUploadPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *AIEmojiUploadPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
@@ -61,17 +65,18 @@ func (p *AIEmojiUploadPlugin) SendMessage(ctx *plugin.MessageContext, aiReplyTex
 	}
 }
 
-func (p *AIEmojiUploadPlugin) Run(ctx *plugin.MessageContext) {
+func (p *AIEmojiUploadPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	if ctx.ReferMessage.AttachmentUrl == "" {
 		emojiURL, err := p.GetOSSFileURL(ctx)
 		if err != nil {
 			log.Printf("表情包上传失败: %v", err)
 			p.SendMessage(ctx, fmt.Sprintf("表情包上传失败: %v，你可能没开启自动上传图片，请前往机器人详情 -> OSS 设置手动开启", err))
-			return
+			return plugin.RunStop
 		}
 		if emojiURL == "" {
 			p.SendMessage(ctx, "表情包上传失败: 表情包URL为空，你可能没开启自动上传图片，请前往机器人详情 -> OSS 设置手动开启")
-			return
+			return plugin.RunStop
 		}
 	}
+	return plugin.RunContinue
 }
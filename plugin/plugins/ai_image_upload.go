@@ -21,6 +21,10 @@ func (p *AIImageUploadPlugin) GetLabels() []string {
 	return []string{"text", "internal", "chat"}
 }
 
+func (p *AIImageUploadPlugin) GetPriority() int {
+	return plugin.PriorityDefault
+}
+
 func (p *AIImageUploadPlugin) Match(ctx *plugin.MessageContext) bool {
 	return ctx.ReferMessage != nil
 }
@@ -29,7 +33,7 @@ func (p *AIImageUploadPlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *AIImageUploadPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *AIImageUploadPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
@@ -61,17 +65,18 @@ func (p *AIImageUploadPlugin) SendMessage(ctx *plugin.MessageContext, aiReplyTex
 	}
 }
 
-func (p *AIImageUploadPlugin) Run(ctx *plugin.MessageContext) {
+func (p *AIImageUploadPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	if ctx.ReferMessage.AttachmentUrl == "" {
 		imageURL, err := p.GetOSSFileURL(ctx)
 		if err != nil {
 			log.Printf("图片上传失败: %v", err)
 			p.SendMessage(ctx, fmt.Sprintf("图片上传失败: %v，你可能没开启自动上传图片，请前往机器人详情 -> OSS 设置手动开启", err))
-			return
+			return plugin.RunStop
 		}
 		if imageURL == "" {
 			p.SendMessage(ctx, "图片上传失败: 图片URL为空，你可能没开启自动上传图片，请前往机器人详情 -> OSS 设置手动开启")
-			return
+			return plugin.RunStop
 		}
 	}
+	return plugin.RunContinue
 }
@@ -21,6 +21,10 @@ func (p *AIVideoUploadPlugin) GetLabels() []string {
 	return []string{"text", "internal", "chat"}
 }
 
+func (p *AIVideoUploadPlugin) GetPriority() int {
+	return plugin.PriorityDefault
+}
+
 func (p *AIVideoUploadPlugin) Match(ctx *plugin.MessageContext) bool {
 	return ctx.ReferMessage != nil
 }
@@ -29,7 +33,7 @@ func (p *AIVideoUploadPlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *AIVideoUploadPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *AIVideoUploadPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
@@ -61,17 +65,18 @@ func (p *AIVideoUploadPlugin) SendMessage(ctx *plugin.MessageContext, aiReplyTex
 	}
 }
 
-func (p *AIVideoUploadPlugin) Run(ctx *plugin.MessageContext) {
+func (p *AIVideoUploadPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	if ctx.ReferMessage.AttachmentUrl == "" {
 		videoURL, err := p.GetOSSFileURL(ctx)
 		if err != nil {
 			log.Printf("视频上传失败: %v", err)
 			p.SendMessage(ctx, fmt.Sprintf("视频上传失败: %v，你可能没开启自动上传视频，请前往机器人详情 -> OSS 设置手动开启", err))
-			return
+			return plugin.RunStop
 		}
 		if videoURL == "" {
 			p.SendMessage(ctx, "视频上传失败: 视频URL为空，你可能没开启自动上传视频，请前往机器人详情 -> OSS 设置手动开启")
-			return
+			return plugin.RunStop
 		}
 	}
+	return plugin.RunContinue
 }
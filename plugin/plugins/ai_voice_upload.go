@@ -21,6 +21,10 @@ func (p *AIVoiceUploadPlugin) GetLabels() []string {
 	return []string{"text", "internal", "chat"}
 }
 
+func (p *AIVoiceUploadPlugin) GetPriority() int {
+	return plugin.PriorityDefault
+}
+
 func (p *AIVoiceUploadPlugin) Match(ctx *plugin.MessageContext) bool {
 	return ctx.ReferMessage != nil
 }
@@ -29,7 +33,7 @@ func (p *AIVoiceUploadPlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *AIVoiceUploadPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *AIVoiceUploadPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
@@ -61,17 +65,18 @@ func (p *AIVoiceUploadPlugin) SendMessage(ctx *plugin.MessageContext, aiReplyTex
 	}
 }
 
-func (p *AIVoiceUploadPlugin) Run(ctx *plugin.MessageContext) {
+func (p *AIVoiceUploadPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	if ctx.ReferMessage.AttachmentUrl == "" {
 		voiceURL, err := p.GetOSSFileURL(ctx)
 		if err != nil {
 			log.Printf("语音上传失败: %v", err)
 			p.SendMessage(ctx, fmt.Sprintf("语音上传失败: %v，你可能没开启自动上传语音，请前往机器人详情 -> OSS 设置手动开启", err))
-			return
+			return plugin.RunStop
 		}
 		if voiceURL == "" {
 			p.SendMessage(ctx, "语音上传失败: 语音URL为空，你可能没开启自动上传语音，请前往机器人详情 -> OSS 设置手动开启")
-			return
+			return plugin.RunStop
 		}
 	}
+	return plugin.RunContinue
 }
@@ -23,6 +23,10 @@ func (p *AutoJoinGroupPlugin) GetLabels() []string {
 	return []string{"text", "auto"}
 }
 
+func (p *AutoJoinGroupPlugin) GetPriority() int {
+	return plugin.PriorityCommand
+}
+
 func (p *AutoJoinGroupPlugin) Match(ctx *plugin.MessageContext) bool {
 	return re.MatchString(ctx.MessageContent)
 }
@@ -31,18 +35,19 @@ func (p *AutoJoinGroupPlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *AutoJoinGroupPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *AutoJoinGroupPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
-func (p *AutoJoinGroupPlugin) Run(ctx *plugin.MessageContext) {
+func (p *AutoJoinGroupPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	chatRoomName := re.ReplaceAllString(ctx.MessageContent, "")
 	if chatRoomName == "" {
 		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, "群聊名称不能为空")
-		return
+		return plugin.RunConsumed
 	}
 	err := service.NewChatRoomService(context.Background()).AutoInviteChatRoomMember(chatRoomName, []string{ctx.Message.FromWxID})
 	if err != nil {
 		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, err.Error())
 	}
+	return plugin.RunConsumed
 }
@@ -58,10 +58,6 @@ func (p *BilibiliVideoParsePlugin) GetLabels() []string {
 
 func (p *BilibiliVideoParsePlugin) PreAction(ctx *plugin.MessageContext) bool {
 	if ctx.Message.IsChatRoom {
-		next := NewChatRoomCommonPlugin().PreAction(ctx)
-		if !next {
-			return false
-		}
 		if !ctx.Settings.IsShortVideoParsingEnabled() {
 			return false
 		}
@@ -69,7 +65,11 @@ func (p *BilibiliVideoParsePlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *BilibiliVideoParsePlugin) PostAction(ctx *plugin.MessageContext) {}
+func (p *BilibiliVideoParsePlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {}
+
+func (p *BilibiliVideoParsePlugin) GetPriority() int {
+	return plugin.PriorityParser
+}
 
 func (p *BilibiliVideoParsePlugin) Match(ctx *plugin.MessageContext) bool {
 	if ctx.ReferMessage != nil {
@@ -78,27 +78,23 @@ func (p *BilibiliVideoParsePlugin) Match(ctx *plugin.MessageContext) bool {
 	return strings.Contains(ctx.Message.Content, "https://www.bilibili.com/video")
 }
 
-func (p *BilibiliVideoParsePlugin) Run(ctx *plugin.MessageContext) {
-	if !p.PreAction(ctx) {
-		return
-	}
-
+func (p *BilibiliVideoParsePlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	re := regexp.MustCompile(`https://[^\s]+`)
 	matches := re.FindAllString(ctx.Message.Content, -1)
 	if len(matches) == 0 {
-		return
+		return plugin.RunConsumed
 	}
 	bilibiliURL := matches[0]
 
 	respData, err := p.ParseBilibiliVideo(bilibiliURL)
 	if err != nil {
 		log.Printf("Bilibili视频解析失败: %v\n", err)
-		return
+		return plugin.RunConsumed
 	}
 
 	if respData.Data.URL == "" {
 		log.Printf("Bilibili视频解析成功但未获取到分享链接\n")
-		return
+		return plugin.RunConsumed
 	}
 
 	shareLink := robot.ShareLinkMessage{
@@ -115,6 +111,7 @@ func (p *BilibiliVideoParsePlugin) Run(ctx *plugin.MessageContext) {
 	if err != nil {
 		log.Printf("发送Bilibili分享链接失败: %v\n", err)
 	}
+	return plugin.RunConsumed
 }
 
 func (p *BilibiliVideoParsePlugin) ParseBilibiliVideo(bilibiliURL string) (BilibiliAPIResponse, error) {
@@ -0,0 +1,196 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"wechat-robot-client/interface/plugin"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/robot"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/service"
+	"wechat-robot-client/vars"
+)
+
+// ChatRoomAntiRecallPlugin 群聊防撤回，群成员撤回消息后在群里重发或私聊转发给管理员。
+// 插件实例被所有会话共享，每个群的配置在 Run 中读取，不保存在插件上
+type ChatRoomAntiRecallPlugin struct{}
+
+func NewChatRoomAntiRecallPlugin() plugin.MessageHandler {
+	return &ChatRoomAntiRecallPlugin{}
+}
+
+func (p *ChatRoomAntiRecallPlugin) GetName() string {
+	return "ChatRoomAntiRecall"
+}
+
+func (p *ChatRoomAntiRecallPlugin) GetLabels() []string {
+	return []string{plugin.LabelRecall}
+}
+
+func (p *ChatRoomAntiRecallPlugin) GetPriority() int {
+	return plugin.PriorityDefault
+}
+
+func (p *ChatRoomAntiRecallPlugin) Match(ctx *plugin.MessageContext) bool {
+	// 机器人自己撤回的消息（如 AI 回复的占位消息）不处理
+	return ctx.Message.IsChatRoom && ctx.Recall != nil && ctx.Recall.SenderWxID != vars.RobotRuntime.WxID
+}
+
+func (p *ChatRoomAntiRecallPlugin) PreAction(ctx *plugin.MessageContext) bool {
+	return true
+}
+
+// antiRecallTargets 读取群的防撤回配置，返回防撤回方式和接收通知的微信ID，未开启时返回 false
+func (p *ChatRoomAntiRecallPlugin) antiRecallTargets(chatRoomID string) (model.AntiRecallMode, []string, bool) {
+	chatRoomSettings, err := service.NewChatRoomSettingsService(context.Background()).GetChatRoomSettings(chatRoomID)
+	if err != nil {
+		log.Printf("获取群设置失败: %v", err)
+		return "", nil, false
+	}
+	if chatRoomSettings == nil || chatRoomSettings.AntiRecallEnabled == nil || !*chatRoomSettings.AntiRecallEnabled {
+		return "", nil, false
+	}
+	mode := model.AntiRecallModeForward
+	if chatRoomSettings.AntiRecallMode != nil && *chatRoomSettings.AntiRecallMode != "" {
+		mode = *chatRoomSettings.AntiRecallMode
+	}
+	if mode == model.AntiRecallModeRepost {
+		return mode, []string{chatRoomID}, true
+	}
+	notifyMemberList, err := chatRoomSettings.GetAntiRecallNotifyList()
+	if err != nil {
+		log.Printf("解析防撤回管理员列表失败: %v", err)
+		return "", nil, false
+	}
+	if len(notifyMemberList) == 0 {
+		log.Printf("防撤回管理员列表为空: 群ID=%s", chatRoomID)
+		return "", nil, false
+	}
+	return mode, notifyMemberList, true
+}
+
+func (p *ChatRoomAntiRecallPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
+}
+
+func (p *ChatRoomAntiRecallPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
+	recall := ctx.Recall
+	mode, targets, ok := p.antiRecallTargets(recall.FromWxID)
+	if !ok {
+		return plugin.RunContinue
+	}
+	senderName := p.memberName(ctx, recall.SenderWxID)
+	content, imageURL := p.recalledContent(recall)
+
+	header := fmt.Sprintf("群聊[%s]中 %s 撤回了一条消息：", p.chatRoomName(recall.FromWxID), senderName)
+	if mode == model.AntiRecallModeRepost {
+		header = fmt.Sprintf("%s 撤回了一条消息：", senderName)
+	}
+
+	notified := false
+	for _, target := range targets {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		if err := ctx.MessageService.SendTextMessage(target, header+"\n"+content); err != nil {
+			log.Printf("发送防撤回通知失败: %v", err)
+			continue
+		}
+		notified = true
+		if imageURL != "" {
+			if err := ctx.MessageService.SendImageMessageByRemoteURL(target, imageURL); err != nil {
+				log.Printf("发送防撤回图片失败: %v", err)
+			}
+		}
+	}
+	if notified {
+		if err := service.NewChatHistoryService(context.Background()).SetRecallNotified(recall.ID); err != nil {
+			log.Printf("更新撤回记录失败: %v", err)
+		}
+	}
+	return plugin.RunConsumed
+}
+
+// recalledContent 生成被撤回消息的文本描述，图片消息同时返回图片地址用于重发
+func (p *ChatRoomAntiRecallPlugin) recalledContent(recall *model.MessageRecall) (string, string) {
+	switch recall.Type {
+	case model.MsgTypeText:
+		return recall.Content, ""
+	case model.MsgTypeImage:
+		if recall.AttachmentUrl == "" {
+			return "[图片]（图片未上传，无法找回）", ""
+		}
+		return "[图片]", recall.AttachmentUrl
+	case model.MsgTypeVoice:
+		if recall.Transcript != "" {
+			return "[语音] " + recall.Transcript, ""
+		}
+		return "[语音]", ""
+	case model.MsgTypeVideo, model.MsgTypeMicroVideo:
+		return p.withAttachment("[视频]", recall.AttachmentUrl), ""
+	case model.MsgTypeEmoticon:
+		return "[表情]", ""
+	case model.MsgTypeLocation:
+		var xmlMessage robot.XMLLocationMessage
+		if err := vars.RobotRuntime.XmlDecoder(recall.Content, &xmlMessage); err != nil {
+			return "[位置]", ""
+		}
+		return "[位置] " + xmlMessage.Location.Describe(), ""
+	case model.MsgTypeShareCard:
+		var shareCard robot.ShareCardMessage
+		if err := vars.RobotRuntime.XmlDecoder(recall.Content, &shareCard); err != nil {
+			return "[名片]", ""
+		}
+		return "[名片] " + shareCard.Nickname, ""
+	case model.MsgTypeApp:
+		var xmlMessage robot.XmlMessage
+		if err := vars.RobotRuntime.XmlDecoder(recall.Content, &xmlMessage); err != nil {
+			return "[应用消息]", ""
+		}
+		title := xmlMessage.AppMsg.Title
+		switch recall.AppMsgType {
+		case model.AppMsgTypequote:
+			return title, ""
+		case model.AppMsgTypeAttach:
+			return p.withAttachment("[文件] "+title, recall.AttachmentUrl), ""
+		case model.AppMsgTypeUrl:
+			return fmt.Sprintf("[链接] %s\n%s", title, strings.ReplaceAll(xmlMessage.AppMsg.URL, "&amp;", "&")), ""
+		default:
+			return "[应用消息] " + title, ""
+		}
+	default:
+		return "[其他消息]", ""
+	}
+}
+
+func (p *ChatRoomAntiRecallPlugin) withAttachment(content, attachmentURL string) string {
+	if attachmentURL == "" {
+		return content
+	}
+	return content + "\n地址: " + attachmentURL
+}
+
+func (p *ChatRoomAntiRecallPlugin) memberName(ctx *plugin.MessageContext, wechatID string) string {
+	chatRoomMember, err := ctx.MessageService.GetChatRoomMember(ctx.Message.FromWxID, wechatID)
+	if err != nil || chatRoomMember == nil {
+		return wechatID
+	}
+	if chatRoomMember.Remark != "" {
+		return chatRoomMember.Remark
+	}
+	if chatRoomMember.Nickname != "" {
+		return chatRoomMember.Nickname
+	}
+	return wechatID
+}
+
+func (p *ChatRoomAntiRecallPlugin) chatRoomName(chatRoomID string) string {
+	contact, err := repository.NewContactRepo(context.Background(), vars.DB).GetContact(chatRoomID)
+	if err != nil || contact == nil || contact.Nickname == nil || *contact.Nickname == "" {
+		return chatRoomID
+	}
+	return *contact.Nickname
+}
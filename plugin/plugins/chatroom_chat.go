@@ -16,25 +16,26 @@ func (p *ChatRoomAIChatPlugin) GetName() string {
 }
 
 func (p *ChatRoomAIChatPlugin) GetLabels() []string {
-	return []string{"text", "chat"}
+	return []string{"text", "chat", "ai"}
+}
+
+func (p *ChatRoomAIChatPlugin) GetPriority() int {
+	return plugin.PriorityFallback
 }
 
 func (p *ChatRoomAIChatPlugin) Match(ctx *plugin.MessageContext) bool {
-	return NewChatRoomCommonPlugin().Match(ctx)
+	return ctx.Message.IsChatRoom
 }
 
 func (p *ChatRoomAIChatPlugin) PreAction(ctx *plugin.MessageContext) bool {
-	return NewChatRoomCommonPlugin().PreAction(ctx)
+	return true
 }
 
-func (p *ChatRoomAIChatPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *ChatRoomAIChatPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
-func (p *ChatRoomAIChatPlugin) Run(ctx *plugin.MessageContext) {
-	if !p.PreAction(ctx) {
-		return
-	}
+func (p *ChatRoomAIChatPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	isAIEnabled := ctx.Settings.IsAIChatEnabled()
 	isAITrigger := ctx.Settings.IsAITrigger()
 	if isAIEnabled {
@@ -47,10 +48,10 @@ func (p *ChatRoomAIChatPlugin) Run(ctx *plugin.MessageContext) {
 			}()
 			aiChat := NewAIChatPlugin()
 			if !aiChat.Match(ctx) {
-				return
+				return plugin.RunContinue
 			}
-			aiChat.Run(ctx)
-			return
+			return runPlugin(aiChat, ctx)
 		}
 	}
+	return plugin.RunContinue
 }
@@ -11,9 +11,8 @@ import (
 	"wechat-robot-client/vars"
 )
 
-type ChatRoomWxhbNotifyPlugin struct {
-	NotifyMemberList []string
-}
+// ChatRoomWxhbNotifyPlugin 群红包通知，插件实例被所有会话共享，通知成员列表在 Run 中读取
+type ChatRoomWxhbNotifyPlugin struct{}
 
 func NewChatRoomWxhbNotifyPlugin() plugin.MessageHandler {
 	return &ChatRoomWxhbNotifyPlugin{}
@@ -24,7 +23,11 @@ func (p *ChatRoomWxhbNotifyPlugin) GetName() string {
 }
 
 func (p *ChatRoomWxhbNotifyPlugin) GetLabels() []string {
-	return []string{"red-envelopes", "chat"}
+	return []string{plugin.LabelRedEnvelopes, "chat"}
+}
+
+func (p *ChatRoomWxhbNotifyPlugin) GetPriority() int {
+	return plugin.PriorityDefault
 }
 
 func (p *ChatRoomWxhbNotifyPlugin) Match(ctx *plugin.MessageContext) bool {
@@ -32,43 +35,45 @@ func (p *ChatRoomWxhbNotifyPlugin) Match(ctx *plugin.MessageContext) bool {
 }
 
 func (p *ChatRoomWxhbNotifyPlugin) PreAction(ctx *plugin.MessageContext) bool {
-	if !NewChatRoomCommonPlugin().PreAction(ctx) {
-		return false
-	}
-	chatRoomSettings, err := service.NewChatRoomSettingsService(context.Background()).GetChatRoomSettings(ctx.Message.FromWxID)
+	return true
+}
+
+// notifyMemberList 读取群的红包通知成员列表，未开启时返回 false
+func (p *ChatRoomWxhbNotifyPlugin) notifyMemberList(chatRoomID string) ([]string, bool) {
+	chatRoomSettings, err := service.NewChatRoomSettingsService(context.Background()).GetChatRoomSettings(chatRoomID)
 	if err != nil {
 		log.Printf("获取群设置失败: %v", err)
-		return false
+		return nil, false
 	}
 	if chatRoomSettings == nil {
-		log.Printf("群设置不存在: 群ID=%s", ctx.Message.FromWxID)
-		return false
+		log.Printf("群设置不存在: 群ID=%s", chatRoomID)
+		return nil, false
 	}
 	if chatRoomSettings.WxhbNotifyEnabled == nil || !*chatRoomSettings.WxhbNotifyEnabled {
-		log.Printf("群红包通知未开启: 群ID=%s", ctx.Message.FromWxID)
-		return false
+		log.Printf("群红包通知未开启: 群ID=%s", chatRoomID)
+		return nil, false
 	}
 	if chatRoomSettings.WxhbNotifyMemberList == nil || *chatRoomSettings.WxhbNotifyMemberList == "" {
-		log.Printf("群红包通知成员列表为空: 群ID=%s", ctx.Message.FromWxID)
-		return false
+		log.Printf("群红包通知成员列表为空: 群ID=%s", chatRoomID)
+		return nil, false
 	}
-	p.NotifyMemberList = strings.Split(*chatRoomSettings.WxhbNotifyMemberList, ",")
-	return true
+	return strings.Split(*chatRoomSettings.WxhbNotifyMemberList, ","), true
 }
 
-func (p *ChatRoomWxhbNotifyPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *ChatRoomWxhbNotifyPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 }
 
-func (p *ChatRoomWxhbNotifyPlugin) Run(ctx *plugin.MessageContext) {
-	if !p.PreAction(ctx) {
-		return
+func (p *ChatRoomWxhbNotifyPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
+	notifyMemberList, ok := p.notifyMemberList(ctx.Message.FromWxID)
+	if !ok {
+		return plugin.RunContinue
 	}
 
 	var xmlMessage robot.XmlMessage
 	err := vars.RobotRuntime.XmlDecoder(ctx.Message.Content, &xmlMessage)
 	if err != nil {
 		log.Printf("解析红包消息XML失败: %v", err)
-		return
+		return plugin.RunConsumed
 	}
 
 	var notifyText string
@@ -77,11 +82,11 @@ func (p *ChatRoomWxhbNotifyPlugin) Run(ctx *plugin.MessageContext) {
 	if ctx.Message.AppMsgType == model.AppMsgTypeEcsGift {
 		if xmlMessage.AppMsg.EcsGift == nil {
 			log.Printf("礼物消息EcsGift为空: 群ID=%s", ctx.Message.FromWxID)
-			return
+			return plugin.RunConsumed
 		}
 		if xmlMessage.AppMsg.EcsGift.SubType != 1 {
 			log.Printf("未知的礼物类型")
-			return
+			return plugin.RunConsumed
 		}
 		notifyText = "礼物来啦~"
 		if xmlMessage.AppMsg.EcsGift.TakeMethod == 2 {
@@ -90,27 +95,28 @@ func (p *ChatRoomWxhbNotifyPlugin) Run(ctx *plugin.MessageContext) {
 	} else {
 		if xmlMessage.AppMsg.WcPayInfo.SceneID == "1001" {
 			log.Println("群收款通知~")
-			return
+			return plugin.RunConsumed
 		}
 		notifyText = "红包来啦~"
 		exclusiveRecv = xmlMessage.AppMsg.WcPayInfo.ExclusiveRecvUsername
 	}
 
-	notifyTargets := p.buildNotifyTargets(ctx.Message.SenderWxID, exclusiveRecv)
+	notifyTargets := p.buildNotifyTargets(notifyMemberList, ctx.Message.SenderWxID, exclusiveRecv)
 	if len(notifyTargets) == 0 {
-		return
+		return plugin.RunConsumed
 	}
 
 	_ = ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, notifyText, notifyTargets...)
+	return plugin.RunConsumed
 }
 
-func (p *ChatRoomWxhbNotifyPlugin) buildNotifyTargets(senderWxID, exclusiveRecvUsername string) []string {
+func (p *ChatRoomWxhbNotifyPlugin) buildNotifyTargets(notifyMemberList []string, senderWxID, exclusiveRecvUsername string) []string {
 	senderWxID = strings.TrimSpace(senderWxID)
 	exclusiveRecvUsername = strings.TrimSpace(exclusiveRecvUsername)
 
-	uniqueNotifyMembers := make([]string, 0, len(p.NotifyMemberList))
-	memberSet := make(map[string]struct{}, len(p.NotifyMemberList))
-	for _, member := range p.NotifyMemberList {
+	uniqueNotifyMembers := make([]string, 0, len(notifyMemberList))
+	memberSet := make(map[string]struct{}, len(notifyMemberList))
+	for _, member := range notifyMemberList {
 		member = strings.TrimSpace(member)
 		if member == "" {
 			continue
@@ -169,10 +169,6 @@ func (p *DouyinVideoParsePlugin) GetLabels() []string {
 
 func (p *DouyinVideoParsePlugin) PreAction(ctx *plugin.MessageContext) bool {
 	if ctx.Message.IsChatRoom {
-		next := NewChatRoomCommonPlugin().PreAction(ctx)
-		if !next {
-			return false
-		}
 		if !ctx.Settings.IsShortVideoParsingEnabled() {
 			return false
 		}
@@ -180,10 +176,14 @@ func (p *DouyinVideoParsePlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *DouyinVideoParsePlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *DouyinVideoParsePlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
+func (p *DouyinVideoParsePlugin) GetPriority() int {
+	return plugin.PriorityParser
+}
+
 func (p *DouyinVideoParsePlugin) Match(ctx *plugin.MessageContext) bool {
 	if ctx.ReferMessage != nil {
 		// 不解析引用的抖音链接
@@ -192,16 +192,12 @@ func (p *DouyinVideoParsePlugin) Match(ctx *plugin.MessageContext) bool {
 	return strings.Contains(ctx.Message.Content, "https://v.douyin.com")
 }
 
-func (p *DouyinVideoParsePlugin) Run(ctx *plugin.MessageContext) {
-	if !p.PreAction(ctx) {
-		return
-	}
-
+func (p *DouyinVideoParsePlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	re := regexp.MustCompile(`https://[^\s]+`)
 	matches := re.FindAllString(ctx.Message.Content, -1)
 	if len(matches) == 0 {
 		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, "未找到抖音链接")
-		return
+		return plugin.RunConsumed
 	}
 	douyinURL := matches[0]
 
@@ -211,7 +207,7 @@ func (p *DouyinVideoParsePlugin) Run(ctx *plugin.MessageContext) {
 		fallbackRespData, err2 := parseDouyinVideoByExternalAPI(douyinURL)
 		if err2 != nil {
 			ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, formatDouyinFallbackError(err, err2))
-			return
+			return plugin.RunConsumed
 		}
 		externalAPIParsed = true
 		respData = fallbackRespData
@@ -248,7 +244,7 @@ func (p *DouyinVideoParsePlugin) Run(ctx *plugin.MessageContext) {
 			ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, fmt.Sprintf("发送抖音视频失败: %v", err.Error()))
 		}
 
-		return
+		return plugin.RunConsumed
 	}
 
 	if len(respData.Data.Images) > 0 {
@@ -291,10 +287,11 @@ func (p *DouyinVideoParsePlugin) Run(ctx *plugin.MessageContext) {
 				ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, fmt.Sprintf("发送图片失败: %v", err))
 			}
 		}
-		return
+		return plugin.RunConsumed
 	}
 
 	ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, "解析失败，可能是链接已失效或格式不正确")
+	return plugin.RunConsumed
 }
 
 func parseDouyinVideo(rawURL string) (VideoParseResponse, error) {
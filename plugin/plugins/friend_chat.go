@@ -17,7 +17,11 @@ func (p *FriendAIChatPlugin) GetName() string {
 }
 
 func (p *FriendAIChatPlugin) GetLabels() []string {
-	return []string{"text", "chat"}
+	return []string{"text", "chat", "ai"}
+}
+
+func (p *FriendAIChatPlugin) GetPriority() int {
+	return plugin.PriorityFallback
 }
 
 func (p *FriendAIChatPlugin) Match(ctx *plugin.MessageContext) bool {
@@ -28,14 +32,14 @@ func (p *FriendAIChatPlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *FriendAIChatPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *FriendAIChatPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
-func (p *FriendAIChatPlugin) Run(ctx *plugin.MessageContext) {
+func (p *FriendAIChatPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	// 修复 AI 会响应自己发送(从其他设备)的消息的问题
 	if ctx.Message != nil && ctx.Message.SenderWxID == vars.RobotRuntime.WxID {
-		return
+		return plugin.RunStop
 	}
 	isAIEnabled := ctx.Settings.IsAIChatEnabled()
 	if isAIEnabled {
@@ -47,9 +51,9 @@ func (p *FriendAIChatPlugin) Run(ctx *plugin.MessageContext) {
 		}()
 		aiChat := NewAIChatPlugin()
 		if !aiChat.Match(ctx) {
-			return
+			return plugin.RunContinue
 		}
-		aiChat.Run(ctx)
-		return
+		return runPlugin(aiChat, ctx)
 	}
+	return plugin.RunContinue
 }
@@ -23,6 +23,10 @@ func (p *ImageAutoUploadPlugin) GetLabels() []string {
 	return []string{"image", "oss"}
 }
 
+func (p *ImageAutoUploadPlugin) GetPriority() int {
+	return plugin.PriorityDefault
+}
+
 func (p *ImageAutoUploadPlugin) Match(ctx *plugin.MessageContext) bool {
 	return ctx.Message != nil && ctx.Message.Type == model.MsgTypeImage
 }
@@ -31,30 +35,31 @@ func (p *ImageAutoUploadPlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *ImageAutoUploadPlugin) PostAction(ctx *plugin.MessageContext) {
+func (p *ImageAutoUploadPlugin) PostAction(ctx *plugin.MessageContext, result plugin.RunResult) {
 
 }
 
-func (p *ImageAutoUploadPlugin) Run(ctx *plugin.MessageContext) {
+func (p *ImageAutoUploadPlugin) Run(ctx *plugin.MessageContext) plugin.RunResult {
 	if time.Now().Unix()-vars.RobotRuntime.LoginTime < 60 {
 		log.Printf("登录时间不足60秒，跳过图片自动上传")
-		return
+		return plugin.RunContinue
 	}
 	ossSettingService := service.NewOSSSettingService(ctx.Context)
 	ossSettings, err := ossSettingService.GetOSSSettingService()
 	if err != nil {
 		log.Printf("获取OSS设置失败: %v", err)
-		return
+		return plugin.RunContinue
 	}
 	if ossSettings == nil {
 		log.Printf("OSS设置为空")
-		return
+		return plugin.RunContinue
 	}
 	if ossSettings.AutoUploadImage != nil && *ossSettings.AutoUploadImage && ossSettings.AutoUploadImageMode == model.AutoUploadModeAll {
 		err := ossSettingService.UploadImageToOSS(ossSettings, ctx.Message)
 		if err != nil {
 			log.Printf("上传图片到OSS失败: %v", err)
 		}
-		return
+		return plugin.RunContinue
 	}
+	return plugin.RunContinue
 }
@@ -0,0 +1,183 @@
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"wechat-robot-client/interface/plugin"
+)
+
+// BlacklistInterceptor 群成员黑名单拦截，黑名单成员的消息终止插件链
+type BlacklistInterceptor struct{}
+
+func NewBlacklistInterceptor() plugin.Interceptor {
+	return &BlacklistInterceptor{}
+}
+
+func (i *BlacklistInterceptor) GetName() string {
+	return "Blacklist"
+}
+
+func (i *BlacklistInterceptor) Intercept(handler plugin.MessageHandler, ctx *plugin.MessageContext, next func() plugin.RunResult) plugin.RunResult {
+	// 图片自动上传等归档类插件不受黑名单限制
+	if !ctx.Message.IsChatRoom || slices.Contains(handler.GetLabels(), "oss") {
+		return next()
+	}
+	chatRoomMember, err := ctx.GetSenderChatRoomMember()
+	if err != nil {
+		log.Printf("获取群成员信息失败: %v", err)
+		return plugin.RunContinue
+	}
+	if chatRoomMember == nil {
+		log.Printf("群成员信息不存在: 群ID=%s, 成员微信ID=%s", ctx.Message.FromWxID, ctx.Message.SenderWxID)
+		return plugin.RunContinue
+	}
+	if chatRoomMember.IsBlacklisted != nil && *chatRoomMember.IsBlacklisted {
+		log.Printf("群成员[%s]在黑名单中，跳过插件[%s]", chatRoomMember.Nickname, handler.GetName())
+		return plugin.RunStop
+	}
+	return next()
+}
+
+const rateLimitIdleTTL = 10 * time.Minute
+
+type senderLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+	notified bool // 本次限流是否已经提醒过发送者
+}
+
+// RateLimitInterceptor 按会话+发送者限流，只作用于带有 ai 标签的 AI 对话插件，
+// 只有消息被插件认领时才消耗令牌，未触发 AI 的普通聊天不计入。
+// 触发限流时只跳过当前插件，插件链继续执行
+type RateLimitInterceptor struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	limiters map[string]*senderLimiter
+}
+
+// NewRateLimitInterceptor 每个发送者每 interval 恢复一次，最多连续触发 burst 次
+func NewRateLimitInterceptor(interval time.Duration, burst int) plugin.Interceptor {
+	return &RateLimitInterceptor{
+		interval: interval,
+		burst:    burst,
+		limiters: make(map[string]*senderLimiter),
+	}
+}
+
+func (i *RateLimitInterceptor) GetName() string {
+	return "RateLimit"
+}
+
+func (i *RateLimitInterceptor) getLimiter(key string) *senderLimiter {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	now := time.Now()
+	for k, l := range i.limiters {
+		if now.Sub(l.lastSeen) > rateLimitIdleTTL {
+			delete(i.limiters, k)
+		}
+	}
+	l, ok := i.limiters[key]
+	if !ok {
+		l = &senderLimiter{limiter: rate.NewLimiter(rate.Every(i.interval), i.burst)}
+		i.limiters[key] = l
+	}
+	l.lastSeen = now
+	return l
+}
+
+// shouldNotify 同一次限流只提醒一次，恢复后重新计算
+func (i *RateLimitInterceptor) shouldNotify(l *senderLimiter, limited bool) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !limited {
+		l.notified = false
+		return false
+	}
+	if l.notified {
+		return false
+	}
+	l.notified = true
+	return true
+}
+
+func (i *RateLimitInterceptor) Intercept(handler plugin.MessageHandler, ctx *plugin.MessageContext, next func() plugin.RunResult) plugin.RunResult {
+	if !slices.Contains(handler.GetLabels(), "ai") || ctx.Settings == nil || !ctx.Settings.IsAIChatEnabled() || !ctx.Settings.IsAITrigger() {
+		return next()
+	}
+	l := i.getLimiter(ctx.Message.FromWxID + "|" + ctx.Message.SenderWxID)
+	limited := l.limiter.Tokens() < 1
+	if i.shouldNotify(l, limited) {
+		log.Printf("[PluginRateLimit] plugin=%s from=%s sender=%s 触发限流", handler.GetName(), ctx.Message.FromWxID, ctx.Message.SenderWxID)
+		content := fmt.Sprintf("提问太频繁了，请 %s 后再试", i.interval)
+		if ctx.Message.IsChatRoom {
+			ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, content, ctx.Message.SenderWxID)
+		} else {
+			ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, content)
+		}
+	}
+	if limited {
+		return plugin.RunContinue
+	}
+	result := next()
+	if result == plugin.RunConsumed {
+		l.limiter.Allow()
+	}
+	return result
+}
+
+// AuditInterceptor 记录管理员指令等消息被认领的审计日志
+type AuditInterceptor struct{}
+
+func NewAuditInterceptor() plugin.Interceptor {
+	return &AuditInterceptor{}
+}
+
+func (i *AuditInterceptor) GetName() string {
+	return "Audit"
+}
+
+func (i *AuditInterceptor) Intercept(handler plugin.MessageHandler, ctx *plugin.MessageContext, next func() plugin.RunResult) plugin.RunResult {
+	result := next()
+	if result == plugin.RunContinue || handler.GetPriority() < plugin.PriorityCommand {
+		return result
+	}
+	log.Printf("[PluginAudit] plugin=%s msg_id=%d from=%s sender=%s result=%s content=%q",
+		handler.GetName(),
+		ctx.Message.MsgId,
+		ctx.Message.FromWxID,
+		ctx.Message.SenderWxID,
+		result,
+		ctx.MessageContent,
+	)
+	return result
+}
+
+const slowPluginThreshold = 5 * time.Second
+
+// TimingInterceptor 记录执行耗时过长的插件
+type TimingInterceptor struct{}
+
+func NewTimingInterceptor() plugin.Interceptor {
+	return &TimingInterceptor{}
+}
+
+func (i *TimingInterceptor) GetName() string {
+	return "Timing"
+}
+
+func (i *TimingInterceptor) Intercept(handler plugin.MessageHandler, ctx *plugin.MessageContext, next func() plugin.RunResult) plugin.RunResult {
+	start := time.Now()
+	result := next()
+	if cost := time.Since(start); cost >= slowPluginThreshold {
+		log.Printf("[PluginTiming] plugin=%s msg_id=%d result=%s cost=%s", handler.GetName(), ctx.Message.MsgId, result, cost)
+	}
+	return result
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
-	"log"
 	"strings"
 
 	"wechat-robot-client/interface/plugin"
@@ -21,41 +20,30 @@ type PodcastPlugin struct {
 	PodcastConfig  podcast.PodcastConfig
 }
 
-func NewPodcastPlugin() plugin.MessageHandler {
-	return &PodcastPlugin{}
-}
-
-func (p *PodcastPlugin) GetName() string {
-	return "Podcast"
-}
-
-func (p *PodcastPlugin) GetLabels() []string {
-	return []string{"text", "chat"}
-}
-
-func (p *PodcastPlugin) Match(ctx *plugin.MessageContext) bool {
-	return strings.HasPrefix(ctx.MessageContent, "#AI播客")
+func NewPodcastCommand() *plugin.Command {
+	return &plugin.Command{
+		Name: "AI播客",
+		Args: []plugin.CommandArg{
+			{Name: "内容", Type: plugin.ArgText, Description: "播客文本内容或音频链接，也可以引用链接、聊天记录消息"},
+		},
+		Role:         plugin.RoleGroupAdmin,
+		Description:  "根据文本、链接或聊天记录生成 AI 播客",
+		ChatRoomOnly: true,
+		Handler:      generatePodcast,
+	}
 }
 
-func (p *PodcastPlugin) PreAction(ctx *plugin.MessageContext) bool {
-	chatRoomMember, err := ctx.MessageService.GetChatRoomMember(ctx.Message.FromWxID, ctx.Message.SenderWxID)
-	if err != nil {
-		log.Printf("获取群成员信息失败: %v", err)
-		return false
-	}
-	if chatRoomMember == nil {
-		log.Printf("群成员信息不存在: 群ID=%s, 成员微信ID=%s", ctx.Message.FromWxID, ctx.Message.SenderWxID)
-		return false
-	}
-	if chatRoomMember.IsBlacklisted != nil && *chatRoomMember.IsBlacklisted {
-		log.Printf("群成员[%s]在黑名单中，跳过AI回复", chatRoomMember.Nickname)
-		return false
-	}
-	if chatRoomMember.IsAdmin == nil || !*chatRoomMember.IsAdmin {
-		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, "您配使用这个指令吗？")
-		return false
+func generatePodcast(ctx *plugin.MessageContext, args plugin.CommandArgs) error {
+	// 播客配置与单次请求相关，每次执行都创建新的实例
+	p := &PodcastPlugin{}
+	if !p.loadPodcastSecrets(ctx) {
+		return fmt.Errorf("当前群聊未开启AI播客功能或播客密钥未配置")
 	}
+	p.generate(ctx)
+	return nil
+}
 
+func (p *PodcastPlugin) loadPodcastSecrets(ctx *plugin.MessageContext) bool {
 	chatRoomSettings, err := service.NewChatRoomSettingsService(context.Background()).GetChatRoomSettings(ctx.Message.FromWxID)
 	if err != nil || chatRoomSettings == nil {
 		return false
@@ -96,14 +84,7 @@ func (p *PodcastPlugin) PreAction(ctx *plugin.MessageContext) bool {
 	return true
 }
 
-func (p *PodcastPlugin) PostAction(ctx *plugin.MessageContext) {
-}
-
-func (p *PodcastPlugin) Run(ctx *plugin.MessageContext) {
-	if !p.PreAction(ctx) {
-		return
-	}
-
+func (p *PodcastPlugin) generate(ctx *plugin.MessageContext) {
 	if ctx.Message.Type == model.MsgTypeText {
 		if err := p.InitPodcastConfigFromTextMessage(ctx.MessageContent); err != nil {
 			ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, err.Error())
@@ -2,99 +2,62 @@ package plugins
 
 import (
 	"context"
+	"fmt"
 	"log"
-	"strings"
 	"wechat-robot-client/interface/plugin"
 	"wechat-robot-client/service"
 )
 
-type SwitchChatModelPlugin struct{}
-
-func NewSwitchChatModelPlugin() plugin.MessageHandler {
-	return &SwitchChatModelPlugin{}
-}
-
-func (p *SwitchChatModelPlugin) GetName() string {
-	return "SwitchChatModel"
-}
-
-func (p *SwitchChatModelPlugin) GetLabels() []string {
-	return []string{"text", "chat"}
-}
-
-func (p *SwitchChatModelPlugin) Match(ctx *plugin.MessageContext) bool {
-	return strings.HasPrefix(ctx.MessageContent, "#切换聊天模型")
-}
-
-func (p *SwitchChatModelPlugin) PreAction(ctx *plugin.MessageContext) bool {
-	chatRoomMember, err := ctx.MessageService.GetChatRoomMember(ctx.Message.FromWxID, ctx.Message.SenderWxID)
-	if err != nil {
-		log.Printf("获取群成员信息失败: %v", err)
-		return false
-	}
-	if chatRoomMember == nil {
-		log.Printf("群成员信息不存在: 群ID=%s, 成员微信ID=%s", ctx.Message.FromWxID, ctx.Message.SenderWxID)
-		return false
-	}
-	if chatRoomMember.IsBlacklisted != nil && *chatRoomMember.IsBlacklisted {
-		log.Printf("群成员[%s]在黑名单中，跳过AI回复", chatRoomMember.Nickname)
-		return false
+func NewSwitchChatModelCommand() *plugin.Command {
+	return &plugin.Command{
+		Name: "切换聊天模型",
+		Args: []plugin.CommandArg{
+			{Name: "模型名称", Type: plugin.ArgString, Required: true, Description: "要切换的聊天模型名称，例如 gpt-3.5-turbo"},
+		},
+		Role:        plugin.RoleGroupAdmin,
+		Description: "切换当前会话使用的聊天模型",
+		Handler:     switchChatModel,
 	}
-	if chatRoomMember.IsAdmin == nil || !*chatRoomMember.IsAdmin {
-		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, "您配使用这个指令吗？", ctx.Message.SenderWxID)
-		return false
-	}
-	return true
-}
-
-func (p *SwitchChatModelPlugin) PostAction(ctx *plugin.MessageContext) {
 }
 
-func (p *SwitchChatModelPlugin) Run(ctx *plugin.MessageContext) {
-	if !p.PreAction(ctx) {
-		return
-	}
-
-	parts := strings.Fields(ctx.MessageContent)
-	if len(parts) < 2 {
-		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, "请提供要切换的聊天模型名称，例如：#切换聊天模型 gpt-3.5-turbo", ctx.Message.SenderWxID)
-		return
-	}
-	newModel := parts[1]
+func switchChatModel(ctx *plugin.MessageContext, args plugin.CommandArgs) error {
+	newModel := args.String("模型名称")
 	if ctx.Message.IsChatRoom {
 		svc := service.NewChatRoomSettingsService(context.Background())
 		chatRoomSettings, err := svc.GetChatRoomSettings(ctx.Message.FromWxID)
 		if err != nil {
 			log.Printf("获取群设置失败: %v", err)
-			return
+			return fmt.Errorf("获取群设置失败")
 		}
 		if chatRoomSettings == nil {
 			log.Printf("群设置不存在: 群ID=%s", ctx.Message.FromWxID)
-			return
+			return fmt.Errorf("群设置不存在，请先在后台完成群聊配置")
 		}
 		chatRoomSettings.ChatModel = &newModel
 		err = svc.SaveChatRoomSettings(chatRoomSettings)
 		if err != nil {
 			log.Printf("保存群设置失败: %v", err)
-			return
-		}
-		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, "已将聊天模型切换为："+newModel, ctx.Message.SenderWxID)
-	} else {
-		friendSettings, err := service.NewFriendSettingsService(context.Background()).GetFriendSettings(ctx.Message.FromWxID)
-		if err != nil {
-			log.Printf("获取群设置失败: %v", err)
-			return
-		}
-		if friendSettings == nil {
-			log.Printf("群设置不存在: 群ID=%s", ctx.Message.FromWxID)
-			return
-		}
-		friendSettings.ChatModel = &newModel
-		err = service.NewFriendSettingsService(context.Background()).SaveFriendSettings(friendSettings)
-		if err != nil {
-			log.Printf("保存好友设置失败: %v", err)
-			return
+			return fmt.Errorf("保存群设置失败")
 		}
 		ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, "已将聊天模型切换为："+newModel, ctx.Message.SenderWxID)
+		return nil
+	}
+	svc := service.NewFriendSettingsService(context.Background())
+	friendSettings, err := svc.GetFriendSettings(ctx.Message.FromWxID)
+	if err != nil {
+		log.Printf("获取好友设置失败: %v", err)
+		return fmt.Errorf("获取好友设置失败")
+	}
+	if friendSettings == nil {
+		log.Printf("好友设置不存在: 好友ID=%s", ctx.Message.FromWxID)
+		return fmt.Errorf("好友设置不存在，请先在后台完成好友配置")
+	}
+	friendSettings.ChatModel = &newModel
+	err = svc.SaveFriendSettings(friendSettings)
+	if err != nil {
+		log.Printf("保存好友设置失败: %v", err)
+		return fmt.Errorf("保存好友设置失败")
 	}
+	ctx.MessageService.SendTextMessage(ctx.Message.FromWxID, "已将聊天模型切换为："+newModel)
+	return nil
 }
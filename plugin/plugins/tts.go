@@ -0,0 +1,68 @@
+package plugins
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"wechat-robot-client/interface/plugin"
+	"wechat-robot-client/plugin/pkg"
+)
+
+// synthesizeSpeech 按当前会话的文本转语音配置合成语音，返回音频数据和带点的扩展名
+func synthesizeSpeech(ctx *plugin.MessageContext, text, timbre string) ([]byte, string, error) {
+	if !ctx.Settings.IsTTSEnabled() {
+		return nil, "", errors.New("文本转语音功能未开启，请联系管理员。")
+	}
+	aiConfig := ctx.Settings.GetAIConfig()
+	var ttsSettingsMap map[string]json.RawMessage
+	if err := json.Unmarshal(aiConfig.TTSSettings, &ttsSettingsMap); err != nil {
+		return nil, "", fmt.Errorf("反序列化文本转语音配置失败: %w", err)
+	}
+	switch aiConfig.TTSModel {
+	case "doubao":
+		modelRaw, ok := ttsSettingsMap["doubao"]
+		if !ok {
+			return nil, "", errors.New("文本转语音配置中缺少 doubao 配置")
+		}
+		var doubaoConfig pkg.DoubaoTTSConfig
+		if err := json.Unmarshal(modelRaw, &doubaoConfig); err != nil {
+			return nil, "", fmt.Errorf("反序列化豆包文本转语音配置失败: %w", err)
+		}
+		doubaoConfig.RequestBody.ReqParams.Speaker = timbre
+		doubaoConfig.RequestBody.ReqParams.Text = text
+
+		audioBase64, err := pkg.DoubaoTTSSubmit(&doubaoConfig)
+		if err != nil {
+			return nil, "", fmt.Errorf("豆包文本转语音请求失败: %w", err)
+		}
+		audioData, err := base64.StdEncoding.DecodeString(audioBase64)
+		if err != nil {
+			return nil, "", fmt.Errorf("音频数据解码失败: %w", err)
+		}
+		return audioData, fmt.Sprintf(".%s", doubaoConfig.RequestBody.ReqParams.AudioParams.Format), nil
+	case "mimo":
+		modelRaw, ok := ttsSettingsMap["mimo"]
+		if !ok {
+			return nil, "", errors.New("文本转语音配置中缺少 mimo 配置")
+		}
+		var mimoConfig pkg.MimoTTSConfig
+		if err := json.Unmarshal(modelRaw, &mimoConfig); err != nil {
+			return nil, "", fmt.Errorf("反序列化 mimo 文本转语音配置失败: %w", err)
+		}
+		if mimoConfig.BaseURL == "" {
+			mimoConfig.BaseURL = aiConfig.BaseURL
+		}
+		if mimoConfig.APIKey == "" {
+			mimoConfig.APIKey = aiConfig.APIKey
+		}
+		wavBytes, err := pkg.MimoTTSSubmit(&mimoConfig, text, timbre)
+		if err != nil {
+			return nil, "", fmt.Errorf("mimo 文本转语音请求失败: %w", err)
+		}
+		return wavBytes, ".wav", nil
+	default:
+		return nil, "", fmt.Errorf("未知的 TTS 模型: %s", aiConfig.TTSModel)
+	}
+}
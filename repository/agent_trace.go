@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+
+	"gorm.io/gorm"
+)
+
+type AgentTrace struct {
+	Ctx context.Context
+	DB  *gorm.DB
+}
+
+func NewAgentTraceRepo(ctx context.Context, db *gorm.DB) *AgentTrace {
+	return &AgentTrace{
+		Ctx: ctx,
+		DB:  db,
+	}
+}
+
+func (r *AgentTrace) Create(data *model.AgentTrace) error {
+	return r.DB.WithContext(r.Ctx).Create(data).Error
+}
+
+func (r *AgentTrace) GetByID(id int64) (*model.AgentTrace, error) {
+	var trace model.AgentTrace
+	err := r.DB.WithContext(r.Ctx).Where("id = ?", id).First(&trace).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &trace, nil
+}
+
+// GetList 列表不返回输入消息和执行过程，详情通过 GetByID 获取
+func (r *AgentTrace) GetList(req dto.AgentTraceListRequest, pager appx.Pager) ([]*model.AgentTrace, int64, error) {
+	var traces []*model.AgentTrace
+	var total int64
+
+	query := r.DB.WithContext(r.Ctx).Model(&model.AgentTrace{})
+	if req.MessageID > 0 {
+		query = query.Where("message_id = ?", req.MessageID)
+	}
+	if req.ChatID != "" {
+		query = query.Where("chat_id = ?", req.ChatID)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.ReplayOf > 0 {
+		query = query.Where("replay_of = ?", req.ReplayOf)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Omit("input", "tools", "steps").Order("id DESC").Offset(pager.OffSet).Limit(pager.PageSize).Find(&traces).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return traces, total, nil
+}
+
+// DeleteBefore 分批删除 createdAt 之前的执行记录，避免一次删除过多数据长时间锁表
+func (r *AgentTrace) DeleteBefore(createdAt int64, batchSize int) (int64, error) {
+	var total int64
+	for {
+		var ids []int64
+		err := r.DB.WithContext(r.Ctx).Model(&model.AgentTrace{}).
+			Where("created_at < ?", createdAt).
+			Order("id ASC").
+			Limit(batchSize).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		result := r.DB.WithContext(r.Ctx).Where("id IN ?", ids).Delete(&model.AgentTrace{})
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+		if len(ids) < batchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"wechat-robot-client/model"
+
+	"gorm.io/gorm"
+)
+
+type AIProvider struct {
+	Ctx context.Context
+	DB  *gorm.DB
+}
+
+func NewAIProviderRepo(ctx context.Context, db *gorm.DB) *AIProvider {
+	return &AIProvider{
+		Ctx: ctx,
+		DB:  db,
+	}
+}
+
+func (r *AIProvider) Create(data *model.AIProvider) error {
+	return r.DB.WithContext(r.Ctx).Create(data).Error
+}
+
+func (r *AIProvider) Update(data *model.AIProvider) error {
+	return r.DB.WithContext(r.Ctx).Where("id = ?", data.ID).Updates(data).Error
+}
+
+func (r *AIProvider) Delete(id int64) error {
+	return r.DB.WithContext(r.Ctx).Where("id = ?", id).Delete(&model.AIProvider{}).Error
+}
+
+func (r *AIProvider) GetByID(id int64) (*model.AIProvider, error) {
+	var provider model.AIProvider
+	err := r.DB.WithContext(r.Ctx).Where("id = ?", id).First(&provider).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &provider, nil
+}
+
+func (r *AIProvider) GetByName(name string) (*model.AIProvider, error) {
+	var provider model.AIProvider
+	err := r.DB.WithContext(r.Ctx).Where("name = ?", name).First(&provider).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &provider, nil
+}
+
+func (r *AIProvider) GetList() ([]*model.AIProvider, error) {
+	var providers []*model.AIProvider
+	err := r.DB.WithContext(r.Ctx).Order("id ASC").Find(&providers).Error
+	return providers, err
+}
+
+func (r *AIProvider) GetEnabled() ([]*model.AIProvider, error) {
+	var providers []*model.AIProvider
+	err := r.DB.WithContext(r.Ctx).Where("enabled = ?", true).Find(&providers).Error
+	return providers, err
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AIUsage struct {
+	Ctx context.Context
+	DB  *gorm.DB
+}
+
+func NewAIUsageRepo(ctx context.Context, db *gorm.DB) *AIUsage {
+	return &AIUsage{
+		Ctx: ctx,
+		DB:  db,
+	}
+}
+
+// Accumulate 累加当天的用量，没有记录时新建
+func (r *AIUsage) Accumulate(data *model.AIUsage) error {
+	return r.DB.WithContext(r.Ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"calls":             gorm.Expr("calls + ?", data.Calls),
+			"prompt_tokens":     gorm.Expr("prompt_tokens + ?", data.PromptTokens),
+			"completion_tokens": gorm.Expr("completion_tokens + ?", data.CompletionTokens),
+			"total_tokens":      gorm.Expr("total_tokens + ?", data.TotalTokens),
+			"updated_at":        gorm.Expr("VALUES(updated_at)"),
+		}),
+	}).Create(data).Error
+}
+
+// Sum 统计某天某个会话的用量，senderWxID 为空时统计整个会话
+func (r *AIUsage) Sum(date, chatID, senderWxID string, feature model.AIUsageFeature) (calls, tokens int64, err error) {
+	var result struct {
+		Calls  int64
+		Tokens int64
+	}
+	query := r.DB.WithContext(r.Ctx).Model(&model.AIUsage{}).
+		Select("COALESCE(SUM(calls), 0) AS calls, COALESCE(SUM(total_tokens), 0) AS tokens").
+		Where("date = ? AND chat_id = ? AND feature = ?", date, chatID, feature)
+	if senderWxID != "" {
+		query = query.Where("sender_wxid = ?", senderWxID)
+	}
+	if err := query.Scan(&result).Error; err != nil {
+		return 0, 0, err
+	}
+	return result.Calls, result.Tokens, nil
+}
+
+func (r *AIUsage) filter(req dto.AIUsageListRequest) *gorm.DB {
+	query := r.DB.WithContext(r.Ctx).Model(&model.AIUsage{})
+	if req.StartDate != "" {
+		query = query.Where("date >= ?", req.StartDate)
+	}
+	if req.EndDate != "" {
+		query = query.Where("date <= ?", req.EndDate)
+	}
+	if req.ChatID != "" {
+		query = query.Where("chat_id = ?", req.ChatID)
+	}
+	if req.SenderWxID != "" {
+		query = query.Where("sender_wxid = ?", req.SenderWxID)
+	}
+	if req.Feature != "" {
+		query = query.Where("feature = ?", req.Feature)
+	}
+	return query
+}
+
+func (r *AIUsage) GetList(req dto.AIUsageListRequest, pager appx.Pager) ([]*model.AIUsage, int64, error) {
+	var usages []*model.AIUsage
+	var total int64
+
+	query := r.filter(req)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("date DESC, total_tokens DESC").Offset(pager.OffSet).Limit(pager.PageSize).Find(&usages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return usages, total, nil
+}
+
+// GetDailySummary 按天和功能汇总用量，不区分会话和模型
+func (r *AIUsage) GetDailySummary(req dto.AIUsageListRequest) ([]*model.AIUsage, error) {
+	var usages []*model.AIUsage
+	err := r.filter(req).
+		Select("date, feature, SUM(calls) AS calls, SUM(prompt_tokens) AS prompt_tokens, SUM(completion_tokens) AS completion_tokens, SUM(total_tokens) AS total_tokens").
+		Group("date, feature").
+		Order("date DESC, feature").
+		Find(&usages).Error
+	if err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
@@ -184,6 +184,17 @@ func (c *ChatRoomMember) AtomicUpdateScores(chatRoomID, wechatID string, updates
 	return db.Updates(updates).Error
 }
 
+// DeductScore 积分足够时扣减积分，返回是否扣减成功
+func (c *ChatRoomMember) DeductScore(chatRoomID, wechatID string, score int64) (bool, error) {
+	result := c.DB.WithContext(c.Ctx).Model(&model.ChatRoomMember{}).
+		Where("chat_room_id = ? AND wechat_id = ? AND score >= ?", chatRoomID, wechatID, score).
+		Update("score", gorm.Expr("score - ?", score))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 func (c *ChatRoomMember) UpdateMemberInfo(chatRoomID, wechatID string, updates map[string]any) error {
 	return c.DB.WithContext(c.Ctx).Model(&model.ChatRoomMember{}).
 		Where("chat_room_id = ? AND wechat_id = ?", chatRoomID, wechatID).
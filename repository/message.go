@@ -2,15 +2,27 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"math/rand"
 	"strings"
 	"time"
 	"wechat-robot-client/dto"
 	"wechat-robot-client/model"
 	"wechat-robot-client/pkg/appx"
 
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
 )
 
+// IsDuplicateKeyError 是否是违反唯一索引的错误
+func IsDuplicateKeyError(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
 type Message struct {
 	Ctx context.Context
 	DB  *gorm.DB
@@ -108,7 +120,7 @@ func (m *Message) GetFriendAIMessageContext(message *model.Message) ([]*model.Me
 	err := m.DB.WithContext(m.Ctx).Where("id < ?", message.ID).
 		Where("from_wxid = ?", message.FromWxID).
 		Where("created_at >= ?", tenMinutesAgo).
-		Where("`type` in (1, 3) OR (`type` = 49 AND `app_msg_type` = 57)").
+		Where("`type` in (1, 3, 48) OR (`type` = 34 AND transcript != '') OR (`type` = 49 AND `app_msg_type` = 57)").
 		Find(&messages).
 		Order("id ASC").Error
 	if err != nil {
@@ -133,7 +145,7 @@ func (m *Message) GetChatRoomAIMessageContext(message *model.Message) ([]*model.
 		Where("from_wxid = ?", message.FromWxID).
 		Where("(sender_wxid = ? AND is_ai_context = 1) OR reply_wxid = ?", message.SenderWxID, message.SenderWxID).
 		Where("created_at >= ?", tenMinutesAgo).
-		Where("`type` in (1, 3) OR (`type` = 49 AND `app_msg_type` = 57)").
+		Where("`type` in (1, 3, 48) OR (`type` = 34 AND transcript != '') OR (`type` = 49 AND `app_msg_type` = 57)").
 		Find(&messages).
 		Order("id ASC").Error
 	if err != nil {
@@ -290,7 +302,11 @@ func (m *Message) GetLastMonthChatRommRank(self, chatRoomID string) ([]*dto.Chat
 	return chatRoomRank, nil
 }
 
+// Create 发送接口有时不返回消息ID，这时和 ToolsCompleted 一样生成一个，避免和其他消息的 msg_id 冲突
 func (m *Message) Create(data *model.Message) error {
+	if data.MsgId == 0 {
+		data.MsgId = time.Now().UnixNano() + rand.Int63n(1000)
+	}
 	return m.DB.WithContext(m.Ctx).Create(data).Error
 }
 
@@ -298,6 +314,11 @@ func (m *Message) Update(data *model.Message) error {
 	return m.DB.WithContext(m.Ctx).Where("id = ?", data.ID).Updates(data).Error
 }
 
+// UpdateTranscript 保存语音消息的转写文本
+func (m *Message) UpdateTranscript(id int64, transcript string) error {
+	return m.DB.WithContext(m.Ctx).Model(&model.Message{}).Where("id = ?", id).Update("transcript", transcript).Error
+}
+
 func (c *Message) Delete(data *model.Message) error {
 	return c.DB.WithContext(c.Ctx).Unscoped().Delete(data).Error
 }
@@ -400,7 +421,7 @@ func (m *Message) GetFriendTextMessagesInIDRange(contactWxID string, startMsgID,
 	query := m.DB.WithContext(m.Ctx).
 		Where("from_wxid = ?", contactWxID).
 		Where("id >= ? AND id <= ?", startMsgID, endMsgID).
-		Where("`type` = 1 AND content != ''").
+		Where("(`type` = 1 AND content != '') OR (`type` = 34 AND transcript != '')").
 		Order("id ASC")
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -418,7 +439,7 @@ func (m *Message) GetChatRoomTextMessagesInIDRangeExcludeSenders(chatRoomID stri
 	query := m.DB.WithContext(m.Ctx).
 		Where("from_wxid = ?", chatRoomID).
 		Where("id >= ? AND id <= ?", startMsgID, endMsgID).
-		Where("`type` = 1 AND content != ''").
+		Where("(`type` = 1 AND content != '') OR (`type` = 34 AND transcript != '')").
 		Order("id ASC")
 	if len(excludeWxIDs) > 0 {
 		query = query.Where("sender_wxid NOT IN ?", excludeWxIDs)
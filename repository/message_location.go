@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"wechat-robot-client/model"
+
+	"gorm.io/gorm"
+)
+
+type MessageLocation struct {
+	Ctx context.Context
+	DB  *gorm.DB
+}
+
+func NewMessageLocationRepo(ctx context.Context, db *gorm.DB) *MessageLocation {
+	return &MessageLocation{
+		Ctx: ctx,
+		DB:  db,
+	}
+}
+
+func (r *MessageLocation) Create(data *model.MessageLocation) error {
+	return r.DB.WithContext(r.Ctx).Create(data).Error
+}
+
+func (r *MessageLocation) GetByMessageID(messageID int64) (*model.MessageLocation, error) {
+	var location model.MessageLocation
+	err := r.DB.WithContext(r.Ctx).Where("message_id = ?", messageID).First(&location).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// GetLatest 获取会话中最近分享的位置，senderWxIDs 不为空时只查询这些成员分享的位置
+func (r *MessageLocation) GetLatest(fromWxID string, limit int, senderWxIDs ...string) ([]*model.MessageLocation, error) {
+	var locations []*model.MessageLocation
+	query := r.DB.WithContext(r.Ctx).Where("from_wxid = ?", fromWxID)
+	if len(senderWxIDs) > 0 {
+		query = query.Where("sender_wxid IN ?", senderWxIDs)
+	}
+	err := query.Order("created_at DESC").Order("id DESC").Limit(limit).Find(&locations).Error
+	return locations, err
+}
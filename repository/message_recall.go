@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+
+	"gorm.io/gorm"
+)
+
+type MessageRecall struct {
+	Ctx context.Context
+	DB  *gorm.DB
+}
+
+func NewMessageRecallRepo(ctx context.Context, db *gorm.DB) *MessageRecall {
+	return &MessageRecall{
+		Ctx: ctx,
+		DB:  db,
+	}
+}
+
+func (r *MessageRecall) Create(data *model.MessageRecall) error {
+	return r.DB.WithContext(r.Ctx).Create(data).Error
+}
+
+func (r *MessageRecall) SetNotified(id int64) error {
+	return r.DB.WithContext(r.Ctx).Model(&model.MessageRecall{}).Where("id = ?", id).Update("notified", true).Error
+}
+
+// GetByChatRoomID 分页获取群聊的撤回记录，附带发送者和撤回人的群昵称
+func (r *MessageRecall) GetByChatRoomID(req dto.RecalledMessageRequest, pager appx.Pager) ([]*dto.RecalledMessage, int64, error) {
+	var recalls []*dto.RecalledMessage
+	var total int64
+
+	query := r.DB.WithContext(r.Ctx).Model(&model.MessageRecall{}).Where("message_recalls.from_wxid = ?", req.ChatRoomID)
+	if req.SenderWxID != "" {
+		query = query.Where("message_recalls.sender_wxid = ?", req.SenderWxID)
+	}
+	if req.TimeStart > 0 {
+		query = query.Where("message_recalls.recalled_at >= ?", req.TimeStart)
+	}
+	if req.TimeEnd > 0 {
+		query = query.Where("message_recalls.recalled_at <= ?", req.TimeEnd)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Joins("LEFT JOIN chat_room_members AS sender ON sender.wechat_id = message_recalls.sender_wxid AND sender.chat_room_id = message_recalls.from_wxid").
+		Joins("LEFT JOIN chat_room_members AS recaller ON recaller.wechat_id = message_recalls.recalled_by AND recaller.chat_room_id = message_recalls.from_wxid").
+		Select("message_recalls.*, " +
+			"IF(sender.remark != '' AND sender.remark IS NOT NULL, sender.remark, sender.nickname) AS sender_nickname, " +
+			"IF(recaller.remark != '' AND recaller.remark IS NOT NULL, recaller.remark, recaller.nickname) AS recalled_by_nickname").
+		Order("message_recalls.recalled_at DESC").
+		Order("message_recalls.id DESC").
+		Offset(pager.OffSet).
+		Limit(pager.PageSize).
+		Find(&recalls).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return recalls, total, nil
+}
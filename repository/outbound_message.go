@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+
+	"gorm.io/gorm"
+)
+
+type OutboundMessage struct {
+	Ctx context.Context
+	DB  *gorm.DB
+}
+
+func NewOutboundMessageRepo(ctx context.Context, db *gorm.DB) *OutboundMessage {
+	return &OutboundMessage{
+		Ctx: ctx,
+		DB:  db,
+	}
+}
+
+func (r *OutboundMessage) Create(data *model.OutboundMessage) error {
+	return r.DB.WithContext(r.Ctx).Create(data).Error
+}
+
+func (r *OutboundMessage) Update(data *model.OutboundMessage) error {
+	return r.DB.WithContext(r.Ctx).Save(data).Error
+}
+
+func (r *OutboundMessage) GetByID(id int64) (*model.OutboundMessage, error) {
+	var message model.OutboundMessage
+	err := r.DB.WithContext(r.Ctx).Where("id = ?", id).First(&message).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// GetDueHeads 获取每个接收者最早一条未发送的消息中已到发送时间的，保证同一接收者的消息按顺序发送
+func (r *OutboundMessage) GetDueHeads(now int64, limit int) ([]*model.OutboundMessage, error) {
+	var messages []*model.OutboundMessage
+	heads := r.DB.Model(&model.OutboundMessage{}).
+		Select("MIN(id)").
+		Where("status IN ?", []model.OutboundMessageStatus{model.OutboundMessageStatusPending, model.OutboundMessageStatusSending}).
+		Group("to_wxid")
+	err := r.DB.WithContext(r.Ctx).
+		Where("id IN (?)", heads).
+		Where("status = ?", model.OutboundMessageStatusPending).
+		Where("next_attempt_at <= ?", now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// Claim 将待发送的消息标记为发送中，消息已被其他协程处理时返回 false
+func (r *OutboundMessage) Claim(data *model.OutboundMessage) (bool, error) {
+	result := r.DB.WithContext(r.Ctx).Model(&model.OutboundMessage{}).
+		Where("id = ? AND status = ? AND attempts = ?", data.ID, model.OutboundMessageStatusPending, data.Attempts).
+		Updates(map[string]any{
+			"status":   model.OutboundMessageStatusSending,
+			"attempts": data.Attempts + 1,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	data.Status = model.OutboundMessageStatusSending
+	data.Attempts++
+	return true, nil
+}
+
+// ResetSending 进程异常退出时可能残留发送中的消息，启动时重新放回待发送队列
+func (r *OutboundMessage) ResetSending() error {
+	return r.DB.WithContext(r.Ctx).Model(&model.OutboundMessage{}).
+		Where("status = ?", model.OutboundMessageStatusSending).
+		Update("status", model.OutboundMessageStatusPending).Error
+}
+
+func (r *OutboundMessage) GetList(req dto.OutboundMessageListRequest, pager appx.Pager) ([]*model.OutboundMessage, int64, error) {
+	var messages []*model.OutboundMessage
+	var total int64
+
+	status := req.Status
+	if status == "" {
+		status = string(model.OutboundMessageStatusDead)
+	}
+	query := r.DB.WithContext(r.Ctx).Model(&model.OutboundMessage{}).Where("status = ?", status)
+	if req.ToWxID != "" {
+		query = query.Where("to_wxid = ?", req.ToWxID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(pager.OffSet).Limit(pager.PageSize).Find(&messages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return messages, total, nil
+}
+
+// Redrive 将死信消息重新放回待发送队列，重置尝试次数
+func (r *OutboundMessage) Redrive(ids []int64, now int64) (int64, error) {
+	result := r.DB.WithContext(r.Ctx).Model(&model.OutboundMessage{}).
+		Where("id IN ? AND status = ?", ids, model.OutboundMessageStatusDead).
+		Updates(map[string]any{
+			"status":          model.OutboundMessageStatusPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *OutboundMessage) CreateAttempt(data *model.OutboundMessageAttempt) error {
+	return r.DB.WithContext(r.Ctx).Create(data).Error
+}
+
+func (r *OutboundMessage) GetAttempts(outboundMessageID int64) ([]*model.OutboundMessageAttempt, error) {
+	var attempts []*model.OutboundMessageAttempt
+	err := r.DB.WithContext(r.Ctx).Where("outbound_message_id = ?", outboundMessageID).Order("id ASC").Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+
+	"gorm.io/gorm"
+)
+
+type RobotSessionEvent struct {
+	Ctx context.Context
+	DB  *gorm.DB
+}
+
+func NewRobotSessionEventRepo(ctx context.Context, db *gorm.DB) *RobotSessionEvent {
+	return &RobotSessionEvent{
+		Ctx: ctx,
+		DB:  db,
+	}
+}
+
+func (r *RobotSessionEvent) Create(data *model.RobotSessionEvent) error {
+	return r.DB.WithContext(r.Ctx).Create(data).Error
+}
+
+func (r *RobotSessionEvent) GetList(req dto.RobotSessionHistoryRequest, pager appx.Pager) ([]*model.RobotSessionEvent, int64, error) {
+	var events []*model.RobotSessionEvent
+	var total int64
+
+	query := r.DB.WithContext(r.Ctx).Model(&model.RobotSessionEvent{})
+	if req.State != "" {
+		query = query.Where("to_state = ?", req.State)
+	}
+	if req.StartTime > 0 {
+		query = query.Where("created_at >= ?", req.StartTime)
+	}
+	if req.EndTime > 0 {
+		query = query.Where("created_at <= ?", req.EndTime)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(pager.OffSet).Limit(pager.PageSize).Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
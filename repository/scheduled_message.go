@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+
+	"gorm.io/gorm"
+)
+
+type ScheduledMessage struct {
+	Ctx context.Context
+	DB  *gorm.DB
+}
+
+func NewScheduledMessageRepo(ctx context.Context, db *gorm.DB) *ScheduledMessage {
+	return &ScheduledMessage{
+		Ctx: ctx,
+		DB:  db,
+	}
+}
+
+func (r *ScheduledMessage) Create(data *model.ScheduledMessage) error {
+	return r.DB.WithContext(r.Ctx).Create(data).Error
+}
+
+func (r *ScheduledMessage) Update(data *model.ScheduledMessage) error {
+	return r.DB.WithContext(r.Ctx).Save(data).Error
+}
+
+func (r *ScheduledMessage) GetByID(id int64) (*model.ScheduledMessage, error) {
+	var message model.ScheduledMessage
+	err := r.DB.WithContext(r.Ctx).Where("id = ?", id).First(&message).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *ScheduledMessage) GetAllEnabled() ([]*model.ScheduledMessage, error) {
+	var messages []*model.ScheduledMessage
+	err := r.DB.WithContext(r.Ctx).Where("enabled = ?", true).Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *ScheduledMessage) GetList(req dto.ScheduledMessageListRequest, pager appx.Pager) ([]*model.ScheduledMessage, int64, error) {
+	var messages []*model.ScheduledMessage
+	var total int64
+
+	query := r.DB.WithContext(r.Ctx).Model(&model.ScheduledMessage{})
+	if req.ScheduleType != "" {
+		query = query.Where("schedule_type = ?", req.ScheduleType)
+	}
+	if req.Enabled != nil {
+		query = query.Where("enabled = ?", *req.Enabled)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(pager.OffSet).Limit(pager.PageSize).Find(&messages).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return messages, total, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+
+	"gorm.io/gorm"
+)
+
+type ToolApproval struct {
+	Ctx context.Context
+	DB  *gorm.DB
+}
+
+func NewToolApprovalRepo(ctx context.Context, db *gorm.DB) *ToolApproval {
+	return &ToolApproval{
+		Ctx: ctx,
+		DB:  db,
+	}
+}
+
+func (r *ToolApproval) Create(data *model.ToolApproval) error {
+	return r.DB.WithContext(r.Ctx).Create(data).Error
+}
+
+func (r *ToolApproval) GetByID(id int64) (*model.ToolApproval, error) {
+	var approval model.ToolApproval
+	err := r.DB.WithContext(r.Ctx).Where("id = ?", id).First(&approval).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// GetPending 获取未过期的待审批记录，最新的在前
+func (r *ToolApproval) GetPending() ([]*model.ToolApproval, error) {
+	var approvals []*model.ToolApproval
+	err := r.DB.WithContext(r.Ctx).
+		Where("status = ? AND expires_at > ?", model.ToolApprovalStatusPending, time.Now().Unix()).
+		Order("id DESC").
+		Find(&approvals).Error
+	return approvals, err
+}
+
+func (r *ToolApproval) GetList(req dto.ToolApprovalListRequest, pager appx.Pager) ([]*model.ToolApproval, int64, error) {
+	var approvals []*model.ToolApproval
+	var total int64
+
+	query := r.DB.WithContext(r.Ctx).Model(&model.ToolApproval{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.ChatID != "" {
+		query = query.Where("chat_id = ?", req.ChatID)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset(pager.OffSet).Limit(pager.PageSize).Find(&approvals).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return approvals, total, nil
+}
+
+// Decide 只更新仍在等待审批的记录，返回是否更新成功，避免重复审批
+func (r *ToolApproval) Decide(id int64, status model.ToolApprovalStatus, decidedBy string) (bool, error) {
+	result := r.DB.WithContext(r.Ctx).Model(&model.ToolApproval{}).
+		Where("id = ? AND status = ?", id, model.ToolApprovalStatusPending).
+		Updates(map[string]any{
+			"status":     status,
+			"decided_by": decidedBy,
+			"decided_at": time.Now().Unix(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ExpireAll 将所有待审批的记录标记为超时，服务重启后等待审批的对话已经不存在了
+func (r *ToolApproval) ExpireAll() error {
+	return r.DB.WithContext(r.Ctx).Model(&model.ToolApproval{}).
+		Where("status = ?", model.ToolApprovalStatusPending).
+		Update("status", model.ToolApprovalStatusExpired).Error
+}
@@ -12,7 +12,14 @@ var attachDownloadCtl *controller.AttachDownload
 var chatRoomCtl *controller.ChatRoom
 var contactCtl *controller.Contact
 var loginCtl *controller.Login
+var robotSessionCtl *controller.RobotSession
 var messageCtl *controller.Message
+var outboundMessageCtl *controller.OutboundMessage
+var toolApprovalCtl *controller.ToolApproval
+var agentTraceCtl *controller.AgentTrace
+var aiUsageCtl *controller.AIUsage
+var aiProviderCtl *controller.AIProvider
+var scheduledMessageCtl *controller.ScheduledMessage
 var systemMessageCtl *controller.SystemMessage
 var globalSettingsCtl *controller.GlobalSettings
 var friendSettingsCtl *controller.FriendSettings
@@ -31,6 +38,7 @@ var knowledgeCategoryCtl *controller.KnowledgeCategory
 var systemPromptCtl *controller.SystemPrompt
 var officialAccountCtx *controller.OfficialAccount
 var wxAppCtl *controller.WXApp
+var pluginCtl *controller.Plugin
 
 func initController() {
 	chatHistoryCtl = controller.NewChatHistoryController()
@@ -38,7 +46,14 @@ func initController() {
 	chatRoomCtl = controller.NewChatRoomController()
 	contactCtl = controller.NewContactController()
 	loginCtl = controller.NewLoginController()
+	robotSessionCtl = controller.NewRobotSessionController()
 	messageCtl = controller.NewMessageController()
+	outboundMessageCtl = controller.NewOutboundMessageController()
+	toolApprovalCtl = controller.NewToolApprovalController()
+	agentTraceCtl = controller.NewAgentTraceController()
+	aiUsageCtl = controller.NewAIUsageController()
+	aiProviderCtl = controller.NewAIProviderController()
+	scheduledMessageCtl = controller.NewScheduledMessageController()
 	systemMessageCtl = controller.NewSystemMessageController()
 	globalSettingsCtl = controller.NewGlobalSettingsController()
 	friendSettingsCtl = controller.NewFriendSettingsController()
@@ -57,6 +72,7 @@ func initController() {
 	systemPromptCtl = controller.NewSystemPromptController()
 	officialAccountCtx = controller.NewOfficialAccountController()
 	wxAppCtl = controller.NewWXAppController()
+	pluginCtl = controller.NewPluginController()
 }
 
 func RegisterRouter(r *gin.Engine) error {
@@ -91,6 +107,9 @@ func RegisterRouter(r *gin.Engine) error {
 	api.POST("/robot/login/a16", loginCtl.LoginA16Data)
 	api.POST("/robot/login/set-proxy", loginCtl.SetProxy)
 	api.DELETE("/robot/logout", loginCtl.Logout)
+	api.GET("/robot/session/state", robotSessionCtl.GetState)
+	api.GET("/robot/session/history", robotSessionCtl.GetHistory)
+	api.POST("/robot/session/reconnect", robotSessionCtl.Reconnect)
 
 	// 联系人相关接口
 	api.GET("/robot/contacts", contactCtl.GetContacts)
@@ -119,6 +138,15 @@ func RegisterRouter(r *gin.Engine) error {
 
 	// 消息相关接口
 	api.POST("/robot/message/revoke", messageCtl.MessageRevoke)
+	api.GET("/robot/message/ingest-stats", messageCtl.GetIngestStats)
+	api.GET("/robot/message/queue-stats", messageCtl.GetQueueStats)
+	api.GET("/robot/message/outbound/list", outboundMessageCtl.GetList)
+	api.GET("/robot/message/outbound/attempts", outboundMessageCtl.GetAttempts)
+	api.POST("/robot/message/outbound/redrive", outboundMessageCtl.Redrive)
+	api.GET("/robot/message/scheduled/list", scheduledMessageCtl.GetList)
+	api.POST("/robot/message/scheduled", scheduledMessageCtl.Create)
+	api.PUT("/robot/message/scheduled", scheduledMessageCtl.Update)
+	api.POST("/robot/message/scheduled/cancel", scheduledMessageCtl.Cancel)
 	api.POST("/robot/message/send/text", messageCtl.SendTextMessage)
 	api.POST("/robot/message/send/longtext", messageCtl.SendLongTextMessage)
 	api.POST("/robot/message/send/masssend", messageCtl.SendGroupMassMsgText)
@@ -133,6 +161,8 @@ func RegisterRouter(r *gin.Engine) error {
 	api.POST("/robot/message/send/voice/local", messageCtl.SendVoiceMessageByLocalPath)
 	api.POST("/robot/message/send/music", messageCtl.SendMusicMessage)
 	api.POST("/robot/message/send/app", messageCtl.SendAppMessage)
+	api.POST("/robot/message/send/reply", messageCtl.SendReplyMessage)
+	api.POST("/robot/message/send/quote", messageCtl.SendQuoteMessage)
 	api.POST("/robot/message/send/emoji", messageCtl.SendEmojiMessage)
 	api.POST("/robot/message/send/file", messageCtl.SendFileMessage)
 	api.POST("/robot/message/send/file/local", messageCtl.SendFileMessageByLocalPath)
@@ -169,6 +199,25 @@ func RegisterRouter(r *gin.Engine) error {
 	api.DELETE("/robot/skill/uninstall", skillCtl.UninstallSkill)
 	api.POST("/robot/skill/env-vars", skillCtl.SetSkillEnvVars)
 
+	// AI 工具调用审批接口
+	api.GET("/robot/tool-approval/list", toolApprovalCtl.GetList)
+	api.POST("/robot/tool-approval/decide", toolApprovalCtl.Decide)
+
+	// AI 工具调用执行记录接口
+	api.GET("/robot/agent-trace/list", agentTraceCtl.GetList)
+	api.GET("/robot/agent-trace/detail", agentTraceCtl.GetDetail)
+	api.POST("/robot/agent-trace/replay", agentTraceCtl.Replay)
+
+	// AI 用量统计接口
+	api.GET("/robot/ai-usage/list", aiUsageCtl.GetList)
+	api.GET("/robot/ai-usage/daily-summary", aiUsageCtl.GetDailySummary)
+
+	// AI 服务商配置接口，模型路由通过名称引用服务商
+	api.GET("/robot/ai-providers", aiProviderCtl.GetAIProviders)
+	api.POST("/robot/ai-provider", aiProviderCtl.CreateAIProvider)
+	api.PUT("/robot/ai-provider", aiProviderCtl.UpdateAIProvider)
+	api.DELETE("/robot/ai-provider", aiProviderCtl.DeleteAIProvider)
+
 	// 系统提示词管理接口
 	api.GET("/robot/system-prompts", systemPromptCtl.List)
 	api.GET("/robot/system-prompt", systemPromptCtl.Get)
@@ -209,6 +258,7 @@ func RegisterRouter(r *gin.Engine) error {
 	api.GET("/robot/chat/video/download", attachDownloadCtl.DownloadVideo)
 	api.POST("/robot/chat/media/upload", attachDownloadCtl.UploadMedia)
 	api.GET("/robot/chat/history", chatHistoryCtl.GetChatHistory)
+	api.GET("/robot/chat/recalled-messages", chatHistoryCtl.GetRecalledMessages)
 
 	api.GET("/robot/global-settings", globalSettingsCtl.GetGlobalSettings)
 	api.POST("/robot/global-settings", globalSettingsCtl.SaveGlobalSettings)
@@ -216,6 +266,10 @@ func RegisterRouter(r *gin.Engine) error {
 	api.POST("/robot/friend-settings", friendSettingsCtl.SaveFriendSettings)
 	api.GET("/robot/chat-room-settings", chatRoomSettingsCtl.GetChatRoomSettings)
 	api.POST("/robot/chat-room-settings", chatRoomSettingsCtl.SaveChatRoomSettings)
+	api.GET("/robot/chat-room-settings/commands", chatRoomSettingsCtl.GetCommands)
+
+	api.GET("/robot/plugins", pluginCtl.GetPlugins)
+	api.POST("/robot/plugins/toggle", pluginCtl.TogglePlugin)
 
 	// 朋友圈接口
 	api.GET("/robot/moments/list", momentsCtl.FriendCircleGetList)
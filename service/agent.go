@@ -6,11 +6,15 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/openai/openai-go/v3"
 	"gorm.io/gorm"
 
+	"wechat-robot-client/dto"
 	"wechat-robot-client/interface/ai"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/airouter"
 	"wechat-robot-client/pkg/mcp"
 	openaitools "wechat-robot-client/pkg/openai_tools"
 	"wechat-robot-client/pkg/robotctx"
@@ -86,8 +90,16 @@ func (s *AgentService) GetSkillsManager() *skills.SkillsManager {
 	return s.skillsManager
 }
 
-// GetAllTools 获取所有可用工具（OpenAI格式）
+// GetAllTools 获取当前会话可用的工具（OpenAI格式），按工具调用策略过滤
 func (s *AgentService) GetAllTools(robotCtx *robotctx.RobotContext) ([]openai.ChatCompletionToolUnionParam, error) {
+	policy, err := s.resolveToolPolicy(robotCtx)
+	if err != nil {
+		return nil, err
+	}
+	return s.getTools(robotCtx, policy)
+}
+
+func (s *AgentService) getTools(robotCtx *robotctx.RobotContext, policy toolPolicy) ([]openai.ChatCompletionToolUnionParam, error) {
 	var tools []openai.ChatCompletionToolUnionParam
 	// 从内部工具管理器获取工具
 	internalTools := s.internalToolsManager.GetOpenAITools(robotCtx)
@@ -95,13 +107,13 @@ func (s *AgentService) GetAllTools(robotCtx *robotctx.RobotContext) ([]openai.Ch
 	// 从MCP获取工具
 	mcpTools, err := s.mcpManager.GetOpenAITools(s.ctx)
 	if err != nil {
-		return tools, err
+		return s.filterTools(policy, tools), err
 	}
 	tools = append(tools, mcpTools...)
 	// 从Skills获取工具
 	skillTools := s.skillsManager.GetOpenAITools()
 	tools = append(tools, skillTools...)
-	return tools, nil
+	return s.filterTools(policy, tools), nil
 }
 
 // BuildSystemPrompt 构建包含工具描述的系统提示词
@@ -129,23 +141,57 @@ func (s *AgentService) BuildSystemPrompt(ctx context.Context, robotCtx *robotctx
 
 func (s *AgentService) ChatWithTools(
 	robotCtx *robotctx.RobotContext,
-	client *openai.Client,
+	router *airouter.Router,
+	req openai.ChatCompletionNewParams,
+	streamHandler ai.ChatStreamHandler,
+) (ai.AgentReply, error) {
+	reply, _, err := s.runAgent(robotCtx, router, req, streamHandler, agentRunOptions{})
+	return reply, err
+}
+
+// agentRunOptions 回放历史记录时使用记录中的工具结果，不真正执行工具
+type agentRunOptions struct {
+	replayOf    int64
+	toolResults map[string]string
+}
+
+// runAgent 执行工具调用循环，执行过程记录到 agent_traces
+func (s *AgentService) runAgent(
+	robotCtx *robotctx.RobotContext,
+	router *airouter.Router,
 	req openai.ChatCompletionNewParams,
-) (openai.ChatCompletionMessage, error) {
+	streamHandler ai.ChatStreamHandler,
+	opts agentRunOptions,
+) (reply ai.AgentReply, trace *model.AgentTrace, err error) {
 	if len(req.Messages) == 0 {
-		return openai.ChatCompletionMessage{}, fmt.Errorf("messages cannot be empty")
+		return ai.AgentReply{}, nil, fmt.Errorf("messages cannot be empty")
 	}
-
-	// 获取所有可用工具
-	tools, err := s.GetAllTools(robotCtx)
+	recorder := newAgentTraceRecorder(robotCtx, req, opts.replayOf)
+	defer func() {
+		trace = recorder.finish(s.ctx, s.db, reply.Content, err)
+		recorder.recordUsage(s.ctx)
+	}()
+
+	// 获取当前会话可用的工具
+	policy, err := s.resolveToolPolicy(robotCtx)
 	if err != nil {
-		return openai.ChatCompletionMessage{}, fmt.Errorf("failed to get tools: %w", err)
+		return ai.AgentReply{}, nil, fmt.Errorf("failed to resolve tool policy: %w", err)
 	}
+	tools, err := s.getTools(robotCtx, policy)
+	if err != nil {
+		return ai.AgentReply{}, nil, fmt.Errorf("failed to get tools: %w", err)
+	}
+	recorder.setTools(tools)
 
 	// 如果没有可用工具，直接调用AI
 	if len(tools) == 0 {
-		msg, _, err := s.streamChatCompletion(client, req)
-		return msg, err
+		start := time.Now()
+		result, err := s.streamChatCompletion(router, req, streamHandler)
+		if err != nil {
+			return ai.AgentReply{}, nil, err
+		}
+		recorder.addStep(result, time.Since(start))
+		return ai.AgentReply{ChatCompletionMessage: result.msg}, nil, nil
 	}
 
 	req.Tools = tools
@@ -153,7 +199,7 @@ func (s *AgentService) ChatWithTools(
 	// 构建包含工具描述的系统提示词，追加到首条 system 消息或前置新消息
 	toolsPrompt, err := s.BuildSystemPrompt(s.ctx, robotCtx)
 	if err != nil {
-		return openai.ChatCompletionMessage{}, fmt.Errorf("failed to build system prompt: %w", err)
+		return ai.AgentReply{}, nil, fmt.Errorf("failed to build system prompt: %w", err)
 	}
 	if req.Messages[0].OfSystem != nil {
 		existing := req.Messages[0].OfSystem.Content.OfString.Value
@@ -164,14 +210,18 @@ func (s *AgentService) ChatWithTools(
 
 	for range vars.MaxToolsIterations {
 		// 调用AI
-		msg, reasoning, err := s.streamChatCompletion(client, req)
+		start := time.Now()
+		result, err := s.streamChatCompletion(router, req, streamHandler)
 		if err != nil {
-			return openai.ChatCompletionMessage{}, fmt.Errorf("failed to call ai: %w", err)
+			// 路由返回的错误已经是给用户看的提示，详细原因记录在日志中
+			return ai.AgentReply{}, nil, err
 		}
+		recorder.addStep(result, time.Since(start))
+		msg, reasoning := result.msg, result.reasoning
 
 		// 没有工具调用，返回结果
 		if len(msg.ToolCalls) == 0 {
-			return msg, nil
+			return ai.AgentReply{ChatCompletionMessage: msg}, nil, nil
 		}
 
 		asstParam := msg.ToParam()
@@ -182,15 +232,29 @@ func (s *AgentService) ChatWithTools(
 		}
 		req.Messages = append(req.Messages, asstParam)
 
+		if streamHandler != nil {
+			streamHandler.OnToolCalls()
+		}
+
 		// 执行所有工具调用
 		for _, tc := range msg.ToolCalls {
 			log.Printf("Executing tool: %s", tc.Function.Name)
 
 			var result string
 			var immediately bool
+			var structuredReply *dto.StructuredReply
 			var err error
-
-			if s.skillsManager.IsSkillTool(tc.Function.Name) {
+			start := time.Now()
+
+			if err = s.checkToolCall(policy, tc); err != nil {
+				// 不允许调用的工具，把拒绝原因返回给模型
+			} else if opts.toolResults != nil {
+				// 回放时使用历史记录中相同调用的结果
+				result = replayToolResult(opts.toolResults, tc)
+				immediately = result == vars.AIEnded || tc.Function.Name == openaitools.ReplyToolName
+			} else if err = s.waitForApproval(robotCtx, policy, tc); err != nil {
+				// 审批被拒绝或者超时，把原因返回给模型
+			} else if s.skillsManager.IsSkillTool(tc.Function.Name) {
 				// skill 工具调用
 				result, err = s.skillsManager.ExecuteToolCall(*robotCtx, tc)
 				immediately = result == vars.AIEnded || strings.HasSuffix(result, "\n"+vars.AIEnded)
@@ -200,6 +264,12 @@ func (s *AgentService) ChatWithTools(
 				if tc.Function.Name == "execute_skill_script" {
 					log.Printf("工具[%s]执行结果:\n%s\n", tc.Function.Name, result)
 				}
+			} else if tc.Function.Name == openaitools.ReplyToolName && s.internalToolsManager.IsOpenAITool(tc.Function.Name) {
+				// 结构化回复通过返回值交给调用方，执行记录中保存回复的 JSON
+				if structuredReply, err = openaitools.BuildReply(robotCtx, tc); err == nil {
+					replyBytes, _ := json.Marshal(structuredReply)
+					result, immediately = string(replyBytes), true
+				}
 			} else if s.internalToolsManager.IsOpenAITool(tc.Function.Name) {
 				// 内部工具调用
 				result, immediately, err = s.internalToolsManager.ExecuteToolCall(s.ctx, robotCtx, tc)
@@ -207,11 +277,12 @@ func (s *AgentService) ChatWithTools(
 				// MCP 工具调用
 				result, immediately, err = s.mcpManager.ExecuteToolCall(s.ctx, *robotCtx, tc)
 			}
+			recorder.addToolCall(tc, result, err, time.Since(start))
 
 			if err == nil {
 				// 工具调用结果立即返回
 				if immediately {
-					return openai.ChatCompletionMessage{Content: result}, nil
+					return ai.AgentReply{ChatCompletionMessage: openai.ChatCompletionMessage{Content: result}, Reply: structuredReply}, nil, nil
 				}
 				// 工具返回空结果时，补充默认提示，避免API报错
 				if result == "" {
@@ -228,42 +299,68 @@ func (s *AgentService) ChatWithTools(
 		}
 	}
 
-	return openai.ChatCompletionMessage{}, fmt.Errorf("max iterations reached without final answer")
+	return ai.AgentReply{}, nil, fmt.Errorf("max iterations reached without final answer")
+}
+
+// chatCompletionResult 一次模型调用的结果，reasoning 为累积的 reasoning_content（思考内容），用于回写给后续请求
+type chatCompletionResult struct {
+	msg       openai.ChatCompletionMessage
+	reasoning string
+	usage     openai.CompletionUsage
+	model     string
 }
 
-// streamChatCompletion 通过流式接口调用 AI 并用 accumulator 汇总完整消息。
-// 第二个返回值为累积的 reasoning_content（思考内容），用于回写给后续请求。
+// streamChatCompletion 通过模型路由流式调用 AI 并用 accumulator 汇总完整消息。
+// streamHandler 不为空时，回复内容的增量会实时回调给它，已经回调过内容的调用失败后不再切换模型，避免重复发送。
 func (s *AgentService) streamChatCompletion(
-	client *openai.Client,
+	router *airouter.Router,
 	req openai.ChatCompletionNewParams,
-) (openai.ChatCompletionMessage, string, error) {
-	stream := client.Chat.Completions.NewStreaming(s.ctx, req)
-	acc := openai.ChatCompletionAccumulator{}
-	var reasoningSB strings.Builder
-	for stream.Next() {
-		chunk := stream.Current()
-		acc.AddChunk(chunk)
-		// openai-go v3 SDK 没有 reasoning_content 字段，从 ExtraFields 原始 JSON 中提取
-		if len(chunk.Choices) > 0 {
-			if rcField, ok := chunk.Choices[0].Delta.JSON.ExtraFields["reasoning_content"]; ok {
-				raw := rcField.Raw()
-				if raw != "" && raw != "null" {
-					var rc string
-					if err := json.Unmarshal([]byte(raw), &rc); err == nil {
-						reasoningSB.WriteString(rc)
+	streamHandler ai.ChatStreamHandler,
+) (chatCompletionResult, error) {
+	// 流式接口默认不返回 token 用量
+	req.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	var result chatCompletionResult
+	err := router.Do(s.ctx, airouter.NewRequest(req.Messages, len(req.Tools) > 0), func(client *openai.Client, endpoint airouter.Endpoint) error {
+		req.Model = endpoint.Model
+		stream := client.Chat.Completions.NewStreaming(s.ctx, req)
+		acc := openai.ChatCompletionAccumulator{}
+		var reasoningSB strings.Builder
+		var streamed bool
+		for stream.Next() {
+			chunk := stream.Current()
+			acc.AddChunk(chunk)
+			if streamHandler != nil && len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+				streamed = true
+				streamHandler.OnContent(chunk.Choices[0].Delta.Content)
+			}
+			// openai-go v3 SDK 没有 reasoning_content 字段，从 ExtraFields 原始 JSON 中提取
+			if len(chunk.Choices) > 0 {
+				if rcField, ok := chunk.Choices[0].Delta.JSON.ExtraFields["reasoning_content"]; ok {
+					raw := rcField.Raw()
+					if raw != "" && raw != "null" {
+						var rc string
+						if err := json.Unmarshal([]byte(raw), &rc); err == nil {
+							reasoningSB.WriteString(rc)
+						}
 					}
 				}
 			}
 		}
-	}
-	if err := stream.Err(); err != nil {
-		return openai.ChatCompletionMessage{}, "", fmt.Errorf("stream error: %w", err)
-	}
-	if len(acc.Choices) == 0 {
-		return openai.ChatCompletionMessage{}, "", fmt.Errorf("no choices in response")
-	}
-	msg := acc.Choices[0].Message
-	log.Printf("Stream finished with reason: %s, toolCalls: %d, content length: %d, reasoning length: %d",
-		acc.Choices[0].FinishReason, len(msg.ToolCalls), len(msg.Content), reasoningSB.Len())
-	return msg, reasoningSB.String(), nil
+		if err := stream.Err(); err != nil {
+			err = fmt.Errorf("stream error: %w", err)
+			if streamed {
+				return airouter.NoFallback(err)
+			}
+			return err
+		}
+		if len(acc.Choices) == 0 {
+			return fmt.Errorf("no choices in response")
+		}
+		msg := acc.Choices[0].Message
+		log.Printf("Stream finished with model: %s, reason: %s, toolCalls: %d, content length: %d, reasoning length: %d",
+			endpoint, acc.Choices[0].FinishReason, len(msg.ToolCalls), len(msg.Content), reasoningSB.Len())
+		result = chatCompletionResult{msg: msg, reasoning: reasoningSB.String(), usage: acc.Usage, model: endpoint.Model}
+		return nil
+	})
+	return result, err
 }
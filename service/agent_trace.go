@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"gorm.io/gorm"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/airouter"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/pkg/robotctx"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/utils"
+	"wechat-robot-client/vars"
+)
+
+// agentTraceRecorder 记录一次工具调用对话的执行过程，结束时写入数据库
+type agentTraceRecorder struct {
+	trace model.AgentTrace
+	steps []model.AgentTraceStep
+	start time.Time
+}
+
+func newAgentTraceRecorder(robotCtx *robotctx.RobotContext, req openai.ChatCompletionNewParams, replayOf int64) *agentTraceRecorder {
+	r := &agentTraceRecorder{
+		trace: model.AgentTrace{
+			Model:    string(req.Model),
+			ReplayOf: replayOf,
+		},
+		start: time.Now(),
+	}
+	if robotCtx != nil {
+		r.trace.MessageID = robotCtx.MessageID
+		r.trace.ChatID = robotCtx.FromWxID
+		r.trace.SenderWxID = robotCtx.SenderWxID
+	}
+	input, err := json.Marshal(req.Messages)
+	if err != nil {
+		log.Printf("[AgentTrace] 序列化输入消息失败: %v", err)
+	} else {
+		r.trace.Input = input
+	}
+	return r
+}
+
+func (r *agentTraceRecorder) setTools(tools []openai.ChatCompletionToolUnionParam) {
+	names := make([]string, 0, len(tools))
+	for _, tool := range tools {
+		if fn := tool.GetFunction(); fn != nil {
+			names = append(names, fn.Name)
+		}
+	}
+	if data, err := json.Marshal(names); err == nil {
+		r.trace.Tools = data
+	}
+}
+
+func (r *agentTraceRecorder) addStep(result chatCompletionResult, latency time.Duration) {
+	r.steps = append(r.steps, model.AgentTraceStep{
+		Model:            result.model,
+		Content:          result.msg.Content,
+		Reasoning:        result.reasoning,
+		PromptTokens:     result.usage.PromptTokens,
+		CompletionTokens: result.usage.CompletionTokens,
+		LatencyMs:        latency.Milliseconds(),
+	})
+	r.trace.PromptTokens += result.usage.PromptTokens
+	r.trace.CompletionTokens += result.usage.CompletionTokens
+	r.trace.TotalTokens += result.usage.TotalTokens
+}
+
+func (r *agentTraceRecorder) addToolCall(toolCall openai.ChatCompletionMessageToolCallUnion, result string, err error, latency time.Duration) {
+	if len(r.steps) == 0 {
+		return
+	}
+	call := model.AgentTraceToolCall{
+		ID:        toolCall.ID,
+		Name:      toolCall.Function.Name,
+		Arguments: toolCall.Function.Arguments,
+		Result:    result,
+		LatencyMs: latency.Milliseconds(),
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	step := &r.steps[len(r.steps)-1]
+	step.ToolCalls = append(step.ToolCalls, call)
+}
+
+// finish 保存执行记录，db 为空时只返回记录不入库
+func (r *agentTraceRecorder) finish(ctx context.Context, db *gorm.DB, answer string, err error) *model.AgentTrace {
+	r.trace.Answer = answer
+	r.trace.DurationMs = time.Since(r.start).Milliseconds()
+	r.trace.Status = model.AgentTraceStatusSuccess
+	if err != nil {
+		r.trace.Status = model.AgentTraceStatusFailed
+		r.trace.Error = err.Error()
+	}
+	if steps, err := json.Marshal(r.steps); err == nil {
+		r.trace.Steps = steps
+	}
+	if db == nil {
+		return &r.trace
+	}
+	if err := repository.NewAgentTraceRepo(ctx, db).Create(&r.trace); err != nil {
+		log.Printf("[AgentTrace] 保存执行记录失败: %v", err)
+	}
+	return &r.trace
+}
+
+// recordUsage 把本次执行的 token 用量计入 AI 用量统计，一次执行计一次调用，模型按最后一次实际调用的模型统计
+func (r *agentTraceRecorder) recordUsage(ctx context.Context) {
+	if len(r.steps) == 0 {
+		return
+	}
+	modelName := r.steps[len(r.steps)-1].Model
+	if modelName == "" {
+		modelName = r.trace.Model
+	}
+	feature := model.AIUsageFeatureChat
+	if r.trace.ReplayOf > 0 {
+		feature = model.AIUsageFeatureReplay
+	}
+	scope := aiUsageScope{chatID: r.trace.ChatID, senderWxID: r.trace.SenderWxID, feature: feature}
+	recordAIUsage(ctx, scope, modelName, 1, openai.CompletionUsage{
+		PromptTokens:     r.trace.PromptTokens,
+		CompletionTokens: r.trace.CompletionTokens,
+		TotalTokens:      r.trace.TotalTokens,
+	})
+}
+
+// toolResultKey 工具名称加上规范化的参数，不同模型输出的参数格式可能不一样
+func toolResultKey(name, arguments string) string {
+	var args any
+	if err := json.Unmarshal([]byte(arguments), &args); err == nil {
+		if normalized, err := json.Marshal(args); err == nil {
+			arguments = string(normalized)
+		}
+	}
+	return name + "\x00" + strings.TrimSpace(arguments)
+}
+
+// replayToolResult 回放时返回历史记录中相同调用的结果
+func replayToolResult(results map[string]string, toolCall openai.ChatCompletionMessageToolCallUnion) string {
+	if result, ok := results[toolResultKey(toolCall.Function.Name, toolCall.Function.Arguments)]; ok {
+		return result
+	}
+	return "回放模式下不会真正执行工具，历史记录中没有相同参数的调用结果。"
+}
+
+type AgentTraceService struct {
+	ctx       context.Context
+	traceRepo *repository.AgentTrace
+}
+
+func NewAgentTraceService(ctx context.Context) *AgentTraceService {
+	return &AgentTraceService{
+		ctx:       ctx,
+		traceRepo: repository.NewAgentTraceRepo(ctx, vars.DB),
+	}
+}
+
+func (s *AgentTraceService) GetList(req dto.AgentTraceListRequest, pager appx.Pager) ([]*model.AgentTrace, int64, error) {
+	return s.traceRepo.GetList(req, pager)
+}
+
+func (s *AgentTraceService) GetDetail(id int64) (*model.AgentTrace, error) {
+	trace, err := s.traceRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if trace == nil {
+		return nil, errors.New("执行记录不存在")
+	}
+	return trace, nil
+}
+
+// CleanupExpired 删除超过保留天数的执行记录
+func (s *AgentTraceService) CleanupExpired(retentionDays int) (int64, error) {
+	before := time.Now().AddDate(0, 0, -retentionDays).Unix()
+	return s.traceRepo.DeleteBefore(before, 500)
+}
+
+// Replay 用历史记录的输入重新执行一次，工具调用使用历史记录中的结果，不会产生副作用
+func (s *AgentTraceService) Replay(req dto.AgentTraceReplayRequest) (*model.AgentTrace, error) {
+	trace, err := s.GetDetail(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	agent, ok := vars.Agent.(*AgentService)
+	if !ok || agent == nil {
+		return nil, errors.New("AI 服务还没有初始化")
+	}
+	var messages []openai.ChatCompletionMessageParamUnion
+	if err := json.Unmarshal(trace.Input, &messages); err != nil {
+		return nil, errors.New("解析输入消息失败: " + err.Error())
+	}
+	var steps []model.AgentTraceStep
+	if len(trace.Steps) > 0 {
+		if err := json.Unmarshal(trace.Steps, &steps); err != nil {
+			return nil, errors.New("解析执行过程失败: " + err.Error())
+		}
+	}
+	toolResults := make(map[string]string)
+	for _, step := range steps {
+		for _, call := range step.ToolCalls {
+			result := call.Result
+			if call.Error != "" {
+				result = call.Error
+			}
+			toolResults[toolResultKey(call.Name, call.Arguments)] = result
+		}
+	}
+
+	globalSettings, err := repository.NewGlobalSettingsRepo(s.ctx, vars.DB).GetGlobalSettings()
+	if err != nil {
+		return nil, err
+	}
+	if globalSettings == nil {
+		return nil, errors.New("全局配置不存在")
+	}
+	modelName := req.Model
+	if modelName == "" {
+		modelName = trace.Model
+	}
+	// 回放只使用指定的模型，不切换备用模型
+	router := airouter.Single(airouter.Endpoint{
+		BaseURL: utils.NormalizeAIBaseURL(globalSettings.ChatBaseURL),
+		APIKey:  globalSettings.ChatAPIKey,
+		Model:   modelName,
+	})
+	robotCtx := &robotctx.RobotContext{
+		RobotID:    vars.RobotRuntime.RobotID,
+		RobotCode:  vars.RobotRuntime.RobotCode,
+		RobotWxID:  vars.RobotRuntime.WxID,
+		FromWxID:   trace.ChatID,
+		SenderWxID: trace.SenderWxID,
+		MessageID:  trace.MessageID,
+	}
+	_, replay, err := agent.runAgent(robotCtx, router, openai.ChatCompletionNewParams{
+		Model:    modelName,
+		Messages: messages,
+	}, nil, agentRunOptions{replayOf: trace.ID, toolResults: toolResults})
+	if replay == nil {
+		return nil, err
+	}
+	// 执行失败的原因记录在回放记录中
+	return replay, nil
+}
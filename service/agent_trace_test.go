@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/robotctx"
+)
+
+func TestReplayToolResult(t *testing.T) {
+	results := map[string]string{
+		toolResultKey("get_weather", `{"city": "上海", "days": 3}`): "晴",
+	}
+	toolCall := openai.ChatCompletionMessageToolCallUnion{}
+	toolCall.Function.Name = "get_weather"
+	toolCall.Function.Arguments = `{"days":3,"city":"上海"}`
+	if result := replayToolResult(results, toolCall); result != "晴" {
+		t.Errorf("expected recorded result, got %q", result)
+	}
+	toolCall.Function.Arguments = `{"city":"北京"}`
+	if result := replayToolResult(results, toolCall); result == "晴" {
+		t.Errorf("expected fallback result for different arguments")
+	}
+}
+
+func TestAgentTraceRecorder(t *testing.T) {
+	robotCtx := &robotctx.RobotContext{MessageID: 10, FromWxID: "123@chatroom", SenderWxID: "wxid_a"}
+	recorder := newAgentTraceRecorder(robotCtx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("你好")},
+	}, 0)
+	toolCall := openai.ChatCompletionMessageToolCallUnion{ID: "call_1"}
+	toolCall.Function.Name = "get_weather"
+	recorder.addStep(chatCompletionResult{model: "gpt-4o", usage: openai.CompletionUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}}, time.Second)
+	recorder.addToolCall(toolCall, "", errors.New("timeout"), time.Second)
+	recorder.addStep(chatCompletionResult{
+		msg:   openai.ChatCompletionMessage{Content: "晴"},
+		model: "gpt-4o-mini",
+		usage: openai.CompletionUsage{PromptTokens: 20, CompletionTokens: 2, TotalTokens: 22},
+	}, time.Second)
+
+	trace := recorder.finish(context.Background(), nil, "晴", nil)
+	if trace.MessageID != 10 || trace.ChatID != "123@chatroom" || trace.Model != "gpt-4o" {
+		t.Errorf("unexpected trace header: %+v", trace)
+	}
+	if trace.Status != model.AgentTraceStatusSuccess || trace.TotalTokens != 37 || trace.PromptTokens != 30 {
+		t.Errorf("unexpected trace summary: status=%s total=%d prompt=%d", trace.Status, trace.TotalTokens, trace.PromptTokens)
+	}
+	var steps []model.AgentTraceStep
+	if err := json.Unmarshal(trace.Steps, &steps); err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || len(steps[0].ToolCalls) != 1 || steps[0].ToolCalls[0].Error != "timeout" || steps[1].Model != "gpt-4o-mini" {
+		t.Errorf("unexpected steps: %+v", steps)
+	}
+	var input []openai.ChatCompletionMessageParamUnion
+	if err := json.Unmarshal(trace.Input, &input); err != nil || len(input) != 1 {
+		t.Errorf("input should round trip, got %d messages, err=%v", len(input), err)
+	}
+}
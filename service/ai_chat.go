@@ -8,8 +8,8 @@ import (
 	"time"
 
 	"github.com/openai/openai-go/v3"
-	"github.com/openai/openai-go/v3/option"
 
+	"wechat-robot-client/interface/ai"
 	"wechat-robot-client/interface/settings"
 	"wechat-robot-client/pkg/robotctx"
 	"wechat-robot-client/repository"
@@ -28,7 +28,12 @@ func NewAIChatService(ctx context.Context, config settings.Settings) *AIChatServ
 	}
 }
 
-func (s *AIChatService) Chat(robotCtx robotctx.RobotContext, aiMessages []openai.ChatCompletionMessageParamUnion) (openai.ChatCompletionMessage, error) {
+func (s *AIChatService) Chat(robotCtx robotctx.RobotContext, aiMessages []openai.ChatCompletionMessageParamUnion) (ai.AgentReply, error) {
+	return s.ChatStream(robotCtx, aiMessages, nil)
+}
+
+// ChatStream 和 Chat 相同，streamHandler 不为空时实时接收回复内容的增量
+func (s *AIChatService) ChatStream(robotCtx robotctx.RobotContext, aiMessages []openai.ChatCompletionMessageParamUnion, streamHandler ai.ChatStreamHandler) (ai.AgentReply, error) {
 	// 获取 AI 配置
 	aiConfig := s.config.GetAIConfig()
 
@@ -69,18 +74,26 @@ func (s *AIChatService) Chat(robotCtx robotctx.RobotContext, aiMessages []openai
 	// 群友单独的对话记录
 	aiMessages = append(systemMessages, aiMessages...)
 
-	client := openai.NewClient(
-		option.WithAPIKey(aiConfig.APIKey),
-		option.WithBaseURL(aiConfig.BaseURL),
-	)
+	router := newAIRouter(s.ctx, aiConfig.BaseURL, aiConfig.APIKey, aiConfig.Model)
 	req := openai.ChatCompletionNewParams{
 		Model:    aiConfig.Model,
 		Messages: aiMessages,
 	}
 
+	// 超出今天的 AI 对话额度时，礼貌地拒绝
+	usageService := NewAIUsageService(s.ctx)
+	score, err := usageService.CheckQuota(robotCtx.FromWxID, robotCtx.SenderWxID)
+	if err != nil {
+		return ai.AgentReply{}, err
+	}
+
 	aiStart := time.Now()
-	reply, err := vars.Agent.ChatWithTools(&robotCtx, &client, req)
+	reply, err := vars.Agent.ChatWithTools(&robotCtx, router, req, streamHandler)
 	log.Printf("[AI] 接口调用耗时: %v", time.Since(aiStart))
+	if err != nil {
+		// 对话失败时退还扣减的积分
+		usageService.RefundScore(robotCtx.FromWxID, robotCtx.SenderWxID, score)
+	}
 
 	return reply, err
 }
@@ -26,6 +26,15 @@ func NewAIMomentService(ctx context.Context) *AIMomentService {
 	}
 }
 
+// usageContext 朋友圈相关的 AI 调用计入 moment 功能的用量
+func (s *AIMomentService) usageContext() context.Context {
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return withAIUsageScope(ctx, "", "", model.AIUsageFeatureMoment)
+}
+
 func (s *AIMomentService) UnderstandImage(imageURLs []string, momentSettings model.MomentSettings) (openai.ChatCompletionMessage, error) {
 	if len(imageURLs) == 0 {
 		return openai.ChatCompletionMessage{}, fmt.Errorf("缺少图片地址")
@@ -52,12 +61,9 @@ func (s *AIMomentService) UnderstandImage(imageURLs []string, momentSettings mod
 			openai.UserMessage(parts),
 		},
 	}
-	client := newOpenAIClient(momentSettings.AIAPIKey, momentSettings.AIBaseURL)
-	ctx := s.ctx
-	if ctx == nil {
-		ctx = context.Background()
-	}
-	assistantMsg, err := streamChatCompletionMessage(ctx, &client, req)
+	ctx := s.usageContext()
+	router := newAIRouter(ctx, momentSettings.AIBaseURL, momentSettings.AIAPIKey, req.Model)
+	assistantMsg, err := streamChatCompletionMessage(ctx, router, req)
 	if err != nil {
 		return openai.ChatCompletionMessage{}, err
 	}
@@ -82,12 +88,9 @@ func (s *AIMomentService) UnderstandVideo(videoURL string, momentSettings model.
 			openai.UserMessage(fmt.Sprintf("请理解这个朋友圈视频内容，输出一段简洁、客观的中文描述。\n视频链接：%s", videoURL)),
 		},
 	}
-	client := newOpenAIClient(momentSettings.AIAPIKey, momentSettings.AIBaseURL)
-	ctx := s.ctx
-	if ctx == nil {
-		ctx = context.Background()
-	}
-	assistantMsg, err := streamChatCompletionMessage(ctx, &client, req)
+	ctx := s.usageContext()
+	router := newAIRouter(ctx, momentSettings.AIBaseURL, momentSettings.AIAPIKey, req.Model)
+	assistantMsg, err := streamChatCompletionMessage(ctx, router, req)
 	if err != nil {
 		return openai.ChatCompletionMessage{}, err
 	}
@@ -98,7 +101,8 @@ func (s *AIMomentService) UnderstandVideo(videoURL string, momentSettings model.
 }
 
 func (s *AIMomentService) GetMomentMood(content string, momentSettings model.MomentSettings) *MomentMood {
-	client := newOpenAIClient(momentSettings.AIAPIKey, momentSettings.AIBaseURL)
+	ctx := s.usageContext()
+	router := newAIRouter(ctx, momentSettings.AIBaseURL, momentSettings.AIAPIKey, momentSettings.WorkflowModel)
 
 	aiMessages := []openai.ChatCompletionMessageParamUnion{
 		openai.SystemMessage(`朋友在社交平台上发了一条动态，请根据动态内容，判断这条动态是否适合点赞和评论：
@@ -130,8 +134,8 @@ func (s *AIMomentService) GetMomentMood(content string, momentSettings model.Mom
 	}
 
 	msg, err := streamChatCompletionMessage(
-		context.Background(),
-		&client,
+		ctx,
+		router,
 		openai.ChatCompletionNewParams{
 			Model:    momentSettings.WorkflowModel,
 			Messages: aiMessages,
@@ -169,13 +173,14 @@ func (s *AIMomentService) Comment(content string, momentSettings model.MomentSet
 		openai.UserMessage(content),
 	}
 
-	client := newOpenAIClient(momentSettings.AIAPIKey, momentSettings.AIBaseURL)
 	req := openai.ChatCompletionNewParams{
 		Model:    momentSettings.CommentModel,
 		Messages: aiMessages,
 	}
 
-	assistantMsg, err := streamChatCompletionMessage(context.Background(), &client, req)
+	ctx := s.usageContext()
+	router := newAIRouter(ctx, momentSettings.AIBaseURL, momentSettings.AIAPIKey, req.Model)
+	assistantMsg, err := streamChatCompletionMessage(ctx, router, req)
 	if err != nil {
 		return openai.ChatCompletionMessage{}, err
 	}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/airouter"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/utils"
+	"wechat-robot-client/vars"
+)
+
+// newAIRouter 按全局的模型路由配置构建路由，首选模型使用各功能自己的配置，路由配置加载失败时只使用首选模型
+func newAIRouter(ctx context.Context, baseURL, apiKey, modelName string) *airouter.Router {
+	primary := airouter.Endpoint{
+		BaseURL: utils.NormalizeAIBaseURL(baseURL),
+		APIKey:  apiKey,
+		Model:   modelName,
+	}
+	router := airouter.Single(primary)
+	if vars.DB == nil {
+		return router
+	}
+	globalSettings, err := repository.NewGlobalSettingsRepo(ctx, vars.DB).GetGlobalSettings()
+	if err != nil {
+		log.Printf("[AIRouter] 获取全局配置失败: %v", err)
+		return router
+	}
+	if globalSettings == nil {
+		return router
+	}
+	routing, err := model.ParseAIRouting(globalSettings.AIRouting)
+	if err != nil {
+		log.Printf("[AIRouter] ai_routing 格式错误: %v", err)
+		return router
+	}
+	if routing == nil {
+		return router
+	}
+	providers, err := repository.NewAIProviderRepo(ctx, vars.DB).GetEnabled()
+	if err != nil {
+		log.Printf("[AIRouter] 获取AI服务商失败: %v", err)
+		return router
+	}
+	return buildAIRouter(primary, routing, providers)
+}
+
+// buildAIRouter 把路由配置中的服务商名称解析为地址和密钥，服务商不存在或者未启用的模型会被忽略
+func buildAIRouter(primary airouter.Endpoint, routing *model.AIRouting, providers []*model.AIProvider) *airouter.Router {
+	providerMap := make(map[string]*model.AIProvider, len(providers))
+	for _, provider := range providers {
+		providerMap[provider.Name] = provider
+	}
+	resolve := func(target *model.AIRouteTarget) *airouter.Endpoint {
+		if target == nil || target.Model == "" {
+			return nil
+		}
+		if target.Provider == "" {
+			return &airouter.Endpoint{BaseURL: primary.BaseURL, APIKey: primary.APIKey, Model: target.Model}
+		}
+		provider, ok := providerMap[target.Provider]
+		if !ok {
+			log.Printf("[AIRouter] 服务商 %s 不存在或者未启用，忽略模型 %s", target.Provider, target.Model)
+			return nil
+		}
+		return &airouter.Endpoint{
+			Provider: provider.Name,
+			BaseURL:  utils.NormalizeAIBaseURL(provider.BaseURL),
+			APIKey:   provider.APIKey,
+			Model:    target.Model,
+		}
+	}
+
+	router := airouter.Single(primary)
+	for i := range routing.Fallbacks {
+		if endpoint := resolve(&routing.Fallbacks[i]); endpoint != nil {
+			router.Fallbacks = append(router.Fallbacks, *endpoint)
+		}
+	}
+	router.Vision = resolve(routing.Vision)
+	router.Tools = resolve(routing.Tools)
+	router.LongContext = resolve(routing.LongContext)
+	router.LongContextChars = routing.LongContextChars
+	if routing.MaxRetries != nil {
+		router.MaxRetries = *routing.MaxRetries
+	}
+	if routing.RetryBackoff > 0 {
+		router.Backoff = time.Duration(routing.RetryBackoff) * time.Millisecond
+	}
+	return router
+}
+
+// validateAIRouting 保存配置前检查模型路由的格式
+func validateAIRouting(data datatypes.JSON) error {
+	routing, err := model.ParseAIRouting(data)
+	if err != nil {
+		return fmt.Errorf("ai_routing 格式错误: %w", err)
+	}
+	if routing == nil {
+		return nil
+	}
+	for _, target := range routing.Targets() {
+		if strings.TrimSpace(target.Model) == "" {
+			return errors.New("ai_routing 格式错误: 模型名称不能为空")
+		}
+	}
+	if routing.MaxRetries != nil && *routing.MaxRetries < 0 {
+		return errors.New("ai_routing 格式错误: 重试次数不能为负数")
+	}
+	if routing.LongContext != nil && routing.LongContextChars <= 0 {
+		return errors.New("ai_routing 格式错误: 配置了超长输入的模型时，必须设置 long_context_chars")
+	}
+	return nil
+}
+
+type AIProviderService struct {
+	ctx          context.Context
+	providerRepo *repository.AIProvider
+}
+
+func NewAIProviderService(ctx context.Context) *AIProviderService {
+	return &AIProviderService{
+		ctx:          ctx,
+		providerRepo: repository.NewAIProviderRepo(ctx, vars.DB),
+	}
+}
+
+func (s *AIProviderService) GetAIProviders() ([]*model.AIProvider, error) {
+	return s.providerRepo.GetList()
+}
+
+func (s *AIProviderService) validateAIProvider(provider *model.AIProvider) error {
+	provider.Name = strings.TrimSpace(provider.Name)
+	provider.BaseURL = strings.TrimSpace(provider.BaseURL)
+	if provider.Name == "" {
+		return errors.New("服务商名称不能为空")
+	}
+	if provider.BaseURL == "" {
+		return errors.New("服务商地址不能为空")
+	}
+	existing, err := s.providerRepo.GetByName(provider.Name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != provider.ID {
+		return fmt.Errorf("服务商 %s 已经存在", provider.Name)
+	}
+	return nil
+}
+
+func (s *AIProviderService) CreateAIProvider(provider *model.AIProvider) error {
+	provider.ID = 0
+	if err := s.validateAIProvider(provider); err != nil {
+		return err
+	}
+	return s.providerRepo.Create(provider)
+}
+
+func (s *AIProviderService) UpdateAIProvider(provider *model.AIProvider) error {
+	if provider.ID == 0 {
+		return errors.New("参数异常")
+	}
+	current, err := s.providerRepo.GetByID(provider.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("服务商不存在")
+	}
+	if err := s.validateAIProvider(provider); err != nil {
+		return err
+	}
+	if current.Name != provider.Name {
+		if err := s.checkNotReferenced(current.Name); err != nil {
+			return err
+		}
+	}
+	return s.providerRepo.Update(provider)
+}
+
+// DeleteAIProvider 模型路由还在使用的服务商不允许删除
+func (s *AIProviderService) DeleteAIProvider(id int64) error {
+	current, err := s.providerRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errors.New("服务商不存在")
+	}
+	if err := s.checkNotReferenced(current.Name); err != nil {
+		return err
+	}
+	return s.providerRepo.Delete(id)
+}
+
+func (s *AIProviderService) checkNotReferenced(name string) error {
+	globalSettings, err := repository.NewGlobalSettingsRepo(s.ctx, vars.DB).GetGlobalSettings()
+	if err != nil {
+		return err
+	}
+	if globalSettings == nil {
+		return nil
+	}
+	routing, err := model.ParseAIRouting(globalSettings.AIRouting)
+	if err != nil || routing == nil {
+		return nil
+	}
+	for _, target := range routing.Targets() {
+		if target.Provider == name {
+			return fmt.Errorf("模型路由正在使用服务商 %s，请先修改模型路由配置", name)
+		}
+	}
+	return nil
+}
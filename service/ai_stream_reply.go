@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/interface/ai"
+	"wechat-robot-client/model"
+	"wechat-robot-client/vars"
+)
+
+const aiStreamPlaceholderText = "思考中…"
+
+var streamThinkTagRegexp = regexp.MustCompile(`(?s)<think>.*?</think>|<thinking>.*?</thinking>`)
+
+// replyChunker 将流式输出的回复切分成完整的句子或段落
+type replyChunker struct {
+	minSize int
+	buf     string
+}
+
+// thinkHoldIndex 返回未闭合的思维链标签的起始位置，标签之后的内容需要等待闭合后再处理
+func thinkHoldIndex(text string) int {
+	for _, tag := range []string{"<think>", "<thinking>"} {
+		if index := strings.LastIndex(text, tag); index >= 0 {
+			return index
+		}
+	}
+	// 末尾可能是尚未输出完整的标签
+	if index := strings.LastIndex(text, "<"); index >= 0 && strings.HasPrefix("<thinking>", text[index:]) {
+		return index
+	}
+	return len(text)
+}
+
+func (c *replyChunker) append(delta string) {
+	c.buf += delta
+	c.buf = streamThinkTagRegexp.ReplaceAllString(c.buf, "")
+}
+
+// next 返回可以发送的分段，在最后一个句子结尾处切分，不足最少字数时返回空
+func (c *replyChunker) next() string {
+	safe := c.buf[:thinkHoldIndex(c.buf)]
+	end := strings.LastIndexAny(safe, "\n。！？!?；;")
+	if end < 0 {
+		return ""
+	}
+	_, size := utf8.DecodeRuneInString(safe[end:])
+	chunk := strings.TrimSpace(safe[:end+size])
+	if utf8.RuneCountInString(chunk) < c.minSize {
+		return ""
+	}
+	c.buf = c.buf[end+size:]
+	return chunk
+}
+
+// flush 返回剩余的全部内容，未闭合的思维链会被丢弃
+func (c *replyChunker) flush() string {
+	rest := c.buf
+	if index := strings.Index(rest, "<think"); index >= 0 {
+		rest = rest[:index]
+	}
+	c.buf = ""
+	return strings.TrimSpace(rest)
+}
+
+// AIStreamReplier 在 AI 生成回复的过程中，按句子或段落分段发送
+// 工具调用期间发送“思考中”的占位消息，收到后续回复后撤回
+type AIStreamReplier struct {
+	ctx         context.Context
+	toWxID      string
+	at          []string
+	quote       *model.Message
+	interval    time.Duration
+	chunker     replyChunker
+	turn        strings.Builder // 当前轮次的完整回复，用于判断最终结果是否已经流式发送
+	turnSent    bool
+	lastSentAt  time.Time
+	placeholder *model.Message
+}
+
+var _ ai.ChatStreamHandler = (*AIStreamReplier)(nil)
+
+// NewAIStreamReplier 回复 message，群聊中第一段回复按 replyMode @提问的人或者引用提问的消息
+func NewAIStreamReplier(ctx context.Context, message *model.Message, replyMode model.AIReplyMode) *AIStreamReplier {
+	r := &AIStreamReplier{
+		ctx:      ctx,
+		toWxID:   message.FromWxID,
+		interval: time.Duration(vars.AIStreamSettings.MinInterval) * time.Millisecond,
+		chunker:  replyChunker{minSize: vars.AIStreamSettings.MinChunkSize},
+	}
+	if message.IsChatRoom {
+		r.at = []string{message.SenderWxID}
+		if replyMode == model.AIReplyModeQuote {
+			r.quote = message
+		}
+	}
+	return r
+}
+
+func (r *AIStreamReplier) OnContent(delta string) {
+	r.turn.WriteString(delta)
+	r.chunker.append(delta)
+	if time.Since(r.lastSentAt) < r.interval {
+		return
+	}
+	if chunk := r.chunker.next(); chunk != "" {
+		r.send(chunk)
+	}
+}
+
+func (r *AIStreamReplier) OnToolCalls() {
+	// 工具调用前输出的内容直接发送
+	if rest := r.chunker.flush(); rest != "" {
+		r.send(rest)
+	}
+	r.turn.Reset()
+	r.turnSent = false
+	if r.placeholder != nil {
+		return
+	}
+	placeholder, err := NewMessageService(r.ctx).sendTextMessage(r.toWxID, aiStreamPlaceholderText)
+	if err != nil {
+		log.Printf("[AIStream] 发送占位消息失败: %v", err)
+		return
+	}
+	r.placeholder = placeholder
+}
+
+func (r *AIStreamReplier) send(text string) {
+	r.revokePlaceholder()
+	msgService := NewMessageService(r.ctx)
+	var err error
+	if r.quote != nil {
+		if _, err = msgService.sendReferMessage(r.toWxID, text, r.quote); err != nil {
+			log.Printf("[AIStream] 引用回复失败，改为@回复: %v", err)
+		}
+	}
+	if r.quote == nil || err != nil {
+		if _, err = msgService.sendTextMessage(r.toWxID, text, r.at...); err != nil {
+			log.Printf("[AIStream] 发送分段回复失败: %v", err)
+		}
+	}
+	r.at = nil
+	r.quote = nil
+	r.turnSent = true
+	r.lastSentAt = time.Now()
+}
+
+// revokePlaceholder 撤回占位消息并删除记录，避免进入 AI 上下文
+func (r *AIStreamReplier) revokePlaceholder() {
+	if r.placeholder == nil {
+		return
+	}
+	placeholder := r.placeholder
+	r.placeholder = nil
+	msgService := NewMessageService(r.ctx)
+	if err := msgService.MessageRevoke(dto.MessageCommonRequest{MessageID: placeholder.ID}); err != nil {
+		log.Printf("[AIStream] 撤回占位消息失败: %v", err)
+	}
+	if err := msgService.msgRepo.Delete(placeholder); err != nil {
+		log.Printf("[AIStream] 删除占位消息失败: %v", err)
+	}
+}
+
+// Finish 对话结束后调用，content 为最终回复
+// 最终回复就是流式输出的内容时，发送剩余部分并返回 true；否则（如工具直接返回的结果）返回 false，由调用方处理
+func (r *AIStreamReplier) Finish(content string) bool {
+	if content == "" || content != r.turn.String() {
+		r.revokePlaceholder()
+		return false
+	}
+	if rest := r.chunker.flush(); rest != "" {
+		r.send(rest)
+	}
+	r.revokePlaceholder()
+	return r.turnSent
+}
+
+// Close 对话出错时调用，撤回占位消息
+func (r *AIStreamReplier) Close() {
+	r.revokePlaceholder()
+}
@@ -0,0 +1,39 @@
+package service
+
+import "testing"
+
+func TestReplyChunker(t *testing.T) {
+	c := replyChunker{minSize: 5}
+	c.append("你好。")
+	if chunk := c.next(); chunk != "" {
+		t.Fatalf("chunk shorter than min size should be held, got %q", chunk)
+	}
+	c.append("今天天气不错，适合出门")
+	if chunk := c.next(); chunk != "" {
+		t.Fatalf("incomplete sentence should be held, got %q", chunk)
+	}
+	c.append("散步！明天")
+	if chunk := c.next(); chunk != "你好。今天天气不错，适合出门散步！" {
+		t.Fatalf("unexpected chunk: %q", chunk)
+	}
+	if rest := c.flush(); rest != "明天" {
+		t.Fatalf("unexpected rest: %q", rest)
+	}
+
+	c = replyChunker{minSize: 1}
+	c.append("<thi")
+	if chunk := c.next(); chunk != "" {
+		t.Fatalf("partial think tag should be held, got %q", chunk)
+	}
+	c.append("nk>先想一想。</think>答案是42。")
+	if chunk := c.next(); chunk != "答案是42。" {
+		t.Fatalf("think block should be removed, got %q", chunk)
+	}
+	c.append("补充说明<think>还没想完。")
+	if chunk := c.next(); chunk != "" {
+		t.Fatalf("unclosed think block should be held, got %q", chunk)
+	}
+	if rest := c.flush(); rest != "补充说明" {
+		t.Fatalf("unclosed think block should be dropped on flush, got %q", rest)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/vars"
+)
+
+type aiUsageScopeKey struct{}
+
+// aiUsageScope AI 用量归属的会话、发送人和功能
+type aiUsageScope struct {
+	chatID     string
+	senderWxID string
+	feature    model.AIUsageFeature
+}
+
+// withAIUsageScope 在 ctx 上标记之后 AI 调用的用量归属
+func withAIUsageScope(ctx context.Context, chatID, senderWxID string, feature model.AIUsageFeature) context.Context {
+	return context.WithValue(ctx, aiUsageScopeKey{}, aiUsageScope{chatID: chatID, senderWxID: senderWxID, feature: feature})
+}
+
+func aiUsageScopeFrom(ctx context.Context, fallback model.AIUsageFeature) aiUsageScope {
+	if scope, ok := ctx.Value(aiUsageScopeKey{}).(aiUsageScope); ok {
+		return scope
+	}
+	return aiUsageScope{feature: fallback}
+}
+
+func aiUsageDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// recordAIUsage 累加当天的 AI 用量，记录失败不影响业务
+func recordAIUsage(ctx context.Context, scope aiUsageScope, modelName string, calls int64, usage openai.CompletionUsage) {
+	if vars.DB == nil {
+		return
+	}
+	err := repository.NewAIUsageRepo(ctx, vars.DB).Accumulate(&model.AIUsage{
+		Date:             aiUsageDate(time.Now()),
+		ChatID:           scope.chatID,
+		SenderWxID:       scope.senderWxID,
+		Feature:          scope.feature,
+		Model:            modelName,
+		Calls:            calls,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	})
+	if err != nil {
+		log.Printf("[AIUsage] 记录 AI 用量失败: %v", err)
+	}
+}
+
+type AIUsageService struct {
+	ctx       context.Context
+	usageRepo *repository.AIUsage
+}
+
+func NewAIUsageService(ctx context.Context) *AIUsageService {
+	return &AIUsageService{
+		ctx:       ctx,
+		usageRepo: repository.NewAIUsageRepo(ctx, vars.DB),
+	}
+}
+
+func (s *AIUsageService) GetList(req dto.AIUsageListRequest, pager appx.Pager) ([]*model.AIUsage, int64, error) {
+	return s.usageRepo.GetList(req, pager)
+}
+
+func (s *AIUsageService) GetDailySummary(req dto.AIUsageListRequest) ([]*model.AIUsage, error) {
+	return s.usageRepo.GetDailySummary(req)
+}
+
+// resolveAIQuota 加载会话的 AI 用量额度，群聊、好友未配置时使用全局配置
+func (s *AIUsageService) resolveAIQuota(chatID string) (*model.AIQuota, error) {
+	globalSettings, err := repository.NewGlobalSettingsRepo(s.ctx, vars.DB).GetGlobalSettings()
+	if err != nil {
+		return nil, err
+	}
+	var data datatypes.JSON
+	if globalSettings != nil {
+		data = globalSettings.AIQuota
+	}
+	if strings.Contains(chatID, "@chatroom") {
+		chatRoomSettings, err := repository.NewChatRoomSettingsRepo(s.ctx, vars.DB).GetChatRoomSettings(chatID)
+		if err != nil {
+			return nil, err
+		}
+		if chatRoomSettings != nil && len(chatRoomSettings.AIQuota) > 0 {
+			data = chatRoomSettings.AIQuota
+		}
+	} else {
+		friendSettings, err := repository.NewFriendSettingsRepo(s.ctx, vars.DB).GetFriendSettings(chatID)
+		if err != nil {
+			return nil, err
+		}
+		if friendSettings != nil && len(friendSettings.AIQuota) > 0 {
+			data = friendSettings.AIQuota
+		}
+	}
+	quota, err := model.ParseAIQuota(data)
+	if err != nil {
+		return nil, fmt.Errorf("ai_quota 格式错误: %w", err)
+	}
+	return quota, nil
+}
+
+// CheckQuota AI 对话前检查今天的额度，超出时返回的错误内容会直接回复给用户。
+// 群成员超出个人额度时，配置了积分消耗并且积分足够的，先扣减积分再继续对话，返回扣减的积分，
+// 对话失败时调用 RefundScore 退还
+func (s *AIUsageService) CheckQuota(chatID, senderWxID string) (int64, error) {
+	if vars.DB == nil || senderWxID == vars.RobotRuntime.WxID {
+		return 0, nil
+	}
+	quota, err := s.resolveAIQuota(chatID)
+	if err != nil {
+		// 额度配置有问题时不影响正常对话
+		log.Printf("[AIUsage] 获取 AI 用量额度失败: %v", err)
+		return 0, nil
+	}
+	if quota == nil || quota.IsExempt(senderWxID) || quota.IsExempt(chatID) {
+		return 0, nil
+	}
+	date := aiUsageDate(time.Now())
+	if !strings.Contains(chatID, "@chatroom") {
+		calls, tokens, err := s.usageRepo.Sum(date, chatID, "", model.AIUsageFeatureChat)
+		if err != nil {
+			log.Printf("[AIUsage] 统计 AI 用量失败: %v", err)
+			return 0, nil
+		}
+		if quota.FriendExceeded(calls, tokens) {
+			return 0, errors.New(quota.GetMessage())
+		}
+		return 0, nil
+	}
+
+	calls, tokens, err := s.usageRepo.Sum(date, chatID, "", model.AIUsageFeatureChat)
+	if err != nil {
+		log.Printf("[AIUsage] 统计 AI 用量失败: %v", err)
+		return 0, nil
+	}
+	if quota.ChatRoomExceeded(calls, tokens) {
+		return 0, errors.New(quota.GetMessage())
+	}
+	calls, tokens, err = s.usageRepo.Sum(date, chatID, senderWxID, model.AIUsageFeatureChat)
+	if err != nil {
+		log.Printf("[AIUsage] 统计 AI 用量失败: %v", err)
+		return 0, nil
+	}
+	if !quota.MemberExceeded(calls, tokens) {
+		return 0, nil
+	}
+	if quota.ScorePerCall > 0 {
+		ok, err := repository.NewChatRoomMemberRepo(s.ctx, vars.DB).DeductScore(chatID, senderWxID, quota.ScorePerCall)
+		if err != nil {
+			log.Printf("[AIUsage] 扣减积分失败: %v", err)
+		}
+		if ok {
+			return quota.ScorePerCall, nil
+		}
+		return 0, fmt.Errorf("%s\n（积分不足，超出额度后每次提问需要消耗 %d 积分）", quota.GetMessage(), quota.ScorePerCall)
+	}
+	return 0, errors.New(quota.GetMessage())
+}
+
+// RefundScore 退还 CheckQuota 扣减的积分
+func (s *AIUsageService) RefundScore(chatID, senderWxID string, score int64) {
+	if score <= 0 {
+		return
+	}
+	err := repository.NewChatRoomMemberRepo(s.ctx, vars.DB).AtomicUpdateScores(chatID, senderWxID, map[string]any{
+		"score": gorm.Expr("score + ?", score),
+	})
+	if err != nil {
+		log.Printf("[AIUsage] 退还积分失败: %v", err)
+	}
+}
+
+// validateAIQuota 保存配置前检查 AI 用量额度的格式
+func validateAIQuota(data datatypes.JSON) error {
+	quota, err := model.ParseAIQuota(data)
+	if err != nil {
+		return fmt.Errorf("ai_quota 格式错误: %w", err)
+	}
+	if quota == nil {
+		return nil
+	}
+	for _, limit := range []int64{quota.MemberDailyTokens, quota.MemberDailyCalls, quota.ChatRoomDailyTokens,
+		quota.ChatRoomDailyCalls, quota.FriendDailyTokens, quota.FriendDailyCalls, quota.ScorePerCall} {
+		if limit < 0 {
+			return errors.New("ai_quota 格式错误: 额度不能为负数")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"wechat-robot-client/model"
+)
+
+func TestAIQuotaExceeded(t *testing.T) {
+	quota := &model.AIQuota{MemberDailyCalls: 10, ChatRoomDailyTokens: 1000}
+	if quota.MemberExceeded(9, 100000) {
+		t.Errorf("member token usage should not be limited")
+	}
+	if !quota.MemberExceeded(10, 0) {
+		t.Errorf("member should exceed daily calls")
+	}
+	if quota.ChatRoomExceeded(100, 999) || !quota.ChatRoomExceeded(0, 1000) {
+		t.Errorf("unexpected chat room quota result")
+	}
+	if quota.FriendExceeded(1000, 1000000) {
+		t.Errorf("friend usage should not be limited")
+	}
+	if quota.GetMessage() != model.DefaultAIQuotaMessage {
+		t.Errorf("expected default refusal message")
+	}
+}
+
+func TestValidateAIQuota(t *testing.T) {
+	if err := validateAIQuota(nil); err != nil {
+		t.Errorf("empty quota should be valid: %v", err)
+	}
+	if err := validateAIQuota([]byte(`{"member_daily_calls": 20, "exempt": ["wxid_a"]}`)); err != nil {
+		t.Errorf("quota should be valid: %v", err)
+	}
+	if err := validateAIQuota([]byte(`{"member_daily_calls": -1}`)); err == nil {
+		t.Errorf("negative quota should be rejected")
+	}
+	if err := validateAIQuota([]byte(`{"member_daily_calls": "20"}`)); err == nil {
+		t.Errorf("malformed quota should be rejected")
+	}
+}
+
+func TestAIUsageScope(t *testing.T) {
+	scope := aiUsageScopeFrom(context.Background(), model.AIUsageFeatureEmbedding)
+	if scope.feature != model.AIUsageFeatureEmbedding || scope.chatID != "" {
+		t.Errorf("expected fallback scope, got %+v", scope)
+	}
+	ctx := withAIUsageScope(context.Background(), "123@chatroom", "", model.AIUsageFeatureSummary)
+	scope = aiUsageScopeFrom(ctx, model.AIUsageFeatureEmbedding)
+	if scope.feature != model.AIUsageFeatureSummary || scope.chatID != "123@chatroom" {
+		t.Errorf("expected summary scope, got %+v", scope)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+
+	"wechat-robot-client/interface/ai"
+	"wechat-robot-client/model"
+	"wechat-robot-client/utils"
+)
+
+const (
+	defaultASRModel = "whisper-1"
+	asrTimeout      = 60 * time.Second
+)
+
+// ASRService 基于 OpenAI 兼容 /audio/transcriptions 接口的语音识别服务
+type ASRService struct {
+	client *openai.Client
+	model  openai.AudioModel
+}
+
+var _ ai.ASRProvider = (*ASRService)(nil)
+
+// NewASRService 创建语音识别服务
+func NewASRService(baseURL, apiKey, model string) *ASRService {
+	if model == "" {
+		model = defaultASRModel
+	}
+	client := newOpenAIClient(apiKey, baseURL)
+	return &ASRService{
+		client: &client,
+		model:  openai.AudioModel(model),
+	}
+}
+
+// NewASRServiceFromSettings 根据全局配置创建语音识别服务，未启用或配置不完整时返回 nil
+func NewASRServiceFromSettings(globalSettings *model.GlobalSettings) ai.ASRProvider {
+	if globalSettings == nil || !utils.PtrBoolValue(globalSettings.ASREnabled) {
+		return nil
+	}
+	baseURL := utils.PtrStringValue(globalSettings.ASRBaseURL)
+	if baseURL == "" {
+		baseURL = globalSettings.ChatBaseURL
+	}
+	apiKey := utils.PtrStringValue(globalSettings.ASRAPIKey)
+	if apiKey == "" {
+		apiKey = globalSettings.ChatAPIKey
+	}
+	if baseURL == "" || apiKey == "" {
+		return nil
+	}
+	return NewASRService(baseURL, apiKey, utils.PtrStringValue(globalSettings.ASRModel))
+}
+
+// Transcribe 将音频转写为文本
+func (s *ASRService) Transcribe(ctx context.Context, audio []byte, filename string) (string, error) {
+	if len(audio) == 0 {
+		return "", fmt.Errorf("音频内容为空")
+	}
+	ctx, cancel := context.WithTimeout(ctx, asrTimeout)
+	defer cancel()
+
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	resp, err := s.client.Audio.Transcriptions.New(ctx, openai.AudioTranscriptionNewParams{
+		File:  openai.File(bytes.NewReader(audio), filename, contentType),
+		Model: s.model,
+	})
+	if err != nil {
+		return "", fmt.Errorf("语音识别失败: %w", err)
+	}
+	return strings.TrimSpace(resp.Text), nil
+}
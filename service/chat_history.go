@@ -10,17 +10,29 @@ import (
 )
 
 type ChatHistoryService struct {
-	ctx     context.Context
-	msgRepo *repository.Message
+	ctx        context.Context
+	msgRepo    *repository.Message
+	recallRepo *repository.MessageRecall
 }
 
 func NewChatHistoryService(ctx context.Context) *ChatHistoryService {
 	return &ChatHistoryService{
-		ctx:     ctx,
-		msgRepo: repository.NewMessageRepo(ctx, vars.DB),
+		ctx:        ctx,
+		msgRepo:    repository.NewMessageRepo(ctx, vars.DB),
+		recallRepo: repository.NewMessageRecallRepo(ctx, vars.DB),
 	}
 }
 
 func (s *ChatHistoryService) GetChatHistory(req dto.ChatHistoryRequest, pager appx.Pager) ([]*model.Message, int64, error) {
 	return s.msgRepo.GetByContactID(req, pager)
 }
+
+// GetRecalledMessages 获取群聊的消息撤回记录
+func (s *ChatHistoryService) GetRecalledMessages(req dto.RecalledMessageRequest, pager appx.Pager) ([]*dto.RecalledMessage, int64, error) {
+	return s.recallRepo.GetByChatRoomID(req, pager)
+}
+
+// SetRecallNotified 标记撤回记录已发送防撤回通知
+func (s *ChatHistoryService) SetRecallNotified(id int64) error {
+	return s.recallRepo.SetNotified(id)
+}
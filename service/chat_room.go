@@ -640,7 +640,7 @@ func (s *ChatRoomService) ChatRoomAISummaryByChatRoomID(globalSettings *model.Gl
 	if setting.ChatRoomSummaryModel != nil && *setting.ChatRoomSummaryModel != "" {
 		summaryModel = *setting.ChatRoomSummaryModel
 	}
-	report, err := s.generateChatRoomSummaryReport(context.Background(), aiApiKey, aiApiBaseURL, summaryModel, chatRoomName, content)
+	report, err := s.generateChatRoomSummaryReport(withAIUsageScope(context.Background(), setting.ChatRoomID, "", model.AIUsageFeatureSummary), aiApiKey, aiApiBaseURL, summaryModel, chatRoomName, content)
 	if err != nil {
 		log.Printf("群聊记录总结失败: %v", err.Error())
 		msgService.SendTextMessage(setting.ChatRoomID, "#昨日消息总结\n\n群聊消息总结失败，错误信息: "+err.Error())
@@ -659,17 +659,19 @@ func (s *ChatRoomService) ChatRoomAISummaryByChatRoomID(globalSettings *model.Gl
 	switch mode {
 	case model.ChatRoomSummaryModeImage:
 		data := buildChatRoomSummaryTemplateData(chatRoomName, summaryModel, report)
-		if err := s.sendChatRoomSummaryImage(context.Background(), msgService, setting.ChatRoomID, data); err != nil {
+		if err := s.sendChatRoomSummaryImage(context.Background(), setting.ChatRoomID, data); err != nil {
 			log.Printf("群聊记录总结图片发送失败: %v", err)
 			msgService.SendTextMessage(setting.ChatRoomID, "#昨日消息总结\n\n群聊消息图片生成失败，错误信息: "+err.Error())
 			return err
 		}
 	case model.ChatRoomSummaryModeText:
 		replyMsg := renderChatRoomSummaryText(summaryModel, report)
-		return msgService.SendLongTextMessage(setting.ChatRoomID, replyMsg)
+		_, err := NewOutboundMessageService(context.Background()).EnqueueLongText("群聊总结", setting.ChatRoomID, replyMsg)
+		return err
 	default:
 		replyMsg := renderChatRoomSummaryText(summaryModel, report)
-		return msgService.SendLongTextMessage(setting.ChatRoomID, replyMsg)
+		_, err := NewOutboundMessageService(context.Background()).EnqueueLongText("群聊总结", setting.ChatRoomID, replyMsg)
+		return err
 	}
 	return nil
 }
@@ -726,6 +728,7 @@ func (s *ChatRoomService) ChatRoomRankingDaily() error {
 	}
 
 	msgService := NewMessageService(context.Background())
+	outboundService := NewOutboundMessageService(context.Background())
 
 	for _, setting := range settings {
 		notifyMsgs := []string{"#昨日水群排行榜"}
@@ -793,7 +796,9 @@ func (s *ChatRoomService) ChatRoomRankingDaily() error {
 			notifyMsgs = append(notifyMsgs, fmt.Sprintf("%s %s -> %d条", badge, r.ChatRoomMemberNickname, r.Count))
 		}
 		notifyMsgs = append(notifyMsgs, " \n🎉感谢以上群友昨日对群活跃做出的卓越贡献，也请未上榜的群友多多反思。")
-		msgService.SendTextMessage(setting.ChatRoomID, strings.Join(notifyMsgs, "\n"))
+		if _, err := outboundService.EnqueueText("群聊日排行榜", setting.ChatRoomID, strings.Join(notifyMsgs, "\n")); err != nil {
+			log.Printf("群聊 %s 日排行榜消息入队失败: %v", setting.ChatRoomID, err)
+		}
 		// 发送词云图片
 		wordCloudCacheDir := filepath.Join(string(filepath.Separator), "app", "word_cloud_cache")
 		dateStr := yesterdayStart.Format("2006-01-02")
@@ -804,10 +809,10 @@ func (s *ChatRoomService) ChatRoomRankingDaily() error {
 			log.Printf("群聊 %s 打开词云图片文件失败: %v", setting.ChatRoomID, err)
 			continue
 		}
-		defer imageFile.Close()
-		_, err = msgService.MsgUploadImg(setting.ChatRoomID, imageFile)
+		_, err = outboundService.EnqueueImage("群聊词云", setting.ChatRoomID, imageFile)
+		imageFile.Close()
 		if err != nil {
-			log.Printf("群聊 %s 词云图片发送失败: %v", setting.ChatRoomID, err)
+			log.Printf("群聊 %s 词云图片入队失败: %v", setting.ChatRoomID, err)
 			continue
 		}
 	}
@@ -821,6 +826,7 @@ func (s *ChatRoomService) ChatRoomRankingWeekly() error {
 	}
 
 	msgService := NewMessageService(context.Background())
+	outboundService := NewOutboundMessageService(context.Background())
 
 	for _, setting := range settings {
 		notifyMsgs := []string{"#上周水群排行榜"}
@@ -889,7 +895,9 @@ func (s *ChatRoomService) ChatRoomRankingWeekly() error {
 			notifyMsgs = append(notifyMsgs, fmt.Sprintf("%s %s -> %d条", badge, r.ChatRoomMemberNickname, r.Count))
 		}
 		notifyMsgs = append(notifyMsgs, " \n🎉感谢以上群友上周对群活跃做出的卓越贡献，也请未上榜的群友多多反思。")
-		msgService.SendTextMessage(setting.ChatRoomID, strings.Join(notifyMsgs, "\n"))
+		if _, err := outboundService.EnqueueText("群聊周排行榜", setting.ChatRoomID, strings.Join(notifyMsgs, "\n")); err != nil {
+			log.Printf("群聊 %s 周排行榜消息入队失败: %v", setting.ChatRoomID, err)
+		}
 	}
 	return nil
 }
@@ -903,6 +911,7 @@ func (s *ChatRoomService) ChatRoomRankingMonthly() error {
 	}
 
 	msgService := NewMessageService(context.Background())
+	outboundService := NewOutboundMessageService(context.Background())
 
 	for _, setting := range settings {
 		notifyMsgs := []string{fmt.Sprintf("#%s水群排行榜", monthStr)}
@@ -970,7 +979,9 @@ func (s *ChatRoomService) ChatRoomRankingMonthly() error {
 			notifyMsgs = append(notifyMsgs, fmt.Sprintf("%s %s -> %d条", badge, r.ChatRoomMemberNickname, r.Count))
 		}
 		notifyMsgs = append(notifyMsgs, fmt.Sprintf(" \n🎉感谢以上群友%s对群活跃做出的卓越贡献，也请未上榜的群友多多反思。", monthStr))
-		msgService.SendTextMessage(setting.ChatRoomID, strings.Join(notifyMsgs, "\n"))
+		if _, err := outboundService.EnqueueText("群聊月排行榜", setting.ChatRoomID, strings.Join(notifyMsgs, "\n")); err != nil {
+			log.Printf("群聊 %s 月排行榜消息入队失败: %v", setting.ChatRoomID, err)
+		}
 	}
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"slices"
 	"strings"
 	"wechat-robot-client/interface/settings"
 	"wechat-robot-client/model"
@@ -13,6 +14,8 @@ import (
 	"wechat-robot-client/repository"
 	"wechat-robot-client/utils"
 	"wechat-robot-client/vars"
+
+	"gorm.io/datatypes"
 )
 
 type ChatRoomSettingsService struct {
@@ -147,6 +150,25 @@ func (s *ChatRoomSettingsService) IsTTSEnabled() bool {
 	return false
 }
 
+// IsAIStreamReplyEnabled AI 回复是否边生成边分段发送
+func (s *ChatRoomSettingsService) IsAIStreamReplyEnabled() bool {
+	if s.chatRoomSettings != nil && s.chatRoomSettings.AIStreamReplyEnabled != nil {
+		return *s.chatRoomSettings.AIStreamReplyEnabled
+	}
+	if s.globalSettings != nil && s.globalSettings.AIStreamReplyEnabled != nil {
+		return *s.globalSettings.AIStreamReplyEnabled
+	}
+	return false
+}
+
+// GetAIReplyMode 群聊中 AI 的回复方式，未配置时@提问的人
+func (s *ChatRoomSettingsService) GetAIReplyMode() model.AIReplyMode {
+	if s.chatRoomSettings != nil && s.chatRoomSettings.AIReplyMode != nil && *s.chatRoomSettings.AIReplyMode != "" {
+		return *s.chatRoomSettings.AIReplyMode
+	}
+	return model.AIReplyModeAt
+}
+
 func (s *ChatRoomSettingsService) IsShortVideoParsingEnabled() bool {
 	if s.chatRoomSettings != nil && s.chatRoomSettings.ShortVideoParsingEnabled != nil {
 		return *s.chatRoomSettings.ShortVideoParsingEnabled
@@ -176,7 +198,7 @@ func (s *ChatRoomSettingsService) logAITrigger(reason, triggerWord, messageConte
 }
 
 func (s *ChatRoomSettingsService) IsAITrigger() bool {
-	messageContent := s.Message.Content
+	messageContent := s.Message.TextContent()
 	if s.Message.AppMsgType == model.AppMsgTypequote {
 		var xmlMessage robot.XmlMessage
 		if err := vars.RobotRuntime.XmlDecoder(messageContent, &xmlMessage); err == nil {
@@ -284,6 +306,29 @@ func (s *ChatRoomSettingsService) GetPatConfig() settings.PatConfig {
 	return settings.PatConfig{}
 }
 
+func (s *ChatRoomSettingsService) IsCommandEnabled(name string) bool {
+	if s.chatRoomSettings == nil {
+		return true
+	}
+	names, err := s.chatRoomSettings.GetDisabledCommands()
+	if err != nil {
+		log.Printf("解析群聊禁用指令列表失败: %v", err)
+		return true
+	}
+	return !slices.Contains(names, name)
+}
+
+func (s *ChatRoomSettingsService) IsPluginEnabled(name string, labels []string) bool {
+	var chatRoomSwitches, globalSwitches datatypes.JSON
+	if s.chatRoomSettings != nil {
+		chatRoomSwitches = s.chatRoomSettings.PluginSwitches
+	}
+	if s.globalSettings != nil {
+		globalSwitches = s.globalSettings.PluginSwitches
+	}
+	return resolvePluginEnabled(name, labels, chatRoomSwitches, globalSwitches)
+}
+
 func (s *ChatRoomSettingsService) GetLeaveChatRoomConfig(chatRoomID string) *model.ChatRoomSettings {
 	globalSettings, err := s.gsRepo.GetGlobalSettings()
 	if err != nil {
@@ -340,6 +385,15 @@ func (s *ChatRoomSettingsService) SaveChatRoomSettings(data *model.ChatRoomSetti
 	if err := s.normalizeMemoryExtractionBlacklist(data); err != nil {
 		return err
 	}
+	if err := s.normalizeDisabledCommands(data); err != nil {
+		return err
+	}
+	if err := validateToolPolicy(data.ToolPolicy); err != nil {
+		return err
+	}
+	if err := validateAIQuota(data.AIQuota); err != nil {
+		return err
+	}
 	if data.ID == 0 {
 		return s.crsRepo.Create(data)
 	}
@@ -445,3 +499,22 @@ func (s *ChatRoomSettingsService) normalizeMemoryExtractionBlacklist(data *model
 
 	return nil
 }
+
+func (s *ChatRoomSettingsService) normalizeDisabledCommands(data *model.ChatRoomSettings) error {
+	if data == nil || data.DisabledCommands == nil {
+		return nil
+	}
+
+	names, err := data.GetDisabledCommands()
+	if err != nil {
+		return fmt.Errorf("disabled_commands 格式错误: %w", err)
+	}
+
+	payload, err := json.Marshal(normalizeStringList(names))
+	if err != nil {
+		return fmt.Errorf("序列化 disabled_commands 失败: %w", err)
+	}
+	data.DisabledCommands = payload
+
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/chromedp/chromedp"
 	"github.com/openai/openai-go/v3"
 
+	"wechat-robot-client/pkg/airouter"
 	chatRoomSummaryTemplate "wechat-robot-client/pkg/templates/chatroomsummary"
 )
 
@@ -76,16 +77,16 @@ type chatRoomSummaryResourceView struct {
 }
 
 func (s *ChatRoomService) generateChatRoomSummaryReport(ctx context.Context, apiKey, baseURL, summaryModel, chatRoomName string, content []string) (*chatRoomSummaryReport, error) {
-	client := newOpenAIClient(apiKey, baseURL)
+	router := newAIRouter(ctx, baseURL, apiKey, summaryModel)
 	messages := buildChatRoomSummaryAIMessages(chatRoomName, strings.Join(content, "\n"))
 
-	report, err := requestChatRoomSummaryReport(ctx, &client, summaryModel, messages, true)
+	report, err := requestChatRoomSummaryReport(ctx, router, summaryModel, messages, true)
 	if err == nil {
 		return report, nil
 	}
 
 	log.Printf("群聊记录结构化总结失败，尝试降级为普通 JSON 输出: %v", err)
-	fallbackReport, fallbackErr := requestChatRoomSummaryReport(ctx, &client, summaryModel, messages, false)
+	fallbackReport, fallbackErr := requestChatRoomSummaryReport(ctx, router, summaryModel, messages, false)
 	if fallbackErr != nil {
 		return nil, fmt.Errorf("结构化总结失败: %w; 降级总结失败: %v", err, fallbackErr)
 	}
@@ -117,7 +118,7 @@ JSON 字段要求：
 	}
 }
 
-func requestChatRoomSummaryReport(ctx context.Context, client *openai.Client, summaryModel string, messages []openai.ChatCompletionMessageParamUnion, withSchema bool) (*chatRoomSummaryReport, error) {
+func requestChatRoomSummaryReport(ctx context.Context, router *airouter.Router, summaryModel string, messages []openai.ChatCompletionMessageParamUnion, withSchema bool) (*chatRoomSummaryReport, error) {
 	req := openai.ChatCompletionNewParams{
 		Model:    summaryModel,
 		Messages: messages,
@@ -135,7 +136,7 @@ func requestChatRoomSummaryReport(ctx context.Context, client *openai.Client, su
 		}
 	}
 
-	msg, err := streamChatCompletionMessage(ctx, client, req)
+	msg, err := streamChatCompletionMessage(ctx, router, req)
 	if err != nil {
 		return nil, err
 	}
@@ -338,7 +339,7 @@ func renderChatRoomSummaryHTML(data chatRoomSummaryTemplateData) (string, error)
 	return buffer.String(), nil
 }
 
-func (s *ChatRoomService) sendChatRoomSummaryImage(ctx context.Context, msgService *MessageService, chatRoomID string, data chatRoomSummaryTemplateData) error {
+func (s *ChatRoomService) sendChatRoomSummaryImage(ctx context.Context, chatRoomID string, data chatRoomSummaryTemplateData) error {
 	htmlContent, err := renderChatRoomSummaryHTML(data)
 	if err != nil {
 		return fmt.Errorf("渲染群聊总结模板失败: %w", err)
@@ -347,9 +348,9 @@ func (s *ChatRoomService) sendChatRoomSummaryImage(ctx context.Context, msgServi
 	if err != nil {
 		return fmt.Errorf("群聊总结截图失败: %w", err)
 	}
-	_, err = msgService.MsgUploadImg(chatRoomID, bytes.NewReader(pngBytes))
+	_, err = NewOutboundMessageService(ctx).EnqueueImage("群聊总结", chatRoomID, bytes.NewReader(pngBytes))
 	if err != nil {
-		return fmt.Errorf("发送群聊总结图片失败: %w", err)
+		return fmt.Errorf("群聊总结图片入队失败: %w", err)
 	}
 	return nil
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/openai/openai-go/v3"
 	"github.com/redis/go-redis/v9"
 
+	"wechat-robot-client/model"
 	"wechat-robot-client/vars"
 )
 
@@ -63,6 +64,7 @@ func (s *EmbeddingService) Embed(ctx context.Context, text string) ([]float32, e
 	if err != nil {
 		return nil, fmt.Errorf("embedding failed: %w", err)
 	}
+	s.recordUsage(ctx, resp.Usage)
 	if len(resp.Data) == 0 {
 		return nil, fmt.Errorf("empty embedding response")
 	}
@@ -94,6 +96,7 @@ func (s *EmbeddingService) EmbedBatch(ctx context.Context, texts []string) ([][]
 	if err != nil {
 		return nil, fmt.Errorf("batch embedding failed: %w", err)
 	}
+	s.recordUsage(ctx, resp.Usage)
 
 	results := make([][]float32, len(texts))
 	for i, data := range resp.Data {
@@ -106,6 +109,14 @@ func (s *EmbeddingService) EmbedBatch(ctx context.Context, texts []string) ([][]
 	return results, nil
 }
 
+// recordUsage 记录向量化的 token 用量，未标记归属时计入 embedding 功能
+func (s *EmbeddingService) recordUsage(ctx context.Context, usage openai.CreateEmbeddingResponseUsage) {
+	recordAIUsage(ctx, aiUsageScopeFrom(ctx, model.AIUsageFeatureEmbedding), string(s.model), 1, openai.CompletionUsage{
+		PromptTokens: usage.PromptTokens,
+		TotalTokens:  usage.TotalTokens,
+	})
+}
+
 func float64SliceToFloat32(data []float64) []float32 {
 	result := make([]float32, len(data))
 	for i, v := range data {
@@ -8,6 +8,8 @@ import (
 	"wechat-robot-client/repository"
 	"wechat-robot-client/utils"
 	"wechat-robot-client/vars"
+
+	"gorm.io/datatypes"
 )
 
 type FriendSettingsService struct {
@@ -120,6 +122,21 @@ func (s *FriendSettingsService) GetPatConfig() settings.PatConfig {
 	return settings.PatConfig{}
 }
 
+func (s *FriendSettingsService) IsCommandEnabled(name string) bool {
+	return true
+}
+
+func (s *FriendSettingsService) IsPluginEnabled(name string, labels []string) bool {
+	var friendSwitches, globalSwitches datatypes.JSON
+	if s.friendSettings != nil {
+		friendSwitches = s.friendSettings.PluginSwitches
+	}
+	if s.globalSettings != nil {
+		globalSwitches = s.globalSettings.PluginSwitches
+	}
+	return resolvePluginEnabled(name, labels, friendSwitches, globalSwitches)
+}
+
 func (s *FriendSettingsService) IsAIChatEnabled() bool {
 	if s.friendSettings != nil && s.friendSettings.ChatAIEnabled != nil {
 		return *s.friendSettings.ChatAIEnabled
@@ -162,6 +179,22 @@ func (s *FriendSettingsService) IsTTSEnabled() bool {
 	return false
 }
 
+// IsAIStreamReplyEnabled AI 回复是否边生成边分段发送
+func (s *FriendSettingsService) IsAIStreamReplyEnabled() bool {
+	if s.friendSettings != nil && s.friendSettings.AIStreamReplyEnabled != nil {
+		return *s.friendSettings.AIStreamReplyEnabled
+	}
+	if s.globalSettings != nil && s.globalSettings.AIStreamReplyEnabled != nil {
+		return *s.globalSettings.AIStreamReplyEnabled
+	}
+	return false
+}
+
+// GetAIReplyMode 私聊不需要区分回复对象，直接发送文本
+func (s *FriendSettingsService) GetAIReplyMode() model.AIReplyMode {
+	return model.AIReplyModeAt
+}
+
 func (s *FriendSettingsService) IsShortVideoParsingEnabled() bool {
 	return true
 }
@@ -179,6 +212,12 @@ func (s *FriendSettingsService) GetFriendSettings(contactID string) (*model.Frie
 }
 
 func (s *FriendSettingsService) SaveFriendSettings(data *model.FriendSettings) error {
+	if err := validateToolPolicy(data.ToolPolicy); err != nil {
+		return err
+	}
+	if err := validateAIQuota(data.AIQuota); err != nil {
+		return err
+	}
 	if data.ID == 0 {
 		return s.fsRepo.Create(data)
 	}
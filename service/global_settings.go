@@ -25,6 +25,15 @@ func (s *GlobalSettingsService) GetGlobalSettings() (*model.GlobalSettings, erro
 
 func (s *GlobalSettingsService) SaveGlobalSettings(data *model.GlobalSettings) error {
 	data.FriendSyncCron = "" // 这个不允许用户修改
+	if err := validateToolPolicy(data.ToolPolicy); err != nil {
+		return err
+	}
+	if err := validateAIQuota(data.AIQuota); err != nil {
+		return err
+	}
+	if err := validateAIRouting(data.AIRouting); err != nil {
+		return err
+	}
 	err := s.gsRepo.Update(data)
 	if err != nil {
 		return err
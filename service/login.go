@@ -58,6 +58,7 @@ func (s *LoginService) Online() error {
 		ID:     vars.RobotRuntime.RobotID,
 		Status: model.RobotStatusOnline,
 	}
+	GetRobotSession().MarkOnline("登录成功")
 	return s.robotAdminRepo.Update(&robot)
 }
 
@@ -79,12 +80,14 @@ func (s *LoginService) Offline() error {
 		ID:     vars.RobotRuntime.RobotID,
 		Status: model.RobotStatusOffline,
 	}
+	GetRobotSession().MarkOffline("机器人离线")
 	return s.robotAdminRepo.Update(&robot)
 }
 
 func (s *LoginService) IsRunning() (result bool) {
 	result = vars.RobotRuntime.IsRunning()
-	if !result && vars.RobotRuntime.Status != model.RobotStatusOffline {
+	// 重连期间由会话状态机决定是否离线
+	if !result && vars.RobotRuntime.Status != model.RobotStatusOffline && !GetRobotSession().Recovering() {
 		s.Offline()
 	}
 	return
@@ -92,7 +95,7 @@ func (s *LoginService) IsRunning() (result bool) {
 
 func (s *LoginService) IsLoggedIn() (result bool) {
 	result = vars.RobotRuntime.IsLoggedIn()
-	if !result && vars.RobotRuntime.Status != model.RobotStatusOffline {
+	if !result && vars.RobotRuntime.Status != model.RobotStatusOffline && !GetRobotSession().Recovering() {
 		s.Offline()
 	}
 	return
@@ -113,7 +116,7 @@ func (s *LoginService) CloseAutoHeartBeat() error {
 func (s *LoginService) HeartbeatStart() {
 	ctx := context.Background()
 	vars.RobotRuntime.HeartbeatContext, vars.RobotRuntime.HeartbeatCancel = context.WithCancel(ctx)
-	var errCount int
+	session := GetRobotSession()
 	for {
 		select {
 		case <-vars.RobotRuntime.HeartbeatContext.Done():
@@ -123,22 +126,14 @@ func (s *LoginService) HeartbeatStart() {
 			err := vars.RobotRuntime.Heartbeat()
 			log.Println(mode, " 心跳: ", err)
 			if err != nil {
-				errCount++
-				if mode == "release" && errCount%3 == 0 {
-					log.Println("检测到机器人掉线，尝试重新登陆...")
-					err := vars.RobotRuntime.LoginTwiceAutoAuth()
-					if err != nil {
-						log.Println("尝试重新登陆失败: ", err)
-					}
-				}
-				if errCount > 10 {
-					// 10次心跳失败，认为机器人离线
-					s.Offline()
+				// 连续失败、退出登录后的重连都交给会话状态机处理
+				session.HeartbeatFailed(err)
+				if session.State() == model.RobotSessionStateOffline {
 					return
 				}
-			} else {
-				errCount = 0
+				continue
 			}
+			session.Alive()
 		}
 	}
 }
@@ -283,50 +278,26 @@ func (r *LoginService) SyncMessageCallback(wxID string, syncMessage robot.SyncMe
 	if wxID != vars.RobotRuntime.WxID {
 		return
 	}
+	GetRobotSession().Alive()
 	NewMessageService(r.ctx).ProcessMessage(syncMessage)
 }
 
+// LogoutCallback 协议服务的掉线、心跳失败通知，交给会话状态机处理，通知在状态变更时发送
 func (r *LoginService) LogoutCallback(req dto.LogoutNotificationRequest) (err error) {
 	if req.WxID != vars.RobotRuntime.WxID {
 		log.Printf("LogoutCallback: wxID(%s) does not match the current robot's wxID", req.WxID)
 		return
 	}
-	defer func() {
-		if req.Type != "offline" {
-			log.Printf("接收到掉线通知，但类型不是offline，忽略处理: %s", req.Type)
-			return
-		}
-		err := r.Offline()
-		if err != nil {
-			log.Printf("接收到掉线通知，退出客户端失败: %v", err)
-		}
-	}()
-
-	systemSettings, err := r.systemSettingsRepo.GetSystemSettings()
-	if err != nil {
-		log.Printf("接收到掉线通知，获取系统设置失败: %v", err)
-		return
-	}
-	if systemSettings == nil {
-		log.Printf("接收到掉线通知，系统设置还未设置")
-		return
-	}
-	if systemSettings.OfflineNotificationEnabled != nil && *systemSettings.OfflineNotificationEnabled {
-		if notifyErr := r.sendOfflineNotification(systemSettings, req); notifyErr != nil {
-			log.Printf("接收到掉线通知，发送离线通知失败: %v", notifyErr)
-			return
-		}
-		log.Printf("接收到掉线通知，发送离线通知成功")
+	session := GetRobotSession()
+	if req.Type == "offline" {
+		session.LoggedOut("协议服务通知机器人掉线")
 		return
 	}
-
-	log.Printf("接收到掉线通知，系统设置未开启离线通知")
+	session.HeartbeatFailed(fmt.Errorf("协议服务第 %d 次心跳失败", req.RetryCount))
 	return
 }
 
-func (r *LoginService) sendOfflineNotification(systemSettings *model.SystemSettings, req dto.LogoutNotificationRequest) error {
-	title, content := buildOfflineNotificationMessage(req)
-
+func (r *LoginService) sendNotification(systemSettings *model.SystemSettings, title, content string) error {
 	switch systemSettings.NotificationType {
 	case model.NotificationTypePushPlus:
 		return r.sendPushPlusNotification(systemSettings, title, content)
@@ -445,10 +416,3 @@ func (r *LoginService) sendWeComNotification(systemSettings *model.SystemSetting
 	}
 	return nil
 }
-
-func buildOfflineNotificationMessage(req dto.LogoutNotificationRequest) (string, string) {
-	if req.Type == "offline" {
-		return "机器人掉线通知", fmt.Sprintf("您的机器人（%s）掉线啦~~~", vars.RobotRuntime.WxID)
-	}
-	return "机器人发送心跳失败", fmt.Sprintf("您的机器人（%s）第%d次发送心跳失败了~~~", vars.RobotRuntime.WxID, req.RetryCount)
-}
@@ -100,7 +100,7 @@ func NewMemoryService(db *gorm.DB, vectorStore *VectorStoreService) *MemoryServi
 }
 
 func (s *MemoryService) NotifyMessage(ctx context.Context, message *model.Message) {
-	if message == nil || message.Type != model.MsgTypeText || strings.TrimSpace(message.Content) == "" {
+	if message == nil || (message.Type != model.MsgTypeText && message.Type != model.MsgTypeVoice) || strings.TrimSpace(message.TextContent()) == "" {
 		return
 	}
 	if !s.enabled() || vars.RobotRuntime.RobotCode == "" {
@@ -312,6 +312,11 @@ func (s *MemoryService) extractAndStore(ctx context.Context, messages []*model.M
 	if transcript == "" {
 		return nil
 	}
+	usageChatID := contactWxID
+	if isChatRoom {
+		usageChatID = chatRoomID
+	}
+	ctx = withAIUsageScope(ctx, usageChatID, "", model.AIUsageFeatureMemory)
 	result, err := s.extractMemoriesWithAI(ctx, settings, transcript, isChatRoom, chatRoomID, contactWxID)
 	if err != nil {
 		return err
@@ -340,7 +345,7 @@ func (s *MemoryService) buildExtractionTranscript(messages []*model.Message, cha
 	var sb strings.Builder
 	mentionNormalizer := s.buildMentionNormalizer(chatRoomID)
 	for _, msg := range messages {
-		content, mentionedWxIDs := mentionNormalizer.Normalize(msg.Content)
+		content, mentionedWxIDs := mentionNormalizer.Normalize(msg.TextContent())
 		content = strings.TrimSpace(content)
 		if content == "" {
 			continue
@@ -451,8 +456,8 @@ func (s *MemoryService) extractMemoriesWithAI(ctx context.Context, settings *mod
 `, scene, scopeRule)
 
 	userPrompt := "聊天窗口如下：\n" + transcript
-	client := newOpenAIClient(settings.ChatAPIKey, settings.ChatBaseURL)
-	msg, err := streamChatCompletionMessage(ctx, &client, openai.ChatCompletionNewParams{
+	router := newAIRouter(ctx, settings.ChatBaseURL, settings.ChatAPIKey, settings.ChatModel)
+	msg, err := streamChatCompletionMessage(ctx, router, openai.ChatCompletionNewParams{
 		Model: settings.ChatModel,
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.SystemMessage(systemPrompt),
@@ -13,7 +13,9 @@ import (
 	"log"
 	"math/rand"
 	"mime/multipart"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"slices"
 	"strconv"
@@ -25,6 +27,7 @@ import (
 	"github.com/openai/openai-go/v3"
 
 	"wechat-robot-client/dto"
+	"wechat-robot-client/interface/ai"
 	"wechat-robot-client/interface/plugin"
 	"wechat-robot-client/interface/settings"
 	"wechat-robot-client/model"
@@ -36,6 +39,8 @@ import (
 type MessageService struct {
 	ctx            context.Context
 	msgRepo        *repository.Message
+	locationRepo   *repository.MessageLocation
+	recallRepo     *repository.MessageRecall
 	crmRepo        *repository.ChatRoomMember
 	sysmsgRepo     *repository.SystemMessage
 	robotAdminRepo *repository.RobotAdmin
@@ -47,6 +52,8 @@ func NewMessageService(ctx context.Context) *MessageService {
 	return &MessageService{
 		ctx:            ctx,
 		msgRepo:        repository.NewMessageRepo(ctx, vars.DB),
+		locationRepo:   repository.NewMessageLocationRepo(ctx, vars.DB),
+		recallRepo:     repository.NewMessageRecallRepo(ctx, vars.DB),
 		crmRepo:        repository.NewChatRoomMemberRepo(ctx, vars.DB),
 		sysmsgRepo:     repository.NewSystemMessageRepo(ctx, vars.DB),
 		robotAdminRepo: repository.NewRobotAdminRepo(ctx, vars.AdminDB),
@@ -82,63 +89,149 @@ func (s *MessageService) logPluginMatch(messagePlugin plugin.MessageHandler, msg
 	)
 }
 
-// ProcessTextMessage 处理文本消息
-func (s *MessageService) ProcessTextMessage(message *model.Message, msgSettings settings.Settings) {
-	msgCtx := &plugin.MessageContext{
+// newMessageContext 创建插件消息上下文，按消息类型解析的消息体由调用方填充
+func (s *MessageService) newMessageContext(message *model.Message, msgSettings settings.Settings, content string) *plugin.MessageContext {
+	return &plugin.MessageContext{
 		Context:        s.ctx,
 		Settings:       msgSettings,
 		Message:        message,
-		MessageContent: message.Content,
+		MessageContent: content,
 		MessageService: s,
 	}
-	for _, messagePlugin := range vars.MessagePlugin.Plugins {
-		if !slices.Contains(messagePlugin.GetLabels(), "text") {
+}
+
+// dispatchMessagePlugins 按优先级依次执行带有指定标签的插件（经过全局拦截器及 PreAction/PostAction），插件返回非 RunContinue 时终止插件链并返回该结果
+func (s *MessageService) dispatchMessagePlugins(label string, msgCtx *plugin.MessageContext) plugin.RunResult {
+	for _, messagePlugin := range vars.MessagePlugin.GetPluginsByLabel(label) {
+		if msgCtx.Settings != nil && !msgCtx.Settings.IsPluginEnabled(messagePlugin.GetName(), messagePlugin.GetLabels()) {
 			continue
 		}
-		match := messagePlugin.Match(msgCtx)
-		if !match {
+		if !messagePlugin.Match(msgCtx) {
 			continue
 		}
 		s.logPluginMatch(messagePlugin, msgCtx)
-		messagePlugin.Run(msgCtx)
+		result := vars.MessagePlugin.Execute(messagePlugin, msgCtx)
+		if result == plugin.RunContinue {
+			continue
+		}
+		if result == plugin.RunConsumed {
+			log.Printf("[PluginChain] plugin=%s consumed msg_id=%d", messagePlugin.GetName(), msgCtx.Message.MsgId)
+		}
+		return result
 	}
+	return plugin.RunContinue
+}
+
+// ProcessTextMessage 处理文本消息
+func (s *MessageService) ProcessTextMessage(message *model.Message, msgSettings settings.Settings) {
+	s.dispatchMessagePlugins(plugin.LabelText, s.newMessageContext(message, msgSettings, message.Content))
 }
 
 // ProcessImageMessage 处理图片消息
 func (s *MessageService) ProcessImageMessage(message *model.Message, msgSettings settings.Settings) {
-	msgCtx := &plugin.MessageContext{
-		Context:        s.ctx,
-		Settings:       msgSettings,
-		Message:        message,
-		MessageContent: message.Content,
-		MessageService: s,
+	s.dispatchMessagePlugins(plugin.LabelImage, s.newMessageContext(message, msgSettings, message.Content))
+}
+
+// ProcessVoiceMessage 处理语音消息，先交给语音插件链处理；开启语音识别后将语音转写为文本，再交给文本插件链处理
+func (s *MessageService) ProcessVoiceMessage(message *model.Message, msgSettings settings.Settings) {
+	transcript := s.transcribeInboundVoiceMessage(message, msgSettings)
+	if s.dispatchMessagePlugins(plugin.LabelVoice, s.newMessageContext(message, msgSettings, transcript)) != plugin.RunContinue {
+		return
 	}
-	for _, messagePlugin := range vars.MessagePlugin.Plugins {
-		if !slices.Contains(messagePlugin.GetLabels(), "image") {
-			continue
-		}
-		match := messagePlugin.Match(msgCtx)
-		if !match {
-			continue
-		}
-		s.logPluginMatch(messagePlugin, msgCtx)
-		messagePlugin.Run(msgCtx)
+	if transcript == "" {
+		return
+	}
+	if vars.MemoryService != nil {
+		go vars.MemoryService.NotifyMessage(context.Background(), message)
 	}
+	s.dispatchMessagePlugins(plugin.LabelText, s.newMessageContext(message, msgSettings, transcript))
 }
 
-// ProcessVoiceMessage 处理语音消息
-func (s *MessageService) ProcessVoiceMessage(message *model.Message) {
+// transcribeInboundVoiceMessage 识别收到的语音消息，未开启语音识别或识别失败时返回空字符串
+func (s *MessageService) transcribeInboundVoiceMessage(message *model.Message, msgSettings settings.Settings) string {
+	// 机器人自己发送的语音（如文本转语音的回复）不需要识别
+	if message.SenderWxID == vars.RobotRuntime.WxID {
+		return ""
+	}
+	// 没有用到识别结果的会话不下载语音，避免无谓的下载和识别费用
+	if !s.voiceTranscriptNeeded(msgSettings) {
+		return ""
+	}
+	globalSettings, err := repository.NewGlobalSettingsRepo(s.ctx, vars.DB).GetGlobalSettings()
+	if err != nil {
+		log.Printf("获取全局配置失败: %v", err)
+		return ""
+	}
+	asr := NewASRServiceFromSettings(globalSettings)
+	if asr == nil {
+		return ""
+	}
+	transcript, err := s.TranscribeVoiceMessage(asr, message)
+	if err != nil {
+		log.Printf("语音消息[%d]转文字失败: %v", message.MsgId, err)
+		return ""
+	}
+	return transcript
+}
 
+// voiceTranscriptNeeded 会话开启了 AI 对话，或者启用了语音插件时才需要识别语音
+func (s *MessageService) voiceTranscriptNeeded(msgSettings settings.Settings) bool {
+	if msgSettings == nil {
+		return false
+	}
+	if msgSettings.IsAIChatEnabled() {
+		return true
+	}
+	if vars.MessagePlugin == nil {
+		return false
+	}
+	for _, messagePlugin := range vars.MessagePlugin.GetPluginsByLabel(plugin.LabelVoice) {
+		if msgSettings.IsPluginEnabled(messagePlugin.GetName(), messagePlugin.GetLabels()) {
+			return true
+		}
+	}
+	return false
 }
 
-// ProcessVideoMessage 处理视频消息
-func (s *MessageService) ProcessVideoMessage(message *model.Message) {
+// TranscribeVoiceMessage 下载语音（silk 转 wav）并识别为文本，识别结果保存到消息上
+func (s *MessageService) TranscribeVoiceMessage(asr ai.ASRProvider, message *model.Message) (string, error) {
+	audio, _, extension, err := vars.RobotRuntime.DownloadVoice(s.ctx, *message)
+	if err != nil {
+		return "", fmt.Errorf("下载语音失败: %w", err)
+	}
+	transcript, err := asr.Transcribe(s.ctx, audio, fmt.Sprintf("%d%s", message.MsgId, extension))
+	if err != nil {
+		return "", err
+	}
+	message.Transcript = transcript
+	if err := s.msgRepo.UpdateTranscript(message.ID, transcript); err != nil {
+		log.Printf("保存语音转写文本失败: %v", err)
+	}
+	return transcript, nil
+}
 
+// ProcessVideoMessage 处理视频消息
+func (s *MessageService) ProcessVideoMessage(message *model.Message, msgSettings settings.Settings) {
+	var xmlMessage robot.VideoMessageXml
+	if err := vars.RobotRuntime.XmlDecoder(message.Content, &xmlMessage); err != nil {
+		log.Printf("解析视频消息失败: %v", err)
+		return
+	}
+	msgCtx := s.newMessageContext(message, msgSettings, message.Content)
+	msgCtx.Video = &xmlMessage.VideoMsg
+	s.dispatchMessagePlugins(plugin.LabelVideo, msgCtx)
 }
 
 // ProcessEmojiMessage 处理表情消息
-func (s *MessageService) ProcessEmojiMessage(message *model.Message) {
-
+func (s *MessageService) ProcessEmojiMessage(message *model.Message, msgSettings settings.Settings) {
+	var xmlMessage robot.XMLEmojiMessage
+	if err := vars.RobotRuntime.XmlDecoder(message.Content, &xmlMessage); err != nil {
+		log.Printf("解析表情消息失败: %v", err)
+		return
+	}
+	msgCtx := s.newMessageContext(message, msgSettings, message.Content)
+	msgCtx.Emoji = &xmlMessage.Emoji
+	s.dispatchMessagePlugins(plugin.LabelEmoji, msgCtx)
 }
 
 // ProcessReferMessage 处理引用消息
@@ -163,64 +256,24 @@ func (s *MessageService) ProcessReferMessage(message *model.Message, msgSettings
 		log.Printf("获取引用消息为空")
 		return
 	}
-	msgCtx := &plugin.MessageContext{
-		Context:        s.ctx,
-		Settings:       msgSettings,
-		Message:        message,
-		MessageContent: xmlMessage.AppMsg.Title,
-		ReferMessage:   referMessage,
-		MessageService: s,
-	}
-	for _, messagePlugin := range vars.MessagePlugin.Plugins {
-		if !slices.Contains(messagePlugin.GetLabels(), "text") {
-			continue
-		}
-		match := messagePlugin.Match(msgCtx)
-		if !match {
-			continue
-		}
-		s.logPluginMatch(messagePlugin, msgCtx)
-		messagePlugin.Run(msgCtx)
-	}
-}
-
-func (s *MessageService) ProcessRedEnvelopesMessage(message *model.Message, msgSettings settings.Settings) {
-	msgCtx := &plugin.MessageContext{
-		Context:        s.ctx,
-		Settings:       msgSettings,
-		Message:        message,
-		MessageContent: message.Content,
-		MessageService: s,
-	}
-	for _, messagePlugin := range vars.MessagePlugin.Plugins {
-		if !slices.Contains(messagePlugin.GetLabels(), "red-envelopes") {
-			continue
-		}
-		match := messagePlugin.Match(msgCtx)
-		if !match {
-			continue
-		}
-		s.logPluginMatch(messagePlugin, msgCtx)
-		messagePlugin.Run(msgCtx)
-	}
+	msgCtx := s.newMessageContext(message, msgSettings, xmlMessage.AppMsg.Title)
+	msgCtx.ReferMessage = referMessage
+	msgCtx.AppMessage = &xmlMessage.AppMsg
+	s.dispatchMessagePlugins(plugin.LabelText, msgCtx)
 }
 
-// ProcessAppMessage 处理应用消息
+// ProcessAppMessage 处理应用消息，按应用消息子类型分发到对应标签的插件
 func (s *MessageService) ProcessAppMessage(message *model.Message, msgSettings settings.Settings) {
 	if message.AppMsgType == model.AppMsgTypequote {
 		s.ProcessReferMessage(message, msgSettings)
 		return
 	}
-	if message.AppMsgType == model.AppMsgTypeRedEnvelopes {
-		s.ProcessRedEnvelopesMessage(message, msgSettings)
+	xmlMessage, err := s.XmlDecoder(message.Content)
+	if err != nil {
+		log.Printf("解析应用消息失败: %v", err)
 		return
 	}
 	if message.AppMsgType == model.AppMsgTypeUrl {
-		xmlMessage, err := s.XmlDecoder(message.Content)
-		if err != nil {
-			log.Printf("解析应用消息失败: %v", err)
-			return
-		}
 		if xmlMessage.AppMsg.Title == "邀请你加入群聊" || xmlMessage.AppMsg.Title == "Group Chat Invitation" {
 			now := time.Now().Unix()
 			err := s.sysmsgRepo.Create(&model.SystemMessage{
@@ -251,17 +304,27 @@ func (s *MessageService) ProcessAppMessage(message *model.Message, msgSettings s
 			}
 			return
 		}
-		return
 	}
+	// MessageContent 保持为原始 XML，与红包等已有插件保持一致
+	msgCtx := s.newMessageContext(message, msgSettings, message.Content)
+	msgCtx.AppMessage = &xmlMessage.AppMsg
+	s.dispatchMessagePlugins(plugin.AppMessageLabel(message.AppMsgType), msgCtx)
 }
 
 // ProcessShareCardMessage 处理分享名片消息
-func (s *MessageService) ProcessShareCardMessage(message *model.Message) {
-
+func (s *MessageService) ProcessShareCardMessage(message *model.Message, msgSettings settings.Settings) {
+	var shareCard robot.ShareCardMessage
+	if err := vars.RobotRuntime.XmlDecoder(message.Content, &shareCard); err != nil {
+		log.Printf("解析名片消息失败: %v", err)
+		return
+	}
+	msgCtx := s.newMessageContext(message, msgSettings, shareCard.Nickname)
+	msgCtx.ShareCard = &shareCard
+	s.dispatchMessagePlugins(plugin.LabelShareCard, msgCtx)
 }
 
 // ProcessFriendVerifyMessage 处理好友添加请求通知消息
-func (s *MessageService) ProcessFriendVerifyMessage(message *model.Message) {
+func (s *MessageService) ProcessFriendVerifyMessage(message *model.Message, msgSettings settings.Settings) {
 	now := time.Now().Unix()
 	var xmlMessage robot.NewFriendMessage
 	err := vars.RobotRuntime.XmlDecoder(message.Content, &xmlMessage)
@@ -298,6 +361,10 @@ func (s *MessageService) ProcessFriendVerifyMessage(message *model.Message) {
 		}
 	}(systeMessage.ID)
 
+	msgCtx := s.newMessageContext(message, msgSettings, xmlMessage.Content)
+	msgCtx.FriendVerify = &xmlMessage
+	s.dispatchMessagePlugins(plugin.LabelVerify, msgCtx)
+
 	if message.ID > 0 {
 		// 消息已经没什么用了，删除掉
 		err := s.msgRepo.Delete(message)
@@ -308,52 +375,60 @@ func (s *MessageService) ProcessFriendVerifyMessage(message *model.Message) {
 	}
 }
 
-// ProcessRecalledMessage 处理撤回消息
-func (s *MessageService) ProcessRecalledMessage(message *model.Message, msgXml robot.SystemMessage) {
+// ProcessRecalledMessage 处理撤回消息，标记原消息已撤回并保存撤回记录，再交给撤回插件链处理（如防撤回）
+func (s *MessageService) ProcessRecalledMessage(message *model.Message, msgXml robot.SystemMessage, msgSettings settings.Settings) {
 	oldMsg, err := s.msgRepo.GetByMsgID(msgXml.RevokeMsg.NewMsgID)
 	if err != nil {
 		log.Printf("获取撤回的消息失败: %v", err)
 		return
 	}
-	if oldMsg != nil {
-		oldMsg.IsRecalled = true
-		err = s.msgRepo.Update(oldMsg)
+	if oldMsg == nil {
+		return
+	}
+	oldMsg.IsRecalled = true
+	err = s.msgRepo.Update(oldMsg)
+	if err != nil {
+		log.Printf("标记撤回消息失败: %v", err)
+		return
+	}
+	recall := &model.MessageRecall{
+		MessageID:        oldMsg.ID,
+		MsgID:            oldMsg.MsgId,
+		FromWxID:         oldMsg.FromWxID,
+		SenderWxID:       oldMsg.SenderWxID,
+		RecalledBy:       message.SenderWxID,
+		Type:             oldMsg.Type,
+		AppMsgType:       oldMsg.AppMsgType,
+		Content:          oldMsg.Content,
+		Transcript:       oldMsg.Transcript,
+		AttachmentUrl:    oldMsg.AttachmentUrl,
+		ReplaceMsg:       msgXml.RevokeMsg.ReplaceMsg,
+		MessageCreatedAt: oldMsg.CreatedAt,
+		RecalledAt:       message.CreatedAt,
+	}
+	if err := s.recallRepo.Create(recall); err != nil {
+		log.Printf("保存撤回记录失败: %v", err)
+	} else {
+		msgCtx := s.newMessageContext(message, msgSettings, msgXml.RevokeMsg.ReplaceMsg)
+		msgCtx.SystemMessage = &msgXml
+		msgCtx.Recall = recall
+		s.dispatchMessagePlugins(plugin.LabelRecall, msgCtx)
+	}
+	if message.ID > 0 {
+		// 消息已经没什么用了，删除掉
+		err := s.msgRepo.Delete(message)
 		if err != nil {
-			log.Printf("标记撤回消息失败: %v", err)
-		} else {
-			if message.ID > 0 {
-				// 消息已经没什么用了，删除掉
-				err := s.msgRepo.Delete(message)
-				if err != nil {
-					log.Printf("删除消息失败: %v", err)
-					return
-				}
-			}
+			log.Printf("删除消息失败: %v", err)
 		}
-		return
 	}
 }
 
 // ProcessPatMessage 处理拍一拍消息
 func (s *MessageService) ProcessPatMessage(message *model.Message, msgXml robot.SystemMessage, msgSettings settings.Settings) {
-	msgCtx := &plugin.MessageContext{
-		Context:        s.ctx,
-		Settings:       msgSettings,
-		Message:        message,
-		MessageContent: message.Content,
-		Pat:            message.IsChatRoom && msgXml.Pat.PattedUsername == vars.RobotRuntime.WxID,
-		MessageService: s,
-	}
-	for _, messagePlugin := range vars.MessagePlugin.Plugins {
-		if slices.Contains(messagePlugin.GetLabels(), "pat") {
-			match := messagePlugin.Match(msgCtx)
-			if !match {
-				continue
-			}
-			s.logPluginMatch(messagePlugin, msgCtx)
-			messagePlugin.Run(msgCtx)
-		}
-	}
+	msgCtx := s.newMessageContext(message, msgSettings, message.Content)
+	msgCtx.Pat = message.IsChatRoom && msgXml.Pat.PattedUsername == vars.RobotRuntime.WxID
+	msgCtx.SystemMessage = &msgXml
+	s.dispatchMessagePlugins(plugin.LabelPat, msgCtx)
 }
 
 func (s *MessageService) ProcessNewChatRoomMemberMessage(message *model.Message, msgXml robot.SystemMessage) {
@@ -451,7 +526,7 @@ func (s *MessageService) ProcessSystemMessage(message *model.Message, msgSetting
 		return
 	}
 	if msgXml.Type == "revokemsg" {
-		s.ProcessRecalledMessage(message, msgXml)
+		s.ProcessRecalledMessage(message, msgXml, msgSettings)
 		return
 	}
 	if msgXml.Type == "pat" {
@@ -465,16 +540,61 @@ func (s *MessageService) ProcessSystemMessage(message *model.Message, msgSetting
 		s.ProcessNewChatRoomMemberMessage(message, msgXml)
 		return
 	}
+	msgCtx := s.newMessageContext(message, msgSettings, message.Content)
+	msgCtx.SystemMessage = &msgXml
+	s.dispatchMessagePlugins(plugin.LabelSystem, msgCtx)
 }
 
-// ProcessLocationMessage 处理位置消息
-func (s *MessageService) ProcessLocationMessage(message *model.Message) {
+// ProcessLocationMessage 处理位置消息，解析后保存，供 AI 上下文和位置查询工具使用
+func (s *MessageService) ProcessLocationMessage(message *model.Message, msgSettings settings.Settings) {
+	location, err := s.ParseLocationMessage(message)
+	if err != nil {
+		log.Printf("解析位置消息失败: %v", err)
+		return
+	}
+	err = s.locationRepo.Create(&model.MessageLocation{
+		MessageID:  message.ID,
+		MsgID:      message.MsgId,
+		FromWxID:   message.FromWxID,
+		SenderWxID: message.SenderWxID,
+		Latitude:   location.X,
+		Longitude:  location.Y,
+		Scale:      location.Scale,
+		Label:      location.Label,
+		PoiName:    location.PoiName,
+		PoiID:      location.PoiID,
+		CreatedAt:  message.CreatedAt,
+	})
+	if err != nil {
+		log.Printf("保存位置消息失败: %v", err)
+		return
+	}
+	// 群聊的 AI 上下文只包含参与过 AI 对话的消息，分享的位置直接加入上下文
+	if message.IsChatRoom {
+		if err := s.SetMessageIsInContext(message); err != nil {
+			log.Printf("更新消息上下文失败: %v", err)
+		}
+	}
+	msgCtx := s.newMessageContext(message, msgSettings, location.Describe())
+	msgCtx.Location = location
+	s.dispatchMessagePlugins(plugin.LabelLocation, msgCtx)
+}
 
+// ParseLocationMessage 解析位置消息
+func (s *MessageService) ParseLocationMessage(message *model.Message) (*robot.LocationMessage, error) {
+	if message.Type != model.MsgTypeLocation {
+		return nil, errors.New("消息类型错误")
+	}
+	var xmlMessage robot.XMLLocationMessage
+	if err := vars.RobotRuntime.XmlDecoder(message.Content, &xmlMessage); err != nil {
+		return nil, err
+	}
+	return &xmlMessage.Location, nil
 }
 
 // ProcessPromptMessage 处理提示消息
-func (s *MessageService) ProcessPromptMessage(message *model.Message) {
-
+func (s *MessageService) ProcessPromptMessage(message *model.Message, msgSettings settings.Settings) {
+	s.dispatchMessagePlugins(plugin.LabelPrompt, s.newMessageContext(message, msgSettings, message.Content))
 }
 
 func (s *MessageService) ProcessMessageSender(message *model.Message) {
@@ -565,6 +685,7 @@ func (s *MessageService) InitSettingsByMessage(message *model.Message) (settings
 }
 
 func (s *MessageService) ProcessMessage(syncResp robot.SyncMessage) {
+	dedup := NewMessageDeduplicator(s.ctx)
 	for _, message := range syncResp.AddMsgs {
 		now := time.Now().Unix()
 		m := model.Message{
@@ -588,9 +709,21 @@ func (s *MessageService) ProcessMessage(syncResp robot.SyncMessage) {
 		if settings == nil {
 			continue
 		}
+		// 同一条消息可能被重复推送（回调重试、长连接重连等），重复的消息在入库和插件处理前丢弃
+		if !dedup.Claim(m.MsgId) {
+			log.Printf("丢弃重复消息: msg_id=%d from=%s", m.MsgId, m.FromWxID)
+			continue
+		}
 		err := s.msgRepo.Create(&m)
+		if repository.IsDuplicateKeyError(err) {
+			// msg_id 有唯一索引，入库冲突说明消息已经处理过
+			dedup.Duplicated()
+			log.Printf("丢弃重复消息: msg_id=%d from=%s", m.MsgId, m.FromWxID)
+			continue
+		}
 		if err != nil {
 			log.Printf("入库消息失败: %v", err)
+			dedup.Release(m.MsgId)
 			continue
 		}
 		if m.Type == model.MsgTypeText && vars.MemoryService != nil {
@@ -600,44 +733,17 @@ func (s *MessageService) ProcessMessage(syncResp robot.SyncMessage) {
 			// 消息太旧了，不处理了
 			continue
 		}
-		switch m.Type {
-		case model.MsgTypeText:
-			go s.ProcessTextMessage(&m, settings)
-		case model.MsgTypeImage:
-			go s.ProcessImageMessage(&m, settings)
-		case model.MsgTypeVoice:
-			go s.ProcessVoiceMessage(&m)
-		case model.MsgTypeVideo:
-			go s.ProcessVideoMessage(&m)
-		case model.MsgTypeEmoticon:
-			go s.ProcessEmojiMessage(&m)
-		case model.MsgTypeApp:
-			go s.ProcessAppMessage(&m, settings)
-		case model.MsgTypeShareCard:
-			go s.ProcessShareCardMessage(&m)
-		case model.MsgTypeVerify:
-			// 好友添加请求通知消息
-			go s.ProcessFriendVerifyMessage(&m)
-		case model.MsgTypeSystem:
-			go s.ProcessSystemMessage(&m, settings)
-		case model.MsgTypeLocation:
-			go s.ProcessLocationMessage(&m)
-		case model.MsgTypePrompt:
-			go s.ProcessPromptMessage(&m)
-		default:
-			// 未知消息类型
-			log.Printf("未知消息类型: %d, 内容: %s", m.Type, m.Content)
-		}
-		go func() {
-			// 插入一条联系人记录，获取联系人列表接口获取不到未保存到通讯录的群聊
-			NewContactService(s.ctx).InsertOrUpdateContactActiveTime(m.FromWxID)
-			if strings.HasSuffix(m.FromWxID, "@chatroom") {
-				NewChatRoomService(s.ctx).UpsertChatRoomMember(&model.ChatRoomMember{
-					ChatRoomID: m.FromWxID,
-					WechatID:   m.SenderWxID,
-				})
-			}
-		}()
+		// 工具审批的回复不能排在等待审批的会话后面，在进入消息处理池之前处理
+		if NewToolApprovalService(s.ctx).HandleReply(&m) {
+			continue
+		}
+		// 同一会话的消息按顺序处理，整体并发受 worker 池限制
+		// 消息可能在请求结束后才被处理，不能再使用请求的上下文
+		GetMessageDispatcher().SubmitReleasable(m.FromWxID, func(release func()) {
+			messageLaneReleasers.Store(m.ID, release)
+			defer messageLaneReleasers.Delete(m.ID)
+			NewMessageService(context.Background()).dispatchMessage(&m, settings)
+		})
 	}
 	for _, contact := range syncResp.ModContacts {
 		if contact.UserName.String != nil {
@@ -662,45 +768,90 @@ func (s *MessageService) ProcessMessage(syncResp robot.SyncMessage) {
 	s.MessageWebhook(syncResp)
 }
 
+// dispatchMessage 在消息处理池中按消息类型处理一条入站消息
+func (s *MessageService) dispatchMessage(m *model.Message, msgSettings settings.Settings) {
+	// 插入一条联系人记录，获取联系人列表接口获取不到未保存到通讯录的群聊
+	NewContactService(s.ctx).InsertOrUpdateContactActiveTime(m.FromWxID)
+	if strings.HasSuffix(m.FromWxID, "@chatroom") {
+		NewChatRoomService(s.ctx).UpsertChatRoomMember(&model.ChatRoomMember{
+			ChatRoomID: m.FromWxID,
+			WechatID:   m.SenderWxID,
+		})
+	}
+	switch m.Type {
+	case model.MsgTypeText:
+		s.ProcessTextMessage(m, msgSettings)
+	case model.MsgTypeImage:
+		s.ProcessImageMessage(m, msgSettings)
+	case model.MsgTypeVoice:
+		s.ProcessVoiceMessage(m, msgSettings)
+	case model.MsgTypeVideo, model.MsgTypeMicroVideo:
+		s.ProcessVideoMessage(m, msgSettings)
+	case model.MsgTypeEmoticon:
+		s.ProcessEmojiMessage(m, msgSettings)
+	case model.MsgTypeApp:
+		s.ProcessAppMessage(m, msgSettings)
+	case model.MsgTypeShareCard:
+		s.ProcessShareCardMessage(m, msgSettings)
+	case model.MsgTypeVerify:
+		// 好友添加请求通知消息
+		s.ProcessFriendVerifyMessage(m, msgSettings)
+	case model.MsgTypeSystem:
+		s.ProcessSystemMessage(m, msgSettings)
+	case model.MsgTypeLocation:
+		s.ProcessLocationMessage(m, msgSettings)
+	case model.MsgTypePrompt:
+		s.ProcessPromptMessage(m, msgSettings)
+	default:
+		// 未知消息类型
+		log.Printf("未知消息类型: %d, 内容: %s", m.Type, m.Content)
+	}
+}
+
 func (s *MessageService) MessageWebhook(syncResp robot.SyncMessage) {
 	if vars.Webhook.URL != "" {
-		req := resty.New().R().
-			SetHeader("Content-Type", "application/json;chartset=utf-8").
-			SetBody(syncResp)
-
-		// 设置自定义 headers
-		if vars.Webhook.Headers != nil {
-			for k, v := range vars.Webhook.Headers {
-				switch val := v.(type) {
-				case string:
-					// 单个字符串值
-					req.SetHeader(k, val)
-				case []string:
-					// 字符串数组,设置多个相同 key 的 header
-					for _, headerVal := range val {
-						req.SetHeader(k, headerVal)
-					}
-				case []any:
-					// any 数组,尝试转换为字符串
-					for _, item := range val {
-						if strVal, ok := item.(string); ok {
-							req.SetHeader(k, strVal)
-						}
+		postWebhook(syncResp)
+	}
+}
+
+// postWebhook 将 body 推送到 webhook，带上自定义 headers 和机器人信息
+func postWebhook(body any) {
+	req := resty.New().R().
+		SetHeader("Content-Type", "application/json;chartset=utf-8").
+		SetBody(body)
+
+	// 设置自定义 headers
+	if vars.Webhook.Headers != nil {
+		for k, v := range vars.Webhook.Headers {
+			switch val := v.(type) {
+			case string:
+				// 单个字符串值
+				req.SetHeader(k, val)
+			case []string:
+				// 字符串数组,设置多个相同 key 的 header
+				for _, headerVal := range val {
+					req.SetHeader(k, headerVal)
+				}
+			case []any:
+				// any 数组,尝试转换为字符串
+				for _, item := range val {
+					if strVal, ok := item.(string); ok {
+						req.SetHeader(k, strVal)
 					}
 				}
 			}
 		}
+	}
 
-		webhookUrl := vars.Webhook.URL
-		if strings.Contains(webhookUrl, "?") {
-			webhookUrl += fmt.Sprintf("&robot_id=%d&robot_code=%s&robot_wxid=%s", vars.RobotRuntime.RobotID, vars.RobotRuntime.RobotCode, vars.RobotRuntime.WxID)
-		} else {
-			webhookUrl += fmt.Sprintf("?robot_id=%d&robot_code=%s&robot_wxid=%s", vars.RobotRuntime.RobotID, vars.RobotRuntime.RobotCode, vars.RobotRuntime.WxID)
-		}
-		_, err := req.Post(webhookUrl)
-		if err != nil {
-			log.Println("消息 webhook 调用失败: ", err.Error())
-		}
+	webhookUrl := vars.Webhook.URL
+	if strings.Contains(webhookUrl, "?") {
+		webhookUrl += fmt.Sprintf("&robot_id=%d&robot_code=%s&robot_wxid=%s", vars.RobotRuntime.RobotID, vars.RobotRuntime.RobotCode, vars.RobotRuntime.WxID)
+	} else {
+		webhookUrl += fmt.Sprintf("?robot_id=%d&robot_code=%s&robot_wxid=%s", vars.RobotRuntime.RobotID, vars.RobotRuntime.RobotCode, vars.RobotRuntime.WxID)
+	}
+	_, err := req.Post(webhookUrl)
+	if err != nil {
+		log.Println("webhook 调用失败: ", err.Error())
 	}
 }
 
@@ -708,8 +859,11 @@ func (s *MessageService) SyncMessage() {
 	// 获取新消息
 	syncResp, err := vars.RobotRuntime.SyncMessage()
 	if err != nil {
-		// 有可能是用户退出了，或者掉线了，这里不处理，由心跳机制处理机器人在线/离线状态
 		log.Println("获取新消息失败: ", err)
+		if errors.Is(err, robot.ErrLoggedOut) {
+			GetRobotSession().LoggedOut(err.Error())
+		}
+		// 其他情况有可能是掉线了，由心跳机制处理机器人在线/离线状态
 		return
 	}
 	if len(syncResp.AddMsgs) == 0 {
@@ -744,6 +898,12 @@ func (s *MessageService) MessageRevoke(req dto.MessageCommonRequest) error {
 }
 
 func (s *MessageService) SendTextMessage(toWxID, content string, at ...string) error {
+	_, err := s.sendTextMessage(toWxID, content, at...)
+	return err
+}
+
+// sendTextMessage 发送文本消息，返回入库的消息
+func (s *MessageService) sendTextMessage(toWxID, content string, at ...string) (*model.Message, error) {
 	atContent := ""
 	if len(at) > 0 {
 		// 手动拼接上 @ 符号和昵称
@@ -793,10 +953,11 @@ func (s *MessageService) SendTextMessage(toWxID, content string, at ...string) e
 	content = atContent + content
 	newMessages, err := vars.RobotRuntime.SendTextMessage(toWxID, content, at...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 通过机器人发送的消息，消息同步接口获取不到，所以这里需要手动入库
+	var sent *model.Message
 	if len(newMessages.List) > 0 {
 		for _, message := range newMessages.List {
 			if message.Ret == 0 {
@@ -821,13 +982,16 @@ func (s *MessageService) SendTextMessage(toWxID, content string, at ...string) e
 				if err != nil {
 					log.Printf("入库消息失败: %v", err)
 				}
+				if sent == nil {
+					sent = &m
+				}
 				// 插入一条联系人记录，获取联系人列表接口获取不到未保存到通讯录的群聊
 				NewContactService(s.ctx).InsertOrUpdateContactActiveTime(m.FromWxID)
 			}
 		}
 	}
 
-	return nil
+	return sent, nil
 }
 
 func (s *MessageService) ToolsCompleted(toWxID, replyWxID string) error {
@@ -863,9 +1027,14 @@ func (s *MessageService) SendGroupMassMsgText(toWxID []string, content string) e
 }
 
 func (s *MessageService) SendAppMessage(toWxID string, appMsgType int, appMsgXml string) error {
+	_, err := s.sendAppMessage(toWxID, appMsgType, appMsgXml)
+	return err
+}
+
+func (s *MessageService) sendAppMessage(toWxID string, appMsgType int, appMsgXml string) (*model.Message, error) {
 	message, err := vars.RobotRuntime.SendAppMessage(toWxID, appMsgType, appMsgXml)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	m := model.Message{
@@ -890,7 +1059,7 @@ func (s *MessageService) SendAppMessage(toWxID string, appMsgType int, appMsgXml
 	// 插入一条联系人记录，获取联系人列表接口获取不到未保存到通讯录的群聊
 	NewContactService(s.ctx).InsertOrUpdateContactActiveTime(m.FromWxID)
 
-	return nil
+	return &m, nil
 }
 
 // 发送图片信息
@@ -930,6 +1099,11 @@ func (s *MessageService) MsgUploadImg(toWxID string, image io.Reader) (*model.Me
 
 // SendImageMessageByRemoteURL 根据远程URL发送图片（优先使用分片下载，不支持则回退到普通下载）
 func (s *MessageService) SendImageMessageByRemoteURL(toWxID string, imageURL string) error {
+	_, err := s.sendImageMessageByRemoteURL(toWxID, imageURL)
+	return err
+}
+
+func (s *MessageService) sendImageMessageByRemoteURL(toWxID string, imageURL string) (*model.Message, error) {
 	// 使用 Range 请求第一个字节来探测是否支持分片下载
 	rangeHeader := "bytes=0-0"
 	testResp, err := resty.New().R().
@@ -937,13 +1111,13 @@ func (s *MessageService) SendImageMessageByRemoteURL(toWxID string, imageURL str
 		SetDoNotParseResponse(true).
 		Get(imageURL)
 	if err != nil {
-		return fmt.Errorf("获取图片信息失败: %w", err)
+		return nil, fmt.Errorf("获取图片信息失败: %w", err)
 	}
 	testResp.RawBody().Close()
 
 	if testResp.StatusCode() != 206 && testResp.StatusCode() != 200 {
 		log.Printf("获取图片信息失败，HTTP状态码: %d\n", testResp.StatusCode())
-		return fmt.Errorf("获取图片信息失败，HTTP状态码: %d", testResp.StatusCode())
+		return nil, fmt.Errorf("获取图片信息失败，HTTP状态码: %d", testResp.StatusCode())
 	}
 
 	// 如果返回 206，说明支持 Range 请求
@@ -980,7 +1154,8 @@ func (s *MessageService) SendImageMessageByRemoteURL(toWxID string, imageURL str
 	chunkSize := vars.UploadImageChunkSize
 	totalChunks := (contentLength + chunkSize - 1) / chunkSize
 
-	// 分片下载并上传
+	// 分片下载并上传，最后一个分片上传完成后返回入库的消息
+	var message *model.Message
 	for chunkIndex := range totalChunks {
 		start := int64(chunkIndex) * chunkSize
 		end := start + chunkSize - 1
@@ -995,7 +1170,7 @@ func (s *MessageService) SendImageMessageByRemoteURL(toWxID string, imageURL str
 			SetDoNotParseResponse(true).
 			Get(imageURL)
 		if err != nil {
-			return fmt.Errorf("下载图片分片失败 (chunk %d/%d): %w", chunkIndex+1, totalChunks, err)
+			return nil, fmt.Errorf("下载图片分片失败 (chunk %d/%d): %w", chunkIndex+1, totalChunks, err)
 		}
 
 		// 如果第一个分片就不支持 Range，回退到普通下载
@@ -1007,14 +1182,14 @@ func (s *MessageService) SendImageMessageByRemoteURL(toWxID string, imageURL str
 
 		if resp.StatusCode() != 206 && resp.StatusCode() != 200 {
 			resp.RawBody().Close()
-			return fmt.Errorf("下载图片分片失败，HTTP状态码: %d (chunk %d/%d)", resp.StatusCode(), chunkIndex+1, totalChunks)
+			return nil, fmt.Errorf("下载图片分片失败，HTTP状态码: %d (chunk %d/%d)", resp.StatusCode(), chunkIndex+1, totalChunks)
 		}
 
 		// 读取分片数据
 		chunkData, err := io.ReadAll(resp.RawBody())
 		resp.RawBody().Close()
 		if err != nil {
-			return fmt.Errorf("读取分片数据失败 (chunk %d/%d): %w", chunkIndex+1, totalChunks, err)
+			return nil, fmt.Errorf("读取分片数据失败 (chunk %d/%d): %w", chunkIndex+1, totalChunks, err)
 		}
 
 		// 创建分片请求
@@ -1035,36 +1210,36 @@ func (s *MessageService) SendImageMessageByRemoteURL(toWxID string, imageURL str
 		}
 
 		// 发送分片
-		_, err = s.SendImageMessageStream(s.ctx, req, chunkReader, chunkHeader)
+		message, err = s.SendImageMessageStream(s.ctx, req, chunkReader, chunkHeader)
 		if err != nil {
-			return fmt.Errorf("发送图片分片失败 (chunk %d/%d): %w", chunkIndex+1, totalChunks, err)
+			return nil, fmt.Errorf("发送图片分片失败 (chunk %d/%d): %w", chunkIndex+1, totalChunks, err)
 		}
 	}
 
-	return nil
+	return message, nil
 }
 
 // sendImageByNormalDownload 普通下载方式（一次性下载，分片上传）
-func (s *MessageService) sendImageByNormalDownload(toWxID string, imageURL string) error {
+func (s *MessageService) sendImageByNormalDownload(toWxID string, imageURL string) (*model.Message, error) {
 	resp, err := resty.New().R().SetDoNotParseResponse(true).Get(imageURL)
 	if err != nil {
-		return fmt.Errorf("下载图片失败: %w", err)
+		return nil, fmt.Errorf("下载图片失败: %w", err)
 	}
 	defer resp.RawBody().Close()
 
 	if resp.StatusCode() != 200 {
-		return fmt.Errorf("下载图片失败，HTTP状态码: %d", resp.StatusCode())
+		return nil, fmt.Errorf("下载图片失败，HTTP状态码: %d", resp.StatusCode())
 	}
 
 	// 读取整个图片到内存
 	imageData, err := io.ReadAll(resp.RawBody())
 	if err != nil {
-		return fmt.Errorf("读取图片数据失败: %w", err)
+		return nil, fmt.Errorf("读取图片数据失败: %w", err)
 	}
 
 	contentLength := int64(len(imageData))
 	if contentLength == 0 {
-		return fmt.Errorf("图片数据为空")
+		return nil, fmt.Errorf("图片数据为空")
 	}
 
 	// 生成唯一的客户端图片ID
@@ -1075,6 +1250,7 @@ func (s *MessageService) sendImageByNormalDownload(toWxID string, imageURL strin
 	totalChunks := (contentLength + chunkSize - 1) / chunkSize
 
 	// 分片上传
+	var message *model.Message
 	for chunkIndex := range totalChunks {
 		start := int64(chunkIndex) * chunkSize
 		end := start + chunkSize
@@ -1103,13 +1279,13 @@ func (s *MessageService) sendImageByNormalDownload(toWxID string, imageURL strin
 		}
 
 		// 发送分片
-		_, err = s.SendImageMessageStream(s.ctx, req, chunkReader, chunkHeader)
+		message, err = s.SendImageMessageStream(s.ctx, req, chunkReader, chunkHeader)
 		if err != nil {
-			return fmt.Errorf("发送图片分片失败 (chunk %d/%d): %w", chunkIndex+1, totalChunks, err)
+			return nil, fmt.Errorf("发送图片分片失败 (chunk %d/%d): %w", chunkIndex+1, totalChunks, err)
 		}
 	}
 
-	return nil
+	return message, nil
 }
 
 // 分片发送图片信息
@@ -1455,12 +1631,17 @@ func (s *MessageService) SendVoiceMessageByLocalPath(toWxID string, voicePath st
 }
 
 func (s *MessageService) SendLongTextMessage(toWxID string, longText string) error {
+	_, err := s.sendLongTextMessage(toWxID, longText)
+	return err
+}
+
+func (s *MessageService) sendLongTextMessage(toWxID string, longText string) (*model.Message, error) {
 	currentRobot, err := s.robotAdminRepo.GetByWeChatID(vars.RobotRuntime.WxID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if currentRobot == nil || currentRobot.Nickname == nil {
-		return fmt.Errorf("未找到机器人信息")
+		return nil, fmt.Errorf("未找到机器人信息")
 	}
 
 	dataID := uuid.New().String()
@@ -1494,7 +1675,7 @@ func (s *MessageService) SendLongTextMessage(toWxID string, longText string) err
 
 	recordInfoBytes, err := xml.MarshalIndent(recordInfo, "", "  ")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	newMsg := robot.ChatHistoryMessage{
@@ -1512,7 +1693,7 @@ func (s *MessageService) SendLongTextMessage(toWxID string, longText string) err
 	}
 	message, err := vars.RobotRuntime.SendChatHistoryMessage(toWxID, newMsg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	m := model.Message{
@@ -1537,7 +1718,7 @@ func (s *MessageService) SendLongTextMessage(toWxID string, longText string) err
 	// 插入一条联系人记录，获取联系人列表接口获取不到未保存到通讯录的群聊
 	NewContactService(s.ctx).InsertOrUpdateContactActiveTime(m.FromWxID)
 
-	return nil
+	return &m, nil
 }
 
 func (s *MessageService) SendMusicMessage(toWxID string, songTitle string) error {
@@ -1677,6 +1858,67 @@ func (s *MessageService) SendFileMessageByLocalPath(toWxID string, localFilePath
 	})
 }
 
+// SendFileMessageByRemoteURL 下载远程文件后发送，文件名取 URL 路径中的文件名
+func (s *MessageService) SendFileMessageByRemoteURL(toWxID string, fileURL string) error {
+	localFilePath, cleanup, err := downloadRemoteFile(fileURL)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return s.SendFileMessageByLocalPath(toWxID, localFilePath)
+}
+
+// SendVoiceMessageByRemoteURL 下载远程音频后发送
+func (s *MessageService) SendVoiceMessageByRemoteURL(toWxID string, voiceURL string) error {
+	localFilePath, cleanup, err := downloadRemoteFile(voiceURL)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return s.SendVoiceMessageByLocalPath(toWxID, localFilePath)
+}
+
+// downloadRemoteFile 下载远程文件到临时目录，保留 URL 路径中的文件名，使用完后调用 cleanup 删除
+func downloadRemoteFile(fileURL string) (string, func(), error) {
+	parsedURL, err := url.Parse(fileURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("文件地址无效: %w", err)
+	}
+	filename := path.Base(parsedURL.Path)
+	if filename == "" || filename == "." || filename == "/" {
+		return "", nil, fmt.Errorf("无法从文件地址中获取文件名")
+	}
+
+	resp, err := resty.New().R().SetDoNotParseResponse(true).Get(fileURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("下载文件失败: %w", err)
+	}
+	defer resp.RawBody().Close()
+	if resp.StatusCode() != 200 {
+		return "", nil, fmt.Errorf("下载文件失败，HTTP状态码: %d", resp.StatusCode())
+	}
+
+	tempDir, err := os.MkdirTemp("", "remote_file_*")
+	if err != nil {
+		return "", nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+
+	localFilePath := filepath.Join(tempDir, filename)
+	localFile, err := os.Create(localFilePath)
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	_, err = io.Copy(localFile, resp.RawBody())
+	localFile.Close()
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("保存文件失败: %w", err)
+	}
+	return localFilePath, cleanup, nil
+}
+
 func (s *MessageService) ValidateLocalFileForSend(filePath string, allowedExts map[string]bool, maxSize int64, fileType string) (os.FileInfo, string, error) {
 	trimmedPath := strings.TrimSpace(filePath)
 	if trimmedPath == "" {
@@ -2045,6 +2287,9 @@ func (s *MessageService) buildQuoteAIMessage(msg *model.Message, isAssistant boo
 		if !ok {
 			return openai.ChatCompletionMessageParamUnion{}, false
 		}
+		if referMsg.Transcript != "" {
+			return s.aiTextPartMessage(isAssistant, "语音内容: "+referMsg.Transcript, xmlMessage.AppMsg.Title), true
+		}
 		return s.aiTextPartMessage(isAssistant, xmlMessage.AppMsg.Title+"\n\n 语音地址: "+referMsg.AttachmentUrl), true
 	case int(model.MsgTypeVideo):
 		referMsg, ok := s.getReferMessageByMsgID(xmlMessage.AppMsg.ReferMsg.SvrID)
@@ -2180,6 +2425,14 @@ func (s *MessageService) buildAIMessageContextMessage(msg *model.Message) (opena
 		return s.aiTextMessage(isAssistant, msg.Content), true
 	case msg.Type == model.MsgTypeImage && msg.AttachmentUrl != "":
 		return s.aiTextPartMessage(isAssistant, "图片地址: "+msg.AttachmentUrl), true
+	case msg.Type == model.MsgTypeLocation:
+		location, err := s.ParseLocationMessage(msg)
+		if err != nil {
+			return openai.ChatCompletionMessageParamUnion{}, false
+		}
+		return s.aiTextMessage(isAssistant, fmt.Sprintf("[分享了位置] %s，坐标: 纬度%.6f，经度%.6f", location.Describe(), location.X, location.Y)), true
+	case msg.Type == model.MsgTypeVoice && strings.TrimSpace(msg.Transcript) != "":
+		return s.aiTextMessage(isAssistant, msg.Transcript), true
 	case msg.Type == model.MsgTypeVideo && msg.AttachmentUrl != "":
 		return s.aiTextMessage(isAssistant, "视频地址: "+msg.AttachmentUrl), true
 	case msg.Type == model.MsgTypeApp && msg.AppMsgType == model.AppMsgTypequote:
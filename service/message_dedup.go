@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/vars"
+)
+
+const (
+	messageDedupPrefix   = "msg_dedup:"
+	messageDedupDuration = 24 * time.Hour
+)
+
+// 同步消息入库去重统计，进程级别
+var (
+	ingestReceived   atomic.Int64
+	ingestAccepted   atomic.Int64
+	ingestDuplicated atomic.Int64
+	ingestFallback   atomic.Int64
+)
+
+// MessageDeduplicator 按 msg_id 对同步消息去重，优先使用 Redis，Redis 不可用时回退到数据库查询
+type MessageDeduplicator struct {
+	ctx     context.Context
+	msgRepo *repository.Message
+}
+
+func NewMessageDeduplicator(ctx context.Context) *MessageDeduplicator {
+	return &MessageDeduplicator{
+		ctx:     ctx,
+		msgRepo: repository.NewMessageRepo(ctx, vars.DB),
+	}
+}
+
+func (d *MessageDeduplicator) key(msgID int64) string {
+	return fmt.Sprintf("%s%s:%d", messageDedupPrefix, vars.RobotRuntime.WxID, msgID)
+}
+
+// Claim 认领一条消息，返回 false 表示该消息已经处理过，应当丢弃
+func (d *MessageDeduplicator) Claim(msgID int64) bool {
+	ingestReceived.Add(1)
+	if msgID == 0 {
+		ingestAccepted.Add(1)
+		return true
+	}
+	if vars.RedisClient != nil {
+		ok, err := vars.RedisClient.SetNX(d.ctx, d.key(msgID), 1, messageDedupDuration).Result()
+		if err == nil {
+			if !ok {
+				ingestDuplicated.Add(1)
+				return false
+			}
+			ingestAccepted.Add(1)
+			return true
+		}
+		log.Printf("消息去重写入Redis失败，回退到数据库查询: %v", err)
+	}
+	ingestFallback.Add(1)
+	existed, err := d.msgRepo.GetByMsgID(msgID)
+	if err != nil {
+		log.Printf("消息去重查询数据库失败: %v", err)
+	}
+	if existed != nil {
+		ingestDuplicated.Add(1)
+		return false
+	}
+	ingestAccepted.Add(1)
+	return true
+}
+
+// Duplicated 认领后入库时才发现消息已经存在（如 Redis 去重记录过期），改记为重复消息
+func (d *MessageDeduplicator) Duplicated() {
+	ingestAccepted.Add(-1)
+	ingestDuplicated.Add(1)
+}
+
+// Release 消息入库失败时释放认领，便于下次同步重新处理
+func (d *MessageDeduplicator) Release(msgID int64) {
+	ingestAccepted.Add(-1)
+	if msgID == 0 || vars.RedisClient == nil {
+		return
+	}
+	if err := vars.RedisClient.Del(d.ctx, d.key(msgID)).Err(); err != nil {
+		log.Printf("释放消息去重标记失败: %v", err)
+	}
+}
+
+// GetMessageIngestStats 获取同步消息入库去重统计
+func GetMessageIngestStats() dto.MessageIngestStats {
+	return dto.MessageIngestStats{
+		Received:   ingestReceived.Load(),
+		Accepted:   ingestAccepted.Load(),
+		Duplicated: ingestDuplicated.Load(),
+		Fallback:   ingestFallback.Load(),
+	}
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/vars"
+)
+
+// 消息队列满时的处理策略
+const (
+	OverflowBlock      = "block"       // 阻塞等待，对消息同步形成背压
+	OverflowDropNewest = "drop_newest" // 丢弃新到的消息
+	OverflowDropOldest = "drop_oldest" // 丢弃最早的消息
+)
+
+const (
+	defaultMessageWorkers          = 16
+	defaultMessageQueueCapacity    = 2000
+	defaultMessageConversationSize = 100
+)
+
+type messageTask struct {
+	run        func(release func())
+	enqueuedAt time.Time
+}
+
+type conversationQueue struct {
+	tasks     []*messageTask
+	scheduled bool // 已在就绪队列中或正在被 worker 处理
+	running   bool
+}
+
+// MessageDispatcher 入站消息处理的有界 worker 池
+// 同一会话（FromWxID）的消息按顺序处理，不同会话之间轮转调度，避免繁忙的群聊饿死其他会话
+type MessageDispatcher struct {
+	mu               sync.Mutex
+	notFull          *sync.Cond
+	hasReady         *sync.Cond
+	queues           map[string]*conversationQueue
+	ready            []string // 就绪的会话，worker 按先后顺序轮转处理
+	workers          int
+	capacity         int
+	conversationSize int
+	overflowPolicy   string
+	pending          int
+	busy             int
+	processed        int64
+	dropped          int64
+	closed           bool
+}
+
+var (
+	messageDispatcher     *MessageDispatcher
+	messageDispatcherOnce sync.Once
+)
+
+// GetMessageDispatcher 获取全局的入站消息处理池，首次调用时按配置启动
+func GetMessageDispatcher() *MessageDispatcher {
+	messageDispatcherOnce.Do(func() {
+		settings := vars.MessageQueueSettings
+		messageDispatcher = NewMessageDispatcher(settings.Workers, settings.Capacity, settings.ConversationSize, settings.OverflowPolicy)
+	})
+	return messageDispatcher
+}
+
+func NewMessageDispatcher(workers, capacity, conversationSize int, overflowPolicy string) *MessageDispatcher {
+	if workers <= 0 {
+		workers = defaultMessageWorkers
+	}
+	if capacity <= 0 {
+		capacity = defaultMessageQueueCapacity
+	}
+	if conversationSize <= 0 {
+		conversationSize = defaultMessageConversationSize
+	}
+	switch overflowPolicy {
+	case OverflowBlock, OverflowDropNewest, OverflowDropOldest:
+	default:
+		overflowPolicy = OverflowDropOldest
+	}
+	d := &MessageDispatcher{
+		queues:           make(map[string]*conversationQueue),
+		workers:          workers,
+		capacity:         capacity,
+		conversationSize: conversationSize,
+		overflowPolicy:   overflowPolicy,
+	}
+	d.notFull = sync.NewCond(&d.mu)
+	d.hasReady = sync.NewCond(&d.mu)
+	for range workers {
+		go d.worker()
+	}
+	return d
+}
+
+func (d *MessageDispatcher) isFull(q *conversationQueue) bool {
+	return d.pending >= d.capacity || (q != nil && len(q.tasks) >= d.conversationSize)
+}
+
+// dropOldest 丢弃最早的一条消息，优先丢弃当前会话的，当前会话为空时丢弃积压最多的会话
+func (d *MessageDispatcher) dropOldest(key string) {
+	victim := key
+	if q := d.queues[key]; q == nil || len(q.tasks) == 0 {
+		most := 0
+		for k, q := range d.queues {
+			if len(q.tasks) > most {
+				victim, most = k, len(q.tasks)
+			}
+		}
+	}
+	q := d.queues[victim]
+	if q == nil || len(q.tasks) == 0 {
+		return
+	}
+	q.tasks = q.tasks[1:]
+	d.pending--
+	d.dropped++
+	log.Printf("[MessageQueue] 队列已满，丢弃会话[%s]最早的一条消息", victim)
+}
+
+// Submit 提交一个消息处理任务，key 相同的任务按提交顺序依次执行，返回 false 表示任务被丢弃
+func (d *MessageDispatcher) Submit(key string, run func()) bool {
+	return d.SubmitReleasable(key, func(release func()) { run() })
+}
+
+// SubmitReleasable 和 Submit 相同，任务可以调用 release 让出会话：同一会话后面的消息不再等待当前任务，
+// 同时补充一个 worker，用于等待工具审批等长时间阻塞的任务
+func (d *MessageDispatcher) SubmitReleasable(key string, run func(release func())) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for !d.closed && d.isFull(d.queues[key]) {
+		if d.overflowPolicy == OverflowBlock {
+			d.notFull.Wait()
+			continue
+		}
+		if d.overflowPolicy == OverflowDropNewest {
+			d.dropped++
+			log.Printf("[MessageQueue] 队列已满，丢弃会话[%s]的新消息", key)
+			return false
+		}
+		d.dropOldest(key)
+	}
+	if d.closed {
+		d.dropped++
+		return false
+	}
+	q := d.queues[key]
+	if q == nil {
+		q = &conversationQueue{}
+		d.queues[key] = q
+	}
+	q.tasks = append(q.tasks, &messageTask{run: run, enqueuedAt: time.Now()})
+	d.pending++
+	if !q.scheduled {
+		q.scheduled = true
+		d.ready = append(d.ready, key)
+		d.hasReady.Signal()
+	}
+	return true
+}
+
+// next 取出下一个就绪的会话，队列关闭且没有待处理的会话时返回 false
+func (d *MessageDispatcher) next() (string, bool) {
+	for len(d.ready) == 0 {
+		if d.closed {
+			return "", false
+		}
+		d.hasReady.Wait()
+	}
+	key := d.ready[0]
+	d.ready = d.ready[1:]
+	return key, true
+}
+
+func (d *MessageDispatcher) worker() {
+	for {
+		d.mu.Lock()
+		key, ok := d.next()
+		if !ok {
+			d.mu.Unlock()
+			return
+		}
+		q := d.queues[key]
+		if q == nil || len(q.tasks) == 0 {
+			// 任务在调度前被丢弃了
+			if q != nil {
+				delete(d.queues, key)
+			}
+			d.mu.Unlock()
+			continue
+		}
+		task := q.tasks[0]
+		q.tasks = q.tasks[1:]
+		q.running = true
+		d.pending--
+		d.busy++
+		d.notFull.Broadcast()
+		d.mu.Unlock()
+
+		released := false
+		release := func() {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			if released {
+				return
+			}
+			released = true
+			d.releaseConversation(key, q)
+			go d.worker()
+		}
+		d.execute(key, task, release)
+
+		d.mu.Lock()
+		d.busy--
+		d.processed++
+		if released {
+			// 会话已经交给其他 worker，补充的 worker 已经启动，当前 worker 退出
+			d.mu.Unlock()
+			return
+		}
+		q.running = false
+		if len(q.tasks) > 0 {
+			// 放回就绪队列末尾，让其他会话有机会执行
+			d.ready = append(d.ready, key)
+			d.hasReady.Signal()
+		} else {
+			delete(d.queues, key)
+		}
+		d.mu.Unlock()
+	}
+}
+
+// releaseConversation 当前任务让出会话后，会话中剩余的消息重新进入就绪队列
+func (d *MessageDispatcher) releaseConversation(key string, q *conversationQueue) {
+	q.running = false
+	if len(q.tasks) > 0 {
+		d.ready = append(d.ready, key)
+		d.hasReady.Signal()
+		return
+	}
+	if d.queues[key] == q {
+		delete(d.queues, key)
+	}
+}
+
+func (d *MessageDispatcher) execute(key string, task *messageTask, release func()) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("[MessageQueue] 处理会话[%s]的消息异常: %v\n%s", key, err, debug.Stack())
+		}
+	}()
+	task.run(release)
+}
+
+// messageLaneReleasers 正在处理的入站消息让出会话的方法，key 为消息表主键ID
+var messageLaneReleasers sync.Map
+
+// releaseMessageLane 消息处理需要长时间阻塞（如等待工具审批）前调用，让同一会话的后续消息继续处理
+func releaseMessageLane(messageID int64) {
+	if release, ok := messageLaneReleasers.Load(messageID); ok {
+		release.(func())()
+	}
+}
+
+// Stats 获取队列状态，conversationLimit 限制返回的会话数量，按积压数量倒序
+func (d *MessageDispatcher) Stats(conversationLimit int) dto.MessageQueueStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := time.Now()
+	stats := dto.MessageQueueStats{
+		Workers:          d.workers,
+		Busy:             d.busy,
+		Pending:          d.pending,
+		Capacity:         d.capacity,
+		ConversationSize: d.conversationSize,
+		OverflowPolicy:   d.overflowPolicy,
+		Processed:        d.processed,
+		Dropped:          d.dropped,
+		Conversations:    make([]dto.MessageQueueConversation, 0, len(d.queues)),
+	}
+	for key, q := range d.queues {
+		item := dto.MessageQueueConversation{
+			FromWxID: key,
+			Pending:  len(q.tasks),
+			Running:  q.running,
+		}
+		if len(q.tasks) > 0 {
+			item.OldestWait = int64(now.Sub(q.tasks[0].enqueuedAt).Seconds())
+		}
+		stats.Conversations = append(stats.Conversations, item)
+	}
+	sort.Slice(stats.Conversations, func(i, j int) bool {
+		return stats.Conversations[i].Pending > stats.Conversations[j].Pending
+	})
+	if conversationLimit > 0 && len(stats.Conversations) > conversationLimit {
+		stats.Conversations = stats.Conversations[:conversationLimit]
+	}
+	return stats
+}
+
+func (d *MessageDispatcher) Name() string {
+	return "入站消息处理队列"
+}
+
+// Shutdown 停止接收新消息，等待已入队的消息处理完成
+func (d *MessageDispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	d.notFull.Broadcast()
+	d.hasReady.Broadcast()
+	d.mu.Unlock()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		d.mu.Lock()
+		idle := d.pending == 0 && d.busy == 0
+		d.mu.Unlock()
+		if idle {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
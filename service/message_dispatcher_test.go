@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMessageDispatcherConversationOrder(t *testing.T) {
+	d := NewMessageDispatcher(4, 100, 100, OverflowBlock)
+	var mu sync.Mutex
+	got := map[string][]int{}
+	for i := range 20 {
+		for _, key := range []string{"a@chatroom", "b@chatroom", "wxid_c"} {
+			d.Submit(key, func() {
+				mu.Lock()
+				got[key] = append(got[key], i)
+				mu.Unlock()
+			})
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	for key, seq := range got {
+		if len(seq) != 20 {
+			t.Fatalf("%s: expected 20 tasks, got %d", key, len(seq))
+		}
+		for i, v := range seq {
+			if v != i {
+				t.Fatalf("%s: out of order at %d: %v", key, i, seq)
+			}
+		}
+	}
+}
+
+func TestMessageDispatcherOverflow(t *testing.T) {
+	block := make(chan struct{})
+	d := NewMessageDispatcher(1, 100, 2, OverflowDropNewest)
+	d.Submit("a", func() { <-block })
+	// 等待第一条消息被 worker 取走
+	for d.Stats(0).Busy == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if !d.Submit("a", func() {}) || !d.Submit("a", func() {}) {
+		t.Fatal("queue should accept tasks within conversation size")
+	}
+	if d.Submit("a", func() {}) {
+		t.Fatal("drop_newest should reject tasks when conversation queue is full")
+	}
+	if !d.Submit("b", func() {}) {
+		t.Fatal("other conversations should not be affected")
+	}
+	stats := d.Stats(1)
+	if stats.Pending != 3 || stats.Dropped != 1 || len(stats.Conversations) != 1 || stats.Conversations[0].FromWxID != "a" {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	close(block)
+
+	release := make(chan struct{})
+	d = NewMessageDispatcher(1, 2, 100, OverflowDropOldest)
+	d.Submit("a", func() { <-release })
+	for d.Stats(0).Busy == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	var ran []string
+	var mu sync.Mutex
+	for _, name := range []string{"a1", "a2", "a3"} {
+		d.Submit("a", func() {
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+		})
+	}
+	close(release)
+	if err := d.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if len(ran) != 2 || ran[0] != "a2" || ran[1] != "a3" {
+		t.Fatalf("drop_oldest should keep the newest tasks, got %v", ran)
+	}
+}
+
+func TestMessageDispatcherRelease(t *testing.T) {
+	d := NewMessageDispatcher(1, 100, 100, OverflowBlock)
+	block := make(chan struct{})
+	released := make(chan struct{})
+	d.SubmitReleasable("a", func(release func()) {
+		release()
+		release()
+		close(released)
+		<-block
+	})
+	<-released
+	done := make(chan struct{})
+	d.Submit("a", func() { close(done) })
+	d.Submit("b", func() {})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("released conversation should not wait for the blocked task")
+	}
+	close(block)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if stats := d.Stats(0); stats.Processed != 3 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/robot"
+	"wechat-robot-client/vars"
+)
+
+// SendReplyParts 按顺序发送结构化回复的各个部分，某一部分失败不影响后续部分，返回每一部分的发送结果
+// synthesize 将文本合成为语音，返回音频数据和带点的扩展名，为空时不带 url 的语音部分会发送失败
+func (s *MessageService) SendReplyParts(toWxID string, parts []dto.ReplyPart, synthesize func(text string) ([]byte, string, error)) []dto.ReplyPartResult {
+	results := make([]dto.ReplyPartResult, 0, len(parts))
+	for index, part := range parts {
+		result := dto.ReplyPartResult{
+			Index: index,
+			Type:  part.Type,
+		}
+		sent, err := s.sendReplyPart(toWxID, part, synthesize)
+		if err != nil {
+			result.Error = err.Error()
+			log.Printf("结构化回复第 %d 部分(%s)发送失败: %v", index+1, part.Type, err)
+		} else {
+			result.Success = true
+			if sent != nil {
+				result.MessageID = sent.ID
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (s *MessageService) sendReplyPart(toWxID string, part dto.ReplyPart, synthesize func(text string) ([]byte, string, error)) (*model.Message, error) {
+	switch part.Type {
+	case dto.ReplyPartTypeText:
+		if strings.TrimSpace(part.Text) == "" {
+			return nil, errors.New("文本内容不能为空")
+		}
+		if part.QuoteMessageID > 0 {
+			referMessage, err := s.msgRepo.GetByID(part.QuoteMessageID)
+			if err != nil {
+				return nil, err
+			}
+			if referMessage == nil {
+				return nil, errors.New("引用的消息不存在")
+			}
+			return s.sendReferMessage(toWxID, part.Text, referMessage)
+		}
+		return s.sendTextMessage(toWxID, part.Text, part.At...)
+	case dto.ReplyPartTypeImage:
+		if part.URL == "" {
+			return nil, errors.New("图片地址不能为空")
+		}
+		return s.sendImageMessageByRemoteURL(toWxID, part.URL)
+	case dto.ReplyPartTypeVideo:
+		if part.URL == "" {
+			return nil, errors.New("视频地址不能为空")
+		}
+		return nil, s.SendVideoMessageByRemoteURL(toWxID, part.URL)
+	case dto.ReplyPartTypeVoice:
+		if part.URL != "" {
+			return nil, s.SendVoiceMessageByRemoteURL(toWxID, part.URL)
+		}
+		if strings.TrimSpace(part.Text) == "" {
+			return nil, errors.New("语音地址和语音文本不能同时为空")
+		}
+		if synthesize == nil {
+			return nil, errors.New("未配置文本转语音")
+		}
+		audioData, audioExt, err := synthesize(part.Text)
+		if err != nil {
+			return nil, err
+		}
+		return nil, s.MsgSendVoice(toWxID, bytes.NewReader(audioData), audioExt)
+	case dto.ReplyPartTypeFile:
+		if part.URL == "" {
+			return nil, errors.New("文件地址不能为空")
+		}
+		return nil, s.SendFileMessageByRemoteURL(toWxID, part.URL)
+	case dto.ReplyPartTypeApp:
+		if part.AppXML == "" || part.AppType == 0 {
+			return nil, errors.New("应用消息内容和类型不能为空")
+		}
+		return s.sendAppMessage(toWxID, part.AppType, part.AppXML)
+	default:
+		return nil, fmt.Errorf("不支持的消息类型: %s", part.Type)
+	}
+}
+
+// referDisplayName 被引用消息发送者的显示名称，群聊优先取群备注
+func (s *MessageService) referDisplayName(referMessage *model.Message) string {
+	if referMessage.IsChatRoom {
+		chatRoomMember, err := s.crmRepo.GetChatRoomMember(referMessage.FromWxID, referMessage.SenderWxID)
+		if err == nil && chatRoomMember != nil {
+			if chatRoomMember.Remark != "" {
+				return chatRoomMember.Remark
+			}
+			if chatRoomMember.Nickname != "" {
+				return chatRoomMember.Nickname
+			}
+		}
+	}
+	return referMessage.SenderWxID
+}
+
+// ReplyToMessage 在原消息所在的会话中引用该消息进行回复
+func (s *MessageService) ReplyToMessage(referMessage *model.Message, content string) error {
+	_, err := s.sendReferMessage(referMessage.FromWxID, content, referMessage)
+	return err
+}
+
+// ReplyToMessageByID 根据消息表主键ID引用消息进行回复
+func (s *MessageService) ReplyToMessageByID(messageID int64, content string) error {
+	referMessage, err := s.msgRepo.GetByID(messageID)
+	if err != nil {
+		return fmt.Errorf("获取消息失败: %w", err)
+	}
+	if referMessage == nil {
+		return errors.New("消息不存在")
+	}
+	return s.ReplyToMessage(referMessage, content)
+}
+
+// sendReferMessage 发送引用回复消息(应用消息类型57)，引用消息不支持@
+func (s *MessageService) sendReferMessage(toWxID, content string, referMessage *model.Message) (*model.Message, error) {
+	referContent := referMessage.Content
+	if referMessage.Type == model.MsgTypeApp && referMessage.AppMsgType == model.AppMsgTypequote {
+		// 引用一条引用消息时，只保留对方回复的文本
+		var xmlMessage robot.XmlMessage
+		if err := vars.RobotRuntime.XmlDecoder(referMessage.Content, &xmlMessage); err == nil {
+			referContent = xmlMessage.AppMsg.Title
+		}
+	}
+	fromUsr := referMessage.SenderWxID
+	if referMessage.IsChatRoom {
+		fromUsr = referMessage.FromWxID
+	}
+	appMsg := robot.AppMessage{
+		Title: content,
+		Type:  int(model.AppMsgTypequote),
+		ReferMsg: robot.ReferMessage{
+			Type:        int(referMessage.Type),
+			SvrID:       strconv.FormatInt(referMessage.MsgId, 10),
+			FromUsr:     fromUsr,
+			ChatUsr:     referMessage.SenderWxID,
+			DisplayName: s.referDisplayName(referMessage),
+			Content:     referContent,
+			CreateTime:  referMessage.CreatedAt,
+		},
+	}
+	appMsgBytes, err := xml.Marshal(appMsg)
+	if err != nil {
+		return nil, fmt.Errorf("生成引用消息失败: %w", err)
+	}
+	appMsgXml := string(appMsgBytes)
+	message, err := vars.RobotRuntime.SendAppMessage(toWxID, int(model.AppMsgTypequote), appMsgXml)
+	if err != nil {
+		return nil, err
+	}
+
+	m := model.Message{
+		MsgId:       message.NewMsgId,
+		ClientMsgId: message.MsgId,
+		Type:        model.MsgTypeApp,
+		AppMsgType:  model.AppMsgTypequote,
+		// 和收到的引用消息保持一致，方便构建 AI 上下文时解析
+		Content:            fmt.Sprintf("<msg>%s</msg>", appMsgXml),
+		DisplayFullContent: "",
+		MessageSource:      message.MsgSource,
+		FromWxID:           toWxID,
+		ToWxID:             vars.RobotRuntime.WxID,
+		SenderWxID:         vars.RobotRuntime.WxID,
+		IsChatRoom:         strings.HasSuffix(toWxID, "@chatroom"),
+		CreatedAt:          message.CreateTime,
+		UpdatedAt:          time.Now().Unix(),
+	}
+	if m.IsChatRoom && referMessage.SenderWxID != vars.RobotRuntime.WxID {
+		// 和@回复一样记录回复对象，群聊 AI 上下文按回复对象查询
+		m.ReplyWxID = referMessage.SenderWxID
+	}
+	err = s.msgRepo.Create(&m)
+	if err != nil {
+		log.Println("入库消息失败: ", err)
+	}
+	// 插入一条联系人记录，获取联系人列表接口获取不到未保存到通讯录的群聊
+	NewContactService(s.ctx).InsertOrUpdateContactActiveTime(m.FromWxID)
+
+	return &m, nil
+}
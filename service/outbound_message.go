@@ -0,0 +1,412 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/pkg/robot"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/vars"
+)
+
+const (
+	outboundPollInterval       = 2 * time.Second
+	outboundBatchSize          = 20
+	outboundConcurrency        = 4
+	outboundBaseBackoff        = 5 * time.Second
+	outboundMaxBackoff         = 10 * time.Minute
+	outboundDefaultMaxAttempts = 5
+)
+
+// outboundImageCacheDir 本地图片等待发送期间的缓存目录，进入死信的图片保留以便重新投递
+var outboundImageCacheDir = filepath.Join(string(filepath.Separator), "app", "outbound_image_cache")
+
+type OutboundMessageService struct {
+	ctx  context.Context
+	repo *repository.OutboundMessage
+}
+
+func NewOutboundMessageService(ctx context.Context) *OutboundMessageService {
+	return &OutboundMessageService{
+		ctx:  ctx,
+		repo: repository.NewOutboundMessageRepo(ctx, vars.DB),
+	}
+}
+
+func (s *OutboundMessageService) enqueue(message *model.OutboundMessage) (*model.OutboundMessage, error) {
+	message.Status = model.OutboundMessageStatusPending
+	message.MaxAttempts = outboundDefaultMaxAttempts
+	message.NextAttemptAt = time.Now().Unix()
+	if err := s.repo.Create(message); err != nil {
+		return nil, err
+	}
+	GetOutboundQueue().Notify()
+	return message, nil
+}
+
+// EnqueueText 文本消息入队，source 标记消息来源，便于排查
+func (s *OutboundMessageService) EnqueueText(source, toWxID, content string, at ...string) (*model.OutboundMessage, error) {
+	message := &model.OutboundMessage{
+		ToWxID:  toWxID,
+		Type:    model.OutboundMessageTypeText,
+		Content: content,
+		Source:  source,
+	}
+	if len(at) > 0 {
+		atBytes, err := json.Marshal(at)
+		if err != nil {
+			return nil, err
+		}
+		message.At = atBytes
+	}
+	return s.enqueue(message)
+}
+
+func (s *OutboundMessageService) EnqueueLongText(source, toWxID, content string) (*model.OutboundMessage, error) {
+	return s.enqueue(&model.OutboundMessage{
+		ToWxID:  toWxID,
+		Type:    model.OutboundMessageTypeLongText,
+		Content: content,
+		Source:  source,
+	})
+}
+
+func (s *OutboundMessageService) EnqueueImageURL(source, toWxID, imageURL string) (*model.OutboundMessage, error) {
+	return s.enqueue(&model.OutboundMessage{
+		ToWxID: toWxID,
+		Type:   model.OutboundMessageTypeImageURL,
+		URL:    imageURL,
+		Source: source,
+	})
+}
+
+// EnqueueImage 本地生成的图片先写入缓存目录再入队，发送成功后删除缓存文件
+func (s *OutboundMessageService) EnqueueImage(source, toWxID string, image io.Reader) (*model.OutboundMessage, error) {
+	if err := os.MkdirAll(outboundImageCacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建图片缓存目录失败: %w", err)
+	}
+	filePath := filepath.Join(outboundImageCacheDir, uuid.New().String()+".png")
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("创建图片缓存文件失败: %w", err)
+	}
+	_, err = io.Copy(file, image)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filePath)
+		return nil, fmt.Errorf("写入图片缓存文件失败: %w", err)
+	}
+	message, err := s.enqueue(&model.OutboundMessage{
+		ToWxID: toWxID,
+		Type:   model.OutboundMessageTypeImage,
+		URL:    filePath,
+		Source: source,
+	})
+	if err != nil {
+		os.Remove(filePath)
+		return nil, err
+	}
+	return message, nil
+}
+
+func (s *OutboundMessageService) EnqueueFileURL(source, toWxID, fileURL string) (*model.OutboundMessage, error) {
+	return s.enqueue(&model.OutboundMessage{
+		ToWxID: toWxID,
+		Type:   model.OutboundMessageTypeFileURL,
+		URL:    fileURL,
+		Source: source,
+	})
+}
+
+func (s *OutboundMessageService) EnqueueApp(source, toWxID string, appMsgType int, appMsgXml string) (*model.OutboundMessage, error) {
+	return s.enqueue(&model.OutboundMessage{
+		ToWxID:     toWxID,
+		Type:       model.OutboundMessageTypeApp,
+		Content:    appMsgXml,
+		AppMsgType: appMsgType,
+		Source:     source,
+	})
+}
+
+func (s *OutboundMessageService) GetList(req dto.OutboundMessageListRequest, pager appx.Pager) ([]*model.OutboundMessage, int64, error) {
+	return s.repo.GetList(req, pager)
+}
+
+func (s *OutboundMessageService) GetAttempts(id int64) ([]*model.OutboundMessageAttempt, error) {
+	return s.repo.GetAttempts(id)
+}
+
+// Redrive 重新投递死信消息
+func (s *OutboundMessageService) Redrive(ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	affected, err := s.repo.Redrive(ids, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	GetOutboundQueue().Notify()
+	return affected, nil
+}
+
+// send 按消息类型调用机器人接口发送，返回入库的消息
+func (s *OutboundMessageService) send(message *model.OutboundMessage) (*model.Message, error) {
+	msgService := NewMessageService(s.ctx)
+	switch message.Type {
+	case model.OutboundMessageTypeText:
+		var at []string
+		if len(message.At) > 0 {
+			if err := json.Unmarshal(message.At, &at); err != nil {
+				return nil, fmt.Errorf("解析@列表失败: %w", err)
+			}
+		}
+		return msgService.sendTextMessage(message.ToWxID, message.Content, at...)
+	case model.OutboundMessageTypeLongText:
+		return msgService.sendLongTextMessage(message.ToWxID, message.Content)
+	case model.OutboundMessageTypeImageURL:
+		return msgService.sendImageMessageByRemoteURL(message.ToWxID, message.URL)
+	case model.OutboundMessageTypeImage:
+		image, err := os.Open(message.URL)
+		if err != nil {
+			return nil, fmt.Errorf("打开图片缓存文件失败: %w", err)
+		}
+		defer image.Close()
+		return msgService.MsgUploadImg(message.ToWxID, image)
+	case model.OutboundMessageTypeFileURL:
+		// 文件分片发送接口不返回消息，无法关联消息记录
+		return nil, msgService.SendFileMessageByRemoteURL(message.ToWxID, message.URL)
+	case model.OutboundMessageTypeApp:
+		return msgService.sendAppMessage(message.ToWxID, message.AppMsgType, message.Content)
+	default:
+		return nil, fmt.Errorf("不支持的消息类型: %s", message.Type)
+	}
+}
+
+// deliver 执行一次发送尝试并记录结果
+func (s *OutboundMessageService) deliver(message *model.OutboundMessage) {
+	claimed, err := s.repo.Claim(message)
+	if err != nil {
+		log.Printf("[OutboundQueue] 更新消息[%d]状态失败: %v", message.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	start := time.Now()
+	sent, err := s.send(message)
+	attempt := &model.OutboundMessageAttempt{
+		OutboundMessageID: message.ID,
+		Attempt:           message.Attempts,
+		Success:           err == nil,
+		Duration:          time.Since(start).Milliseconds(),
+	}
+	now := time.Now()
+	if err == nil {
+		message.Status = model.OutboundMessageStatusSent
+		message.SentAt = now.Unix()
+		message.LastError = ""
+		if sent != nil {
+			message.MessageID = sent.ID
+			attempt.MessageID = sent.ID
+		}
+		if message.Type == model.OutboundMessageTypeImage {
+			if err := os.Remove(message.URL); err != nil && !os.IsNotExist(err) {
+				log.Printf("[OutboundQueue] 删除消息[%d]的图片缓存文件失败: %v", message.ID, err)
+			}
+		}
+	} else {
+		attempt.Error = err.Error()
+		message.LastError = err.Error()
+		if message.Attempts >= message.MaxAttempts {
+			message.Status = model.OutboundMessageStatusDead
+			log.Printf("[OutboundQueue] 消息[%d]发送给[%s]失败 %d 次，进入死信: %v", message.ID, message.ToWxID, message.Attempts, err)
+		} else if outboundFatalError(err) {
+			message.Status = model.OutboundMessageStatusDead
+			log.Printf("[OutboundQueue] 消息[%d]发送给[%s]失败，错误不可重试，进入死信: %v", message.ID, message.ToWxID, err)
+		} else {
+			message.Status = model.OutboundMessageStatusPending
+			message.NextAttemptAt = now.Add(outboundBackoff(message.Attempts)).Unix()
+			log.Printf("[OutboundQueue] 消息[%d]发送给[%s]失败，第 %d 次重试将在 %s 后进行: %v", message.ID, message.ToWxID, message.Attempts, outboundBackoff(message.Attempts), err)
+		}
+	}
+	if err := s.repo.CreateAttempt(attempt); err != nil {
+		log.Printf("[OutboundQueue] 记录消息[%d]发送尝试失败: %v", message.ID, err)
+	}
+	if err := s.repo.Update(message); err != nil {
+		log.Printf("[OutboundQueue] 更新消息[%d]状态失败: %v", message.ID, err)
+	}
+}
+
+// outboundFatalError 协议服务明确返回不可重试的错误时不再重试
+// 退出登录除外，重新登陆后可以继续发送
+func outboundFatalError(err error) bool {
+	var clientErr *robot.ClientError
+	if !errors.As(err, &clientErr) {
+		return false
+	}
+	return !clientErr.Retryable() && !errors.Is(err, robot.ErrLoggedOut)
+}
+
+// outboundBackoff 第 attempts 次失败后的重试间隔，按指数增长
+func outboundBackoff(attempts int) time.Duration {
+	backoff := outboundBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboundMaxBackoff {
+			return outboundMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// OutboundQueue 出站消息发送队列，轮询数据库中到期的消息进行发送
+// 同一接收者同时只有一条消息在发送，前一条发送成功或进入死信后才会发送下一条
+type OutboundQueue struct {
+	mu       sync.Mutex
+	inflight map[string]bool
+	wake     chan struct{}
+	stop     chan struct{}
+	sem      chan struct{}
+	wg       sync.WaitGroup
+	started  bool
+}
+
+var (
+	outboundQueue     *OutboundQueue
+	outboundQueueOnce sync.Once
+)
+
+func GetOutboundQueue() *OutboundQueue {
+	outboundQueueOnce.Do(func() {
+		outboundQueue = &OutboundQueue{
+			inflight: make(map[string]bool),
+			wake:     make(chan struct{}, 1),
+			stop:     make(chan struct{}),
+			sem:      make(chan struct{}, outboundConcurrency),
+		}
+	})
+	return outboundQueue
+}
+
+// Start 启动发送队列，重复调用无副作用
+func (q *OutboundQueue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started {
+		return
+	}
+	q.started = true
+	if err := repository.NewOutboundMessageRepo(context.Background(), vars.DB).ResetSending(); err != nil {
+		log.Printf("[OutboundQueue] 重置发送中的消息失败: %v", err)
+	}
+	go q.loop()
+}
+
+// Notify 唤醒发送队列，立即检查待发送的消息
+func (q *OutboundQueue) Notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *OutboundQueue) loop() {
+	ticker := time.NewTicker(outboundPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+		// 机器人不在线时发送必然失败，不消耗重试次数
+		if vars.RobotRuntime.Status != model.RobotStatusOnline {
+			continue
+		}
+		q.poll()
+	}
+}
+
+func (q *OutboundQueue) poll() {
+	heads, err := repository.NewOutboundMessageRepo(context.Background(), vars.DB).GetDueHeads(time.Now().Unix(), outboundBatchSize)
+	if err != nil {
+		log.Printf("[OutboundQueue] 获取待发送消息失败: %v", err)
+		return
+	}
+	for _, message := range heads {
+		q.mu.Lock()
+		if q.inflight[message.ToWxID] {
+			q.mu.Unlock()
+			continue
+		}
+		q.inflight[message.ToWxID] = true
+		q.mu.Unlock()
+
+		select {
+		case q.sem <- struct{}{}:
+		case <-q.stop:
+			q.release(message.ToWxID)
+			return
+		}
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			defer func() { <-q.sem }()
+			defer q.release(message.ToWxID)
+			NewOutboundMessageService(context.Background()).deliver(message)
+		}()
+	}
+}
+
+func (q *OutboundQueue) release(toWxID string) {
+	q.mu.Lock()
+	delete(q.inflight, toWxID)
+	q.mu.Unlock()
+	// 同一接收者可能还有后续消息
+	q.Notify()
+}
+
+func (q *OutboundQueue) Name() string {
+	return "出站消息发送队列"
+}
+
+// Shutdown 停止轮询，等待正在发送的消息完成
+func (q *OutboundQueue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.started {
+		q.mu.Unlock()
+		return nil
+	}
+	q.started = false
+	close(q.stop)
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"wechat-robot-client/pkg/robot"
+)
+
+func TestOutboundBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  5 * time.Second,
+		2:  10 * time.Second,
+		4:  40 * time.Second,
+		10: outboundMaxBackoff,
+	}
+	for attempts, want := range cases {
+		if got := outboundBackoff(attempts); got != want {
+			t.Errorf("outboundBackoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestOutboundFatalError(t *testing.T) {
+	cases := map[error]bool{
+		errors.New("下载文件失败"):                                       false,
+		robot.NewClientError(-1, "操作过于频繁"):                         false,
+		robot.NewClientError(-7, ""):                               false,
+		robot.NewClientError(-2, "账号存在安全风险"):                       true,
+		fmt.Errorf("发送失败: %w", robot.NewClientError(-3, "联系人不存在")): true,
+	}
+	for err, want := range cases {
+		if got := outboundFatalError(err); got != want {
+			t.Errorf("outboundFatalError(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
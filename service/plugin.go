@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/vars"
+
+	"gorm.io/datatypes"
+)
+
+type PluginService struct {
+	ctx     context.Context
+	gsRepo  *repository.GlobalSettings
+	crsRepo *repository.ChatRoomSettings
+	fsRepo  *repository.FriendSettings
+}
+
+func NewPluginService(ctx context.Context) *PluginService {
+	return &PluginService{
+		ctx:     ctx,
+		gsRepo:  repository.NewGlobalSettingsRepo(ctx, vars.DB),
+		crsRepo: repository.NewChatRoomSettingsRepo(ctx, vars.DB),
+		fsRepo:  repository.NewFriendSettingsRepo(ctx, vars.DB),
+	}
+}
+
+// resolvePluginEnabled 依次查询群聊/好友开关、全局开关，都未配置时默认启用
+func resolvePluginEnabled(name string, labels []string, switchesList ...datatypes.JSON) bool {
+	for _, data := range switchesList {
+		switches, err := model.ParsePluginSwitches(data)
+		if err != nil {
+			log.Printf("解析插件开关失败: %v", err)
+			continue
+		}
+		if enabled, ok := switches.Lookup(name, labels); ok {
+			return enabled
+		}
+	}
+	return true
+}
+
+func (s *PluginService) getScopeSwitches(chatRoomID, contactID string) (datatypes.JSON, error) {
+	if chatRoomID != "" {
+		chatRoomSettings, err := s.crsRepo.GetChatRoomSettings(chatRoomID)
+		if err != nil || chatRoomSettings == nil {
+			return nil, err
+		}
+		return chatRoomSettings.PluginSwitches, nil
+	}
+	if contactID != "" {
+		friendSettings, err := s.fsRepo.GetFriendSettings(contactID)
+		if err != nil || friendSettings == nil {
+			return nil, err
+		}
+		return friendSettings.PluginSwitches, nil
+	}
+	return nil, nil
+}
+
+// GetPlugins 获取已注册的插件及其在指定群聊/好友中的启用状态
+func (s *PluginService) GetPlugins(req dto.PluginListRequest) ([]dto.PluginInfo, error) {
+	globalSettings, err := s.gsRepo.GetGlobalSettings()
+	if err != nil {
+		return nil, err
+	}
+	var globalSwitches datatypes.JSON
+	if globalSettings != nil {
+		globalSwitches = globalSettings.PluginSwitches
+	}
+	scopeSwitches, err := s.getScopeSwitches(req.ChatRoomID, req.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	parsedGlobal, err := model.ParsePluginSwitches(globalSwitches)
+	if err != nil {
+		return nil, fmt.Errorf("全局插件开关格式错误: %w", err)
+	}
+	parsedScope, err := model.ParsePluginSwitches(scopeSwitches)
+	if err != nil {
+		return nil, fmt.Errorf("插件开关格式错误: %w", err)
+	}
+
+	plugins := make([]dto.PluginInfo, 0, len(vars.MessagePlugin.Plugins))
+	for _, handler := range vars.MessagePlugin.Plugins {
+		info := dto.PluginInfo{
+			Name:     handler.GetName(),
+			Labels:   handler.GetLabels(),
+			Priority: handler.GetPriority(),
+		}
+		if v, ok := parsedScope[info.Name]; ok {
+			info.Switch = &v
+		}
+		if v, ok := parsedGlobal[info.Name]; ok {
+			info.GlobalSwitch = &v
+		}
+		if req.ChatRoomID == "" && req.ContactID == "" {
+			info.Enabled = resolvePluginEnabled(info.Name, info.Labels, globalSwitches)
+		} else {
+			info.Enabled = resolvePluginEnabled(info.Name, info.Labels, scopeSwitches, globalSwitches)
+		}
+		plugins = append(plugins, info)
+	}
+	return plugins, nil
+}
+
+func (s *PluginService) isValidSwitchName(name string) bool {
+	for _, handler := range vars.MessagePlugin.Plugins {
+		if handler.GetName() == name {
+			return true
+		}
+		if label, ok := strings.CutPrefix(name, model.PluginLabelKeyPrefix); ok && slices.Contains(handler.GetLabels(), label) {
+			return true
+		}
+	}
+	return false
+}
+
+func toggleSwitch(data datatypes.JSON, name string, enabled *bool) (datatypes.JSON, error) {
+	switches, err := model.ParsePluginSwitches(data)
+	if err != nil {
+		return nil, fmt.Errorf("插件开关格式错误: %w", err)
+	}
+	if enabled == nil {
+		delete(switches, name)
+	} else {
+		switches[name] = *enabled
+	}
+	return json.Marshal(switches)
+}
+
+// TogglePlugin 设置插件开关，未指定群聊和好友时修改全局默认值
+func (s *PluginService) TogglePlugin(req dto.PluginToggleRequest) error {
+	if !s.isValidSwitchName(req.Name) {
+		return fmt.Errorf("插件[%s]不存在", req.Name)
+	}
+	if req.ChatRoomID != "" {
+		chatRoomSettings, err := s.crsRepo.GetChatRoomSettings(req.ChatRoomID)
+		if err != nil {
+			return err
+		}
+		if chatRoomSettings == nil {
+			// wxhb_notify_member_list 字段不允许为 NULL
+			chatRoomSettings = &model.ChatRoomSettings{ChatRoomID: req.ChatRoomID, WxhbNotifyMemberList: new(string)}
+		}
+		chatRoomSettings.PluginSwitches, err = toggleSwitch(chatRoomSettings.PluginSwitches, req.Name, req.Enabled)
+		if err != nil {
+			return err
+		}
+		if chatRoomSettings.ID == 0 {
+			return s.crsRepo.Create(chatRoomSettings)
+		}
+		return s.crsRepo.Update(chatRoomSettings)
+	}
+	if req.ContactID != "" {
+		friendSettings, err := s.fsRepo.GetFriendSettings(req.ContactID)
+		if err != nil {
+			return err
+		}
+		if friendSettings == nil {
+			friendSettings = &model.FriendSettings{WeChatID: req.ContactID}
+		}
+		friendSettings.PluginSwitches, err = toggleSwitch(friendSettings.PluginSwitches, req.Name, req.Enabled)
+		if err != nil {
+			return err
+		}
+		if friendSettings.ID == 0 {
+			return s.fsRepo.Create(friendSettings)
+		}
+		return s.fsRepo.Update(friendSettings)
+	}
+	globalSettings, err := s.gsRepo.GetGlobalSettings()
+	if err != nil {
+		return err
+	}
+	if globalSettings == nil {
+		return fmt.Errorf("全局配置不存在")
+	}
+	globalSettings.PluginSwitches, err = toggleSwitch(globalSettings.PluginSwitches, req.Name, req.Enabled)
+	if err != nil {
+		return err
+	}
+	return s.gsRepo.Update(globalSettings)
+}
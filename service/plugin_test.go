@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	"gorm.io/datatypes"
+)
+
+func TestResolvePluginEnabled(t *testing.T) {
+	global := datatypes.JSON(`{"DouyinVideoParse": false, "label:bilibili": false}`)
+	chatRoom := datatypes.JSON(`{"DouyinVideoParse": true, "label:chat": false}`)
+
+	if !resolvePluginEnabled("DouyinVideoParse", []string{"text", "douyin"}, chatRoom, global) {
+		t.Fatal("chat room switch should override global default")
+	}
+	if resolvePluginEnabled("DouyinVideoParse", []string{"text", "douyin"}, nil, global) {
+		t.Fatal("global default should apply when chat room has no switch")
+	}
+	if resolvePluginEnabled("BilibiliVideoParse", []string{"text", "bilibili"}, chatRoom, global) {
+		t.Fatal("label switch should disable plugin")
+	}
+	if resolvePluginEnabled("ChatRoomAIChat", []string{"text", "chat"}, chatRoom, global) {
+		t.Fatal("chat room label switch should disable plugin")
+	}
+	if !resolvePluginEnabled("Pat", []string{"pat"}, chatRoom, global) {
+		t.Fatal("plugin without switches should be enabled by default")
+	}
+	if !resolvePluginEnabled("Pat", []string{"pat"}, datatypes.JSON(`invalid`)) {
+		t.Fatal("invalid switches should be ignored")
+	}
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/pkg/robot"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/vars"
+)
+
+const (
+	sessionDegradedThreshold     = 3 // 连续心跳失败多少次后开始重连
+	sessionMaxReconnectAttempts  = 5 // 二次登录的最大尝试次数，之后推送登录确认到手机
+	sessionBaseReconnectBackoff  = 5 * time.Second
+	sessionMaxReconnectBackoff   = 2 * time.Minute
+	sessionAwaitScanTimeout      = 5 * time.Minute
+	sessionAwaitScanPollInterval = 5 * time.Second
+)
+
+// RobotSessionHook 会话状态变更回调，在状态变更的协程中同步执行，耗时的操作需要自行异步处理
+type RobotSessionHook func(from, to model.RobotSessionState, reason string)
+
+type robotSessionHook struct {
+	name string
+	fn   RobotSessionHook
+}
+
+// RobotSession 机器人会话状态机
+// online 心跳失败进入 degraded，连续失败或者收到退出登录后进入 reconnecting 按退避间隔尝试二次登录，
+// 二次登录都失败后推送登录确认到手机进入 awaiting_scan，超时未确认进入 offline；任何状态登录成功后回到 online
+type RobotSession struct {
+	mu        sync.Mutex
+	state     model.RobotSessionState
+	since     time.Time
+	failures  int
+	attempts  int
+	reconnect context.Context
+	cancel    context.CancelFunc
+	hooks     []robotSessionHook
+}
+
+var (
+	robotSession     *RobotSession
+	robotSessionOnce sync.Once
+)
+
+// GetRobotSession 获取全局唯一的会话状态机
+func GetRobotSession() *RobotSession {
+	robotSessionOnce.Do(func() {
+		robotSession = &RobotSession{
+			state: model.RobotSessionStateOffline,
+			since: time.Now(),
+		}
+		robotSession.OnTransition("定时任务", pauseCronOnSessionTransition)
+		robotSession.OnTransition("离线通知", notifyOnSessionTransition)
+		robotSession.OnTransition("webhook", webhookOnSessionTransition)
+	})
+	return robotSession
+}
+
+// OnTransition 注册状态变更回调
+func (s *RobotSession) OnTransition(name string, hook RobotSessionHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, robotSessionHook{name: name, fn: hook})
+}
+
+func (s *RobotSession) State() model.RobotSessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+func (s *RobotSession) Status() dto.RobotSessionStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return dto.RobotSessionStatus{
+		State:    string(s.state),
+		Since:    s.since.Unix(),
+		Failures: s.failures,
+		Attempts: s.attempts,
+	}
+}
+
+// Recovering 是否正在重连或者等待手机确认登录
+func (s *RobotSession) Recovering() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cancel != nil
+}
+
+// setState 切换状态，记录变更历史并执行回调
+func (s *RobotSession) setState(to model.RobotSessionState, reason string) {
+	s.mu.Lock()
+	from := s.state
+	if from == to {
+		s.mu.Unlock()
+		return
+	}
+	s.state = to
+	s.since = time.Now()
+	if to == model.RobotSessionStateOnline {
+		s.failures = 0
+		s.attempts = 0
+	}
+	hooks := slices.Clone(s.hooks)
+	s.mu.Unlock()
+
+	log.Printf("[RobotSession] %s -> %s: %s", from, to, reason)
+	if utf8.RuneCountInString(reason) > 500 {
+		reason = string([]rune(reason)[:500])
+	}
+	if vars.DB != nil {
+		event := &model.RobotSessionEvent{FromState: from, ToState: to, Reason: reason}
+		if err := repository.NewRobotSessionEventRepo(context.Background(), vars.DB).Create(event); err != nil {
+			log.Printf("[RobotSession] 记录状态变更失败: %v", err)
+		}
+	}
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("[RobotSession] 回调[%s]执行失败: %v", hook.name, r)
+				}
+			}()
+			hook.fn(from, to, reason)
+		}()
+	}
+}
+
+// MarkOnline 登录成功，停止正在进行的重连
+func (s *RobotSession) MarkOnline(reason string) {
+	s.stopReconnect()
+	s.setState(model.RobotSessionStateOnline, reason)
+}
+
+// MarkOffline 机器人离线，停止正在进行的重连
+func (s *RobotSession) MarkOffline(reason string) {
+	s.stopReconnect()
+	s.setState(model.RobotSessionStateOffline, reason)
+}
+
+// Alive 心跳成功或者收到新消息，说明连接正常
+func (s *RobotSession) Alive() {
+	s.mu.Lock()
+	s.failures = 0
+	degraded := s.state == model.RobotSessionStateDegraded
+	s.mu.Unlock()
+	if degraded {
+		s.setState(model.RobotSessionStateOnline, "心跳恢复正常")
+	}
+}
+
+// HeartbeatFailed 心跳失败，连续失败达到阈值后开始重连，已退出登录时直接重连
+func (s *RobotSession) HeartbeatFailed(err error) {
+	if errors.Is(err, robot.ErrLoggedOut) {
+		s.LoggedOut(err.Error())
+		return
+	}
+	s.mu.Lock()
+	if s.state != model.RobotSessionStateOnline && s.state != model.RobotSessionStateDegraded {
+		s.mu.Unlock()
+		return
+	}
+	s.failures++
+	failures := s.failures
+	s.mu.Unlock()
+
+	reason := fmt.Sprintf("第 %d 次心跳失败: %v", failures, err)
+	if failures >= sessionDegradedThreshold {
+		s.startReconnect(reason, false)
+		return
+	}
+	s.setState(model.RobotSessionStateDegraded, reason)
+}
+
+// LoggedOut 协议服务返回已退出登录或者通知掉线，开始重连
+func (s *RobotSession) LoggedOut(reason string) {
+	s.startReconnect(reason, false)
+}
+
+// Reconnect 手动触发重连，离线状态下也会尝试
+func (s *RobotSession) Reconnect(reason string) error {
+	if vars.RobotRuntime.WxID == "" {
+		return errors.New("机器人还没有登录过，请扫码登录")
+	}
+	if !s.startReconnect(reason, true) {
+		return errors.New("正在重连中，请稍后")
+	}
+	return nil
+}
+
+func (s *RobotSession) startReconnect(reason string, force bool) bool {
+	s.mu.Lock()
+	if s.cancel != nil || vars.RobotRuntime.WxID == "" {
+		s.mu.Unlock()
+		return false
+	}
+	// 主动退出登录后不再自动重连
+	if s.state == model.RobotSessionStateOffline && !force {
+		s.mu.Unlock()
+		return false
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.reconnect = ctx
+	s.cancel = cancel
+	s.attempts = 0
+	s.mu.Unlock()
+
+	s.setState(model.RobotSessionStateReconnecting, reason)
+	go s.runReconnect(ctx)
+	return true
+}
+
+func (s *RobotSession) stopReconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+		s.reconnect = nil
+	}
+}
+
+func (s *RobotSession) runReconnect(ctx context.Context) {
+	defer func() {
+		s.mu.Lock()
+		if s.reconnect == ctx {
+			s.cancel()
+			s.cancel = nil
+			s.reconnect = nil
+		}
+		s.mu.Unlock()
+	}()
+
+	loginService := NewLoginService(context.Background())
+	for attempt := 1; attempt <= sessionMaxReconnectAttempts; attempt++ {
+		s.mu.Lock()
+		s.attempts = attempt
+		s.mu.Unlock()
+		err := vars.RobotRuntime.LoginTwiceAutoAuth()
+		if err == nil && vars.RobotRuntime.IsLoggedIn() {
+			vars.RobotRuntime.LoginTime = time.Now().Unix()
+			// 启动自动心跳失败时会话不会被标记为在线，按本次重连失败处理，继续退避重试
+			if err = loginService.Online(); err == nil {
+				return
+			}
+		}
+		log.Printf("[RobotSession] 第 %d 次重连失败: %v", attempt, err)
+		if !sleepContext(ctx, reconnectBackoff(attempt)) {
+			return
+		}
+	}
+
+	if s.awaitScan(ctx, loginService) || ctx.Err() != nil {
+		return
+	}
+	s.setState(model.RobotSessionStateOffline, "重连失败，需要重新扫码登录")
+	if err := loginService.Offline(); err != nil {
+		log.Printf("[RobotSession] 更新机器人离线状态失败: %v", err)
+	}
+}
+
+// awaitScan 推送登录确认到手机并等待确认，登录成功返回 true
+func (s *RobotSession) awaitScan(ctx context.Context, loginService *LoginService) bool {
+	resp, err := vars.RobotRuntime.Client.AwakenLogin(vars.RobotRuntime.WxID)
+	if err != nil || resp.Uuid == "" {
+		log.Printf("[RobotSession] 推送登录确认失败: %v", err)
+		return false
+	}
+	s.setState(model.RobotSessionStateAwaitingScan, "二次登录失败，已向手机推送登录确认")
+	deadline := time.Now().Add(sessionAwaitScanTimeout)
+	for time.Now().Before(deadline) {
+		if !sleepContext(ctx, sessionAwaitScanPollInterval) {
+			return false
+		}
+		checkResp, err := loginService.LoginCheck(resp.Uuid)
+		if err != nil {
+			log.Printf("[RobotSession] 检查登录确认状态失败: %v", err)
+			continue
+		}
+		if checkResp.AcctSectResp.Username != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// reconnectBackoff 第 attempt 次重连失败后的等待时间，按指数增长
+func reconnectBackoff(attempt int) time.Duration {
+	backoff := sessionBaseReconnectBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= sessionMaxReconnectBackoff {
+			return sessionMaxReconnectBackoff
+		}
+	}
+	return backoff
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// pauseCronOnSessionTransition 重连期间暂停定时任务，登录成功后由 Online 重新启动
+func pauseCronOnSessionTransition(from, to model.RobotSessionState, reason string) {
+	if vars.CronManager == nil {
+		return
+	}
+	if to == model.RobotSessionStateReconnecting || to == model.RobotSessionStateAwaitingScan {
+		vars.CronManager.Clear()
+	}
+}
+
+// notifyOnSessionTransition 心跳异常、等待确认登录、离线以及重连成功时发送通知
+func notifyOnSessionTransition(from, to model.RobotSessionState, reason string) {
+	var title string
+	switch to {
+	case model.RobotSessionStateDegraded:
+		title = "机器人发送心跳失败"
+	case model.RobotSessionStateAwaitingScan:
+		title = "机器人等待确认登录"
+	case model.RobotSessionStateOffline:
+		title = "机器人掉线通知"
+	case model.RobotSessionStateOnline:
+		if from != model.RobotSessionStateReconnecting && from != model.RobotSessionStateAwaitingScan {
+			return
+		}
+		title = "机器人重新上线"
+	default:
+		return
+	}
+	content := fmt.Sprintf("您的机器人（%s）%s：%s", vars.RobotRuntime.WxID, title, reason)
+	go func() {
+		loginService := NewLoginService(context.Background())
+		systemSettings, err := loginService.systemSettingsRepo.GetSystemSettings()
+		if err != nil || systemSettings == nil {
+			return
+		}
+		if systemSettings.OfflineNotificationEnabled == nil || !*systemSettings.OfflineNotificationEnabled {
+			return
+		}
+		if err := loginService.sendNotification(systemSettings, title, content); err != nil {
+			log.Printf("[RobotSession] 发送通知失败: %v", err)
+		}
+	}()
+}
+
+// webhookOnSessionTransition 将状态变更推送到 webhook
+func webhookOnSessionTransition(from, to model.RobotSessionState, reason string) {
+	if vars.Webhook.URL == "" {
+		return
+	}
+	body := dto.RobotSessionWebhook{
+		Event:     "session_state",
+		FromState: string(from),
+		ToState:   string(to),
+		Reason:    reason,
+		CreatedAt: time.Now().Unix(),
+	}
+	go postWebhook(body)
+}
+
+type RobotSessionService struct {
+	ctx       context.Context
+	eventRepo *repository.RobotSessionEvent
+}
+
+func NewRobotSessionService(ctx context.Context) *RobotSessionService {
+	return &RobotSessionService{
+		ctx:       ctx,
+		eventRepo: repository.NewRobotSessionEventRepo(ctx, vars.DB),
+	}
+}
+
+func (s *RobotSessionService) GetStatus() dto.RobotSessionStatus {
+	return GetRobotSession().Status()
+}
+
+func (s *RobotSessionService) GetHistory(req dto.RobotSessionHistoryRequest, pager appx.Pager) ([]*model.RobotSessionEvent, int64, error) {
+	return s.eventRepo.GetList(req, pager)
+}
+
+func (s *RobotSessionService) Reconnect() error {
+	return GetRobotSession().Reconnect("手动触发重连")
+}
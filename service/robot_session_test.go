@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"wechat-robot-client/model"
+	"wechat-robot-client/vars"
+)
+
+func TestReconnectBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1: 5 * time.Second,
+		2: 10 * time.Second,
+		3: 20 * time.Second,
+		6: 2 * time.Minute,
+		9: 2 * time.Minute,
+	}
+	for attempt, expected := range cases {
+		if got := reconnectBackoff(attempt); got != expected {
+			t.Errorf("attempt %d: expected %v, got %v", attempt, expected, got)
+		}
+	}
+}
+
+func TestRobotSessionTransitions(t *testing.T) {
+	db := vars.DB
+	vars.DB = nil
+	defer func() { vars.DB = db }()
+	session := &RobotSession{state: model.RobotSessionStateOffline}
+	var transitions []model.RobotSessionState
+	session.OnTransition("test", func(from, to model.RobotSessionState, reason string) {
+		transitions = append(transitions, to)
+	})
+
+	session.HeartbeatFailed(errors.New("timeout"))
+	if session.State() != model.RobotSessionStateOffline {
+		t.Fatalf("offline session should ignore heartbeat failures, got %s", session.State())
+	}
+	session.MarkOnline("登录成功")
+	session.HeartbeatFailed(errors.New("timeout"))
+	if session.State() != model.RobotSessionStateDegraded || session.Status().Failures != 1 {
+		t.Fatalf("expected degraded with 1 failure, got %+v", session.Status())
+	}
+	session.Alive()
+	if session.State() != model.RobotSessionStateOnline || session.Status().Failures != 0 {
+		t.Fatalf("expected online after heartbeat recovered, got %+v", session.Status())
+	}
+	session.MarkOffline("退出登录")
+	expected := []model.RobotSessionState{
+		model.RobotSessionStateOnline,
+		model.RobotSessionStateDegraded,
+		model.RobotSessionStateOnline,
+		model.RobotSessionStateOffline,
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("expected transitions %v, got %v", expected, transitions)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Fatalf("expected transitions %v, got %v", expected, transitions)
+		}
+	}
+}
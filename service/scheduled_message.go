@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/vars"
+)
+
+// 单次定时消息错过发送时间（如服务重启）后，仍然补发的最长时间
+const scheduledMessageCatchUpWindow = time.Hour
+
+type ScheduledMessageService struct {
+	ctx  context.Context
+	repo *repository.ScheduledMessage
+}
+
+func NewScheduledMessageService(ctx context.Context) *ScheduledMessageService {
+	return &ScheduledMessageService{
+		ctx:  ctx,
+		repo: repository.NewScheduledMessageRepo(ctx, vars.DB),
+	}
+}
+
+func scheduledMessageCronName(id int64) vars.CommonCron {
+	return vars.CommonCron(fmt.Sprintf("scheduled_message_%d", id))
+}
+
+// onceCronExpr 将单次发送时间转换为 cron 表达式，精确到分钟
+func onceCronExpr(sendAt int64) string {
+	t := time.Unix(sendAt, 0).In(time.Local)
+	return fmt.Sprintf("%d %d %d %d *", t.Minute(), t.Hour(), t.Day(), int(t.Month()))
+}
+
+func (s *ScheduledMessageService) applyRequest(job *model.ScheduledMessage, req dto.ScheduledMessageRequest) error {
+	var toWxIDs []string
+	for _, wxID := range req.ToWxIDs {
+		if wxID = strings.TrimSpace(wxID); wxID != "" {
+			toWxIDs = append(toWxIDs, wxID)
+		}
+	}
+	if len(toWxIDs) == 0 {
+		return errors.New("接收者不能为空")
+	}
+	msgType := model.ScheduledMessageType(req.Type)
+	switch msgType {
+	case model.ScheduledMessageTypeText:
+		if strings.TrimSpace(req.Content) == "" {
+			return errors.New("文本内容不能为空")
+		}
+	case model.ScheduledMessageTypeImage, model.ScheduledMessageTypeFile:
+		if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+			return errors.New("图片或文件地址无效")
+		}
+	case model.ScheduledMessageTypeApp:
+		if req.Content == "" || req.AppMsgType == 0 {
+			return errors.New("应用消息内容和类型不能为空")
+		}
+	default:
+		return fmt.Errorf("不支持的消息类型: %s", req.Type)
+	}
+	scheduleType := model.ScheduleType(req.ScheduleType)
+	switch scheduleType {
+	case model.ScheduleTypeOnce:
+		if req.SendAt <= time.Now().Unix() {
+			return errors.New("发送时间必须晚于当前时间")
+		}
+	case model.ScheduleTypeCron:
+		if _, err := cron.ParseStandard(req.CronExpr); err != nil {
+			return fmt.Errorf("cron 表达式无效: %w", err)
+		}
+	default:
+		return fmt.Errorf("不支持的定时类型: %s", req.ScheduleType)
+	}
+	toWxIDsBytes, err := json.Marshal(toWxIDs)
+	if err != nil {
+		return err
+	}
+
+	job.Name = req.Name
+	job.ToWxIDs = toWxIDsBytes
+	job.Type = msgType
+	job.Content = req.Content
+	job.URL = req.URL
+	job.AppMsgType = req.AppMsgType
+	job.ScheduleType = scheduleType
+	job.SendAt = 0
+	job.CronExpr = ""
+	if scheduleType == model.ScheduleTypeOnce {
+		job.SendAt = req.SendAt
+	} else {
+		job.CronExpr = req.CronExpr
+	}
+	if req.Enabled != nil {
+		job.Enabled = req.Enabled
+	}
+	if job.Enabled == nil {
+		enabled := true
+		job.Enabled = &enabled
+	}
+	return nil
+}
+
+func (s *ScheduledMessageService) Create(req dto.ScheduledMessageRequest) (*model.ScheduledMessage, error) {
+	job := &model.ScheduledMessage{}
+	if err := s.applyRequest(job, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(job); err != nil {
+		return nil, err
+	}
+	s.schedule(job)
+	return job, nil
+}
+
+func (s *ScheduledMessageService) Update(req dto.ScheduledMessageRequest) (*model.ScheduledMessage, error) {
+	job, err := s.repo.GetByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, errors.New("定时消息不存在")
+	}
+	if err := s.applyRequest(job, req); err != nil {
+		return nil, err
+	}
+	job.LastError = ""
+	if err := s.repo.Update(job); err != nil {
+		return nil, err
+	}
+	s.schedule(job)
+	return job, nil
+}
+
+// Cancel 取消定时消息，保留记录以便重新启用
+func (s *ScheduledMessageService) Cancel(id int64) error {
+	job, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return errors.New("定时消息不存在")
+	}
+	enabled := false
+	job.Enabled = &enabled
+	if err := s.repo.Update(job); err != nil {
+		return err
+	}
+	s.unschedule(job.ID)
+	return nil
+}
+
+func (s *ScheduledMessageService) GetList(req dto.ScheduledMessageListRequest, pager appx.Pager) ([]*model.ScheduledMessage, int64, error) {
+	return s.repo.GetList(req, pager)
+}
+
+// RegisterAll 将启用的定时消息注册到定时任务调度器，服务启动或定时任务重置后调用
+func (s *ScheduledMessageService) RegisterAll() {
+	jobs, err := s.repo.GetAllEnabled()
+	if err != nil {
+		log.Printf("[定时消息] 获取定时消息失败: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		s.schedule(job)
+	}
+	log.Printf("[定时消息] 已注册 %d 个定时消息", len(jobs))
+}
+
+func (s *ScheduledMessageService) unschedule(id int64) {
+	if vars.CronManager == nil {
+		return
+	}
+	if err := vars.CronManager.RemoveJob(scheduledMessageCronName(id)); err != nil {
+		log.Printf("[定时消息] 移除定时消息[%d]失败: %v", id, err)
+	}
+}
+
+func (s *ScheduledMessageService) schedule(job *model.ScheduledMessage) {
+	s.unschedule(job.ID)
+	if vars.CronManager == nil || !job.IsEnabled() {
+		return
+	}
+	id := job.ID
+	handler := func() {
+		NewScheduledMessageService(context.Background()).Run(id)
+	}
+	cronExpr := job.CronExpr
+	if job.ScheduleType == model.ScheduleTypeOnce {
+		now := time.Now()
+		if job.SendAt <= now.Unix() {
+			// 服务停止期间错过了发送时间
+			if now.Sub(time.Unix(job.SendAt, 0)) <= scheduledMessageCatchUpWindow {
+				go handler()
+			} else {
+				s.finish(job, errors.New("错过发送时间"))
+			}
+			return
+		}
+		cronExpr = onceCronExpr(job.SendAt)
+	}
+	if err := vars.CronManager.AddJob(scheduledMessageCronName(id), cronExpr, handler); err != nil {
+		log.Printf("[定时消息] 注册定时消息[%d]失败: %v", id, err)
+	}
+}
+
+// finish 单次定时消息执行后停用
+func (s *ScheduledMessageService) finish(job *model.ScheduledMessage, runErr error) {
+	enabled := false
+	job.Enabled = &enabled
+	if runErr != nil {
+		job.LastError = runErr.Error()
+	}
+	if err := s.repo.Update(job); err != nil {
+		log.Printf("[定时消息] 更新定时消息[%d]失败: %v", job.ID, err)
+	}
+	// 在调度器的任务协程里移除任务，避免和调度器互相等待
+	go s.unschedule(job.ID)
+}
+
+// Run 执行定时消息，将消息交给出站消息队列发送
+func (s *ScheduledMessageService) Run(id int64) {
+	job, err := s.repo.GetByID(id)
+	if err != nil {
+		log.Printf("[定时消息] 获取定时消息[%d]失败: %v", id, err)
+		return
+	}
+	if job == nil || !job.IsEnabled() {
+		s.unschedule(id)
+		return
+	}
+	now := time.Now()
+	// 单次消息的 cron 表达式每年都会触发，这里确认确实到了发送时间
+	if job.ScheduleType == model.ScheduleTypeOnce && now.Unix() < job.SendAt-60 {
+		return
+	}
+
+	runErr := s.enqueue(job)
+	job.RunCount++
+	job.LastRunAt = now.Unix()
+	job.LastError = ""
+	if runErr != nil {
+		log.Printf("[定时消息] 定时消息[%d]执行失败: %v", id, runErr)
+	}
+	if job.ScheduleType == model.ScheduleTypeOnce {
+		s.finish(job, runErr)
+		return
+	}
+	if runErr != nil {
+		job.LastError = runErr.Error()
+	}
+	if err := s.repo.Update(job); err != nil {
+		log.Printf("[定时消息] 更新定时消息[%d]失败: %v", id, err)
+	}
+}
+
+func (s *ScheduledMessageService) enqueue(job *model.ScheduledMessage) error {
+	toWxIDs, err := job.GetToWxIDs()
+	if err != nil {
+		return fmt.Errorf("解析接收者失败: %w", err)
+	}
+	outboundService := NewOutboundMessageService(s.ctx)
+	source := fmt.Sprintf("定时消息#%d", job.ID)
+	var errs []error
+	for _, toWxID := range toWxIDs {
+		switch job.Type {
+		case model.ScheduledMessageTypeText:
+			_, err = outboundService.EnqueueText(source, toWxID, job.Content)
+		case model.ScheduledMessageTypeImage:
+			_, err = outboundService.EnqueueImageURL(source, toWxID, job.URL)
+		case model.ScheduledMessageTypeFile:
+			_, err = outboundService.EnqueueFileURL(source, toWxID, job.URL)
+		case model.ScheduledMessageTypeApp:
+			_, err = outboundService.EnqueueApp(source, toWxID, job.AppMsgType, job.Content)
+		default:
+			err = fmt.Errorf("不支持的消息类型: %s", job.Type)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", toWxID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
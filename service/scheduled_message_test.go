@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+)
+
+func TestOnceCronExpr(t *testing.T) {
+	sendAt := time.Date(2026, time.March, 5, 8, 30, 0, 0, time.Local).Unix()
+	if expr := onceCronExpr(sendAt); expr != "30 8 5 3 *" {
+		t.Fatalf("unexpected cron expr: %s", expr)
+	}
+}
+
+func TestScheduledMessageApplyRequest(t *testing.T) {
+	s := &ScheduledMessageService{}
+	job := &model.ScheduledMessage{}
+	req := dto.ScheduledMessageRequest{
+		ToWxIDs:      []string{" 123@chatroom ", ""},
+		Type:         "text",
+		Content:      "开会了",
+		ScheduleType: "cron",
+		CronExpr:     "0 9 * * 1-5",
+	}
+	if err := s.applyRequest(job, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	toWxIDs, _ := job.GetToWxIDs()
+	if len(toWxIDs) != 1 || toWxIDs[0] != "123@chatroom" || !job.IsEnabled() {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	invalid := []dto.ScheduledMessageRequest{
+		{ToWxIDs: []string{"a"}, Type: "text", Content: "x", ScheduleType: "cron", CronExpr: "every day"},
+		{ToWxIDs: []string{"a"}, Type: "text", Content: "x", ScheduleType: "once", SendAt: time.Now().Add(-time.Minute).Unix()},
+		{ToWxIDs: []string{"a"}, Type: "image", URL: "/tmp/a.png", ScheduleType: "once", SendAt: time.Now().Add(time.Hour).Unix()},
+		{ToWxIDs: []string{" "}, Type: "text", Content: "x", ScheduleType: "cron", CronExpr: "* * * * *"},
+	}
+	for i, req := range invalid {
+		if err := s.applyRequest(&model.ScheduledMessage{}, req); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/robot"
 	"wechat-robot-client/repository"
 	"wechat-robot-client/vars"
 )
@@ -34,6 +37,12 @@ func (s *SystemSettingService) GetSystemSettings() (*model.SystemSettings, error
 
 func (s *SystemSettingService) SaveSystemSettings(req *model.SystemSettings) error {
 	var err error
+	if len(req.PacingBudgets) > 0 {
+		var budgets map[robot.ActionClass]model.PacingBudget
+		if err = json.Unmarshal(req.PacingBudgets, &budgets); err != nil {
+			return fmt.Errorf("限速预算格式错误: %w", err)
+		}
+	}
 	if req.ID == 0 {
 		systemSettings, err := s.systemSettingsRepo.GetSystemSettings()
 		if err != nil {
@@ -55,9 +64,67 @@ func (s *SystemSettingService) SaveSystemSettings(req *model.SystemSettings) err
 
 	// 更新全局 webhook 配置
 	s.updateWebhookConfig(req)
+	// 更新客户端限速配置
+	s.ApplyPacingConfig(req)
 	return nil
 }
 
+// ApplyPacingConfig 将系统设置中的限速配置应用到机器人客户端
+func (s *SystemSettingService) ApplyPacingConfig(req *model.SystemSettings) {
+	if vars.RobotRuntime.Client == nil {
+		return
+	}
+	vars.RobotRuntime.Client.SetPacing(robotPacingConfig(req))
+}
+
+// robotPacingConfig 将系统设置转换为客户端限速配置，未配置的项使用默认值
+func robotPacingConfig(req *model.SystemSettings) robot.PacingConfig {
+	config := robot.DefaultPacingConfig()
+	if req == nil {
+		return config
+	}
+	millisecond := func(value *int, target *time.Duration) {
+		if value != nil && *value >= 0 {
+			*target = time.Duration(*value) * time.Millisecond
+		}
+	}
+	if req.PacingEnabled != nil {
+		config.Enabled = *req.PacingEnabled
+	}
+	millisecond(req.PacingJitterMin, &config.JitterMin)
+	millisecond(req.PacingJitterMax, &config.JitterMax)
+	millisecond(req.PacingTypingDelay, &config.TypingDelayPerChar)
+	millisecond(req.PacingMaxTypingDelay, &config.MaxTypingDelay)
+	if req.PacingRiskBackoffFactor != nil {
+		config.RiskBackoffFactor = *req.PacingRiskBackoffFactor
+	}
+	if req.PacingRiskCooldown != nil && *req.PacingRiskCooldown >= 0 {
+		config.RiskCooldown = time.Duration(*req.PacingRiskCooldown) * time.Second
+	}
+	if len(req.PacingBudgets) == 0 {
+		return config
+	}
+	var budgets map[robot.ActionClass]model.PacingBudget
+	if err := json.Unmarshal(req.PacingBudgets, &budgets); err != nil {
+		return config
+	}
+	for class, budget := range budgets {
+		current, ok := config.Budgets[class]
+		if !ok {
+			continue
+		}
+		millisecond(budget.Interval, &current.Interval)
+		if budget.Burst != nil && *budget.Burst > 0 {
+			current.Burst = *budget.Burst
+		}
+		if budget.DailyQuota != nil && *budget.DailyQuota >= 0 {
+			current.DailyQuota = *budget.DailyQuota
+		}
+		config.Budgets[class] = current
+	}
+	return config
+}
+
 func (s *SystemSettingService) updateWebhookConfig(req *model.SystemSettings) {
 	if req.WebhookURL != nil {
 		vars.Webhook.URL = *req.WebhookURL
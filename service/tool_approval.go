@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openai/openai-go/v3"
+
+	"wechat-robot-client/dto"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/appx"
+	"wechat-robot-client/pkg/robotctx"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/vars"
+)
+
+// 未配置审批人的私聊对话，审批请求发到文件传输助手，由机器人主人在手机上回复
+const toolApprovalOwnerChat = "filehelper"
+
+const (
+	toolApprovalApproveWord = "同意"
+	toolApprovalRejectWord  = "拒绝"
+)
+
+type toolApprovalDecision struct {
+	status    model.ToolApprovalStatus
+	decidedBy string
+}
+
+// toolApprovalWaiters 正在等待审批结果的工具调用，key 为审批记录ID
+var toolApprovalWaiters sync.Map
+
+type ToolApprovalService struct {
+	ctx          context.Context
+	approvalRepo *repository.ToolApproval
+}
+
+func NewToolApprovalService(ctx context.Context) *ToolApprovalService {
+	return &ToolApprovalService{
+		ctx:          ctx,
+		approvalRepo: repository.NewToolApprovalRepo(ctx, vars.DB),
+	}
+}
+
+func (s *ToolApprovalService) GetList(req dto.ToolApprovalListRequest, pager appx.Pager) ([]*model.ToolApproval, int64, error) {
+	return s.approvalRepo.GetList(req, pager)
+}
+
+// ExpireStale 服务启动时调用，重启前等待审批的对话已经不存在了
+func (s *ToolApprovalService) ExpireStale() error {
+	return s.approvalRepo.ExpireAll()
+}
+
+// RequestApproval 创建审批记录并通知审批人，阻塞到审批完成或者超时，超时时间不超过 model.MaxToolApprovalTimeout，
+// 调用前需要通过 releaseMessageLane 让出会话，避免阻塞同一会话的其他消息
+func (s *ToolApprovalService) RequestApproval(robotCtx *robotctx.RobotContext, policy *model.ToolPolicy, toolCall openai.ChatCompletionMessageToolCallUnion) (model.ToolApprovalStatus, error) {
+	timeout := time.Duration(policy.GetApprovalTimeout()) * time.Second
+	approvers, err := json.Marshal(policy.Approvers)
+	if err != nil {
+		return "", err
+	}
+	approval := &model.ToolApproval{
+		MessageID:  robotCtx.MessageID,
+		ChatID:     robotCtx.FromWxID,
+		SenderWxID: robotCtx.SenderWxID,
+		ToolName:   toolCall.Function.Name,
+		Arguments:  toolCall.Function.Arguments,
+		Approvers:  approvers,
+		Status:     model.ToolApprovalStatusPending,
+		ExpiresAt:  time.Now().Add(timeout).Unix(),
+	}
+	if err := s.approvalRepo.Create(approval); err != nil {
+		return "", fmt.Errorf("创建审批记录失败: %w", err)
+	}
+	ch := make(chan toolApprovalDecision, 1)
+	toolApprovalWaiters.Store(approval.ID, ch)
+	defer toolApprovalWaiters.Delete(approval.ID)
+
+	if err := s.notifyApprovers(approval, policy.Approvers, timeout); err != nil {
+		s.approvalRepo.Decide(approval.ID, model.ToolApprovalStatusExpired, "")
+		return "", fmt.Errorf("发送审批请求失败: %w", err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case decision := <-ch:
+		return decision.status, nil
+	case <-timer.C:
+	case <-s.ctx.Done():
+	}
+	ok, err := s.approvalRepo.Decide(approval.ID, model.ToolApprovalStatusExpired, "")
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		// 超时的同时审批人做出了决定，以审批结果为准
+		select {
+		case decision := <-ch:
+			return decision.status, nil
+		case <-time.After(time.Second):
+		}
+	}
+	return model.ToolApprovalStatusExpired, nil
+}
+
+func (s *ToolApprovalService) notifyApprovers(approval *model.ToolApproval, approvers []string, timeout time.Duration) error {
+	arguments := approval.Arguments
+	if utf8.RuneCountInString(arguments) > 200 {
+		arguments = string([]rune(arguments)[:200]) + "..."
+	}
+	content := fmt.Sprintf("【工具审批 #%d】\n会话: %s\n发起人: %s\n工具: %s\n参数: %s\n\n请在 %d 分钟内回复「%s %d」或「%s %d」",
+		approval.ID, approval.ChatID, approval.SenderWxID, approval.ToolName, arguments,
+		max(int(timeout.Minutes()), 1), toolApprovalApproveWord, approval.ID, toolApprovalRejectWord, approval.ID)
+
+	targets := approvers
+	if len(targets) == 0 {
+		if strings.HasSuffix(approval.ChatID, "@chatroom") {
+			// 群聊中由群主或者群管理员在群里审批
+			targets = []string{approval.ChatID}
+		} else {
+			targets = []string{toolApprovalOwnerChat}
+		}
+	}
+	messageService := NewMessageService(context.Background())
+	var errs []error
+	for _, target := range targets {
+		if err := messageService.SendTextMessage(target, content); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+		}
+	}
+	if len(errs) == len(targets) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Decide 审批一条待审批记录，通过 REST 接口审批时 decidedBy 为空
+func (s *ToolApprovalService) Decide(id int64, approved bool, decidedBy string) error {
+	status := model.ToolApprovalStatusRejected
+	if approved {
+		status = model.ToolApprovalStatusApproved
+	}
+	ok, err := s.approvalRepo.Decide(id, status, decidedBy)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("审批记录不存在或者已经处理")
+	}
+	if ch, exists := toolApprovalWaiters.Load(id); exists {
+		select {
+		case ch.(chan toolApprovalDecision) <- toolApprovalDecision{status: status, decidedBy: decidedBy}:
+		default:
+		}
+	}
+	return nil
+}
+
+// HandleReply 处理审批人回复的「同意/拒绝」，消息被当作审批回复时返回 true，不再进入插件处理
+func (s *ToolApprovalService) HandleReply(message *model.Message) bool {
+	if message.Type != model.MsgTypeText {
+		return false
+	}
+	approved, id, ok := parseToolApprovalReply(message.Content)
+	if !ok {
+		return false
+	}
+	pending, err := s.approvalRepo.GetPending()
+	if err != nil {
+		log.Printf("获取待审批的工具调用失败: %v", err)
+		return false
+	}
+	for _, approval := range pending {
+		if id > 0 && approval.ID != id {
+			continue
+		}
+		if !s.canDecide(approval, message) {
+			continue
+		}
+		if err := s.Decide(approval.ID, approved, message.SenderWxID); err != nil {
+			log.Printf("审批工具调用[%d]失败: %v", approval.ID, err)
+			return false
+		}
+		reply := fmt.Sprintf("已%s工具调用 #%d（%s）", toolApprovalWord(approved), approval.ID, approval.ToolName)
+		if err := NewMessageService(context.Background()).SendTextMessage(message.FromWxID, reply); err != nil {
+			log.Printf("发送审批结果失败: %v", err)
+		}
+		return true
+	}
+	return false
+}
+
+// canDecide 配置了审批人时只接受审批人私聊回复；否则群聊中由群主、群管理员在原群回复，私聊由机器人主人在文件传输助手回复
+func (s *ToolApprovalService) canDecide(approval *model.ToolApproval, message *model.Message) bool {
+	var approvers []string
+	if len(approval.Approvers) > 0 {
+		if err := json.Unmarshal(approval.Approvers, &approvers); err != nil {
+			log.Printf("解析工具审批[%d]的审批人失败: %v", approval.ID, err)
+			return false
+		}
+	}
+	if len(approvers) > 0 {
+		return !message.IsChatRoom && slices.Contains(approvers, message.SenderWxID)
+	}
+	if message.SenderWxID == vars.RobotRuntime.WxID {
+		return message.FromWxID == toolApprovalOwnerChat
+	}
+	if !message.IsChatRoom || message.FromWxID != approval.ChatID {
+		return false
+	}
+	role, err := chatRoomMemberRole(s.ctx, vars.DB, message.FromWxID, message.SenderWxID)
+	if err != nil {
+		log.Printf("获取群成员角色失败: %v", err)
+		return false
+	}
+	return role == model.ToolPolicyRoleOwner || role == model.ToolPolicyRoleAdmin
+}
+
+// parseToolApprovalReply 解析「同意」「拒绝 12」这样的回复，id 为 0 表示最近的一条审批
+func parseToolApprovalReply(content string) (approved bool, id int64, ok bool) {
+	content = strings.TrimSpace(content)
+	var rest string
+	switch {
+	case strings.HasPrefix(content, toolApprovalApproveWord):
+		approved, rest = true, strings.TrimPrefix(content, toolApprovalApproveWord)
+	case strings.HasPrefix(content, toolApprovalRejectWord):
+		rest = strings.TrimPrefix(content, toolApprovalRejectWord)
+	default:
+		return false, 0, false
+	}
+	rest = strings.TrimPrefix(strings.TrimSpace(rest), "#")
+	if rest == "" {
+		return approved, 0, true
+	}
+	id, err := strconv.ParseInt(rest, 10, 64)
+	if err != nil || id <= 0 {
+		return false, 0, false
+	}
+	return approved, id, true
+}
+
+func toolApprovalWord(approved bool) string {
+	if approved {
+		return toolApprovalApproveWord
+	}
+	return toolApprovalRejectWord
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+
+	"wechat-robot-client/model"
+)
+
+func TestParseToolApprovalReply(t *testing.T) {
+	cases := []struct {
+		content  string
+		approved bool
+		id       int64
+		ok       bool
+	}{
+		{"同意", true, 0, true},
+		{" 拒绝 12 ", false, 12, true},
+		{"同意#3", true, 3, true},
+		{"同意你的看法", false, 0, false},
+		{"好的", false, 0, false},
+	}
+	for _, c := range cases {
+		approved, id, ok := parseToolApprovalReply(c.content)
+		if approved != c.approved || id != c.id || ok != c.ok {
+			t.Errorf("%q: expected (%v, %d, %v), got (%v, %d, %v)", c.content, c.approved, c.id, c.ok, approved, id, ok)
+		}
+	}
+}
+
+func TestToolPolicyRequiresApproval(t *testing.T) {
+	policy := &model.ToolPolicy{
+		RequireApproval: []string{model.ToolGroupKey(model.ToolGroupMCP, "admin")},
+		Roles: map[model.ToolPolicyRole]*model.ToolPolicy{
+			model.ToolPolicyRoleMember: {RequireApproval: []string{"execute_skill_script"}},
+		},
+	}
+	if !policy.RequiresApproval(model.ToolPolicyRoleOwner, "admin__kick", model.ToolGroupMCP, "mcp:admin") {
+		t.Error("mcp:admin tools should require approval")
+	}
+	if policy.RequiresApproval(model.ToolPolicyRoleAdmin, "execute_skill_script", model.ToolGroupSkill) {
+		t.Error("admins should run scripts without approval")
+	}
+	if !policy.RequiresApproval(model.ToolPolicyRoleMember, "execute_skill_script", model.ToolGroupSkill) {
+		t.Error("members should need approval to run scripts")
+	}
+	if policy.GetApprovalTimeout() != model.DefaultToolApprovalTimeout {
+		t.Errorf("unexpected default timeout %d", policy.GetApprovalTimeout())
+	}
+	policy.ApprovalTimeout = 24 * 3600
+	if policy.GetApprovalTimeout() != model.MaxToolApprovalTimeout {
+		t.Errorf("timeout should be capped, got %d", policy.GetApprovalTimeout())
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/robotctx"
+	"wechat-robot-client/repository"
+)
+
+// toolPolicy 单次对话生效的工具调用策略
+type toolPolicy struct {
+	policy *model.ToolPolicy
+	role   model.ToolPolicyRole
+}
+
+// resolveToolPolicy 加载当前会话的工具调用策略，群聊、好友未配置时使用全局配置
+func (s *AgentService) resolveToolPolicy(robotCtx *robotctx.RobotContext) (toolPolicy, error) {
+	result := toolPolicy{role: model.ToolPolicyRoleFriend}
+	if robotCtx == nil {
+		return result, nil
+	}
+	globalSettings, err := repository.NewGlobalSettingsRepo(s.ctx, s.db).GetGlobalSettings()
+	if err != nil {
+		return result, err
+	}
+	var data datatypes.JSON
+	if globalSettings != nil {
+		data = globalSettings.ToolPolicy
+	}
+	if strings.Contains(robotCtx.FromWxID, "@chatroom") {
+		chatRoomSettings, err := repository.NewChatRoomSettingsRepo(s.ctx, s.db).GetChatRoomSettings(robotCtx.FromWxID)
+		if err != nil {
+			return result, err
+		}
+		if chatRoomSettings != nil && len(chatRoomSettings.ToolPolicy) > 0 {
+			data = chatRoomSettings.ToolPolicy
+		}
+		result.role, err = chatRoomMemberRole(s.ctx, s.db, robotCtx.FromWxID, robotCtx.SenderWxID)
+		if err != nil {
+			return result, err
+		}
+	} else {
+		friendSettings, err := repository.NewFriendSettingsRepo(s.ctx, s.db).GetFriendSettings(robotCtx.FromWxID)
+		if err != nil {
+			return result, err
+		}
+		if friendSettings != nil && len(friendSettings.ToolPolicy) > 0 {
+			data = friendSettings.ToolPolicy
+		}
+	}
+	result.policy, err = model.ParseToolPolicy(data)
+	if err != nil {
+		return result, fmt.Errorf("tool_policy 格式错误: %w", err)
+	}
+	return result, nil
+}
+
+// chatRoomMemberRole 群成员在群里的角色：群主、群管理员或者普通成员
+func chatRoomMemberRole(ctx context.Context, db *gorm.DB, chatRoomID, wechatID string) (model.ToolPolicyRole, error) {
+	chatRoom, err := repository.NewContactRepo(ctx, db).GetByWechatID(chatRoomID)
+	if err != nil {
+		return "", err
+	}
+	if chatRoom != nil && chatRoom.ChatRoomOwner != "" && chatRoom.ChatRoomOwner == wechatID {
+		return model.ToolPolicyRoleOwner, nil
+	}
+	member, err := repository.NewChatRoomMemberRepo(ctx, db).GetChatRoomMember(chatRoomID, wechatID)
+	if err != nil {
+		return "", err
+	}
+	if member != nil && member.IsAdmin != nil && *member.IsAdmin {
+		return model.ToolPolicyRoleAdmin, nil
+	}
+	return model.ToolPolicyRoleMember, nil
+}
+
+// toolPolicyKeys 工具名称和所属的工具组
+func (s *AgentService) toolPolicyKeys(toolName string) []string {
+	switch {
+	case s.skillsManager.IsSkillTool(toolName):
+		return []string{toolName, model.ToolGroupSkill}
+	case s.internalToolsManager.IsOpenAITool(toolName):
+		return []string{toolName, model.ToolGroupInternal, model.ToolGroupKey(model.ToolGroupInternal, toolName)}
+	}
+	if serverName := s.mcpManager.ToolServerName(toolName); serverName != "" {
+		return []string{toolName, model.ToolGroupMCP, model.ToolGroupKey(model.ToolGroupMCP, serverName)}
+	}
+	return []string{toolName}
+}
+
+// filterTools 过滤掉当前会话不允许使用的工具，Skill 工具只要有一个 Skill 可用就保留
+func (s *AgentService) filterTools(p toolPolicy, tools []openai.ChatCompletionToolUnionParam) []openai.ChatCompletionToolUnionParam {
+	if p.policy == nil {
+		return tools
+	}
+	var skillNames []string
+	for _, summary := range s.skillsManager.GetAllSummaries() {
+		skillNames = append(skillNames, summary.Name)
+	}
+	filtered := make([]openai.ChatCompletionToolUnionParam, 0, len(tools))
+	for _, tool := range tools {
+		fn := tool.GetFunction()
+		if fn == nil {
+			continue
+		}
+		keys := s.toolPolicyKeys(fn.Name)
+		if !s.skillsManager.IsSkillTool(fn.Name) {
+			if p.policy.Allows(p.role, keys...) {
+				filtered = append(filtered, tool)
+			}
+			continue
+		}
+		for _, name := range skillNames {
+			if p.policy.Allows(p.role, append(keys[:len(keys):len(keys)], model.ToolGroupKey(model.ToolGroupSkill, name))...) {
+				filtered = append(filtered, tool)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// checkToolCall 拒绝当前会话不允许的工具调用，模型可能会调用没有提供给它的工具
+func (s *AgentService) checkToolCall(p toolPolicy, toolCall openai.ChatCompletionMessageToolCallUnion) error {
+	if p.policy == nil {
+		return nil
+	}
+	if !p.policy.Allows(p.role, s.toolCallKeys(toolCall)...) {
+		return fmt.Errorf("工具 %s 在当前会话中不可用，请不要再调用", toolCall.Function.Name)
+	}
+	return nil
+}
+
+// toolCallKeys 工具调用的名称和所属的工具组，Skill 工具按参数中的 Skill 名称归组
+func (s *AgentService) toolCallKeys(toolCall openai.ChatCompletionMessageToolCallUnion) []string {
+	name := toolCall.Function.Name
+	keys := s.toolPolicyKeys(name)
+	if s.skillsManager.IsSkillTool(name) {
+		var args struct {
+			SkillName string `json:"skill_name"`
+		}
+		if err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args); err == nil && args.SkillName != "" {
+			keys = append(keys, model.ToolGroupKey(model.ToolGroupSkill, args.SkillName))
+		}
+	}
+	return keys
+}
+
+// requiresApproval 工具调用前是否需要人工审批
+func (s *AgentService) requiresApproval(p toolPolicy, toolCall openai.ChatCompletionMessageToolCallUnion) bool {
+	if p.policy == nil {
+		return false
+	}
+	return p.policy.RequiresApproval(p.role, s.toolCallKeys(toolCall)...)
+}
+
+// waitForApproval 需要审批的工具在执行前等待审批人同意，拒绝或者超时返回错误
+func (s *AgentService) waitForApproval(robotCtx *robotctx.RobotContext, p toolPolicy, toolCall openai.ChatCompletionMessageToolCallUnion) error {
+	if robotCtx == nil || !s.requiresApproval(p, toolCall) {
+		return nil
+	}
+	// 等待审批期间不占用会话，同一会话的其他消息照常处理
+	releaseMessageLane(robotCtx.MessageID)
+	status, err := NewToolApprovalService(s.ctx).RequestApproval(robotCtx, p.policy, toolCall)
+	if err != nil {
+		return fmt.Errorf("工具 %s 需要人工审批，%w", toolCall.Function.Name, err)
+	}
+	switch status {
+	case model.ToolApprovalStatusApproved:
+		return nil
+	case model.ToolApprovalStatusRejected:
+		return fmt.Errorf("审批人拒绝了工具 %s 的调用，请告知用户该操作未执行", toolCall.Function.Name)
+	default:
+		return fmt.Errorf("工具 %s 的审批已超时，请告知用户该操作未执行", toolCall.Function.Name)
+	}
+}
+
+// validateToolPolicy 保存配置前检查工具调用策略的格式
+func validateToolPolicy(data datatypes.JSON) error {
+	policy, err := model.ParseToolPolicy(data)
+	if err != nil {
+		return fmt.Errorf("tool_policy 格式错误: %w", err)
+	}
+	if policy != nil && policy.ApprovalTimeout > model.MaxToolApprovalTimeout {
+		return fmt.Errorf("tool_policy 格式错误: 审批超时时间不能超过 %d 秒", model.MaxToolApprovalTimeout)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/skills"
+)
+
+func TestToolPolicyCheckToolCall(t *testing.T) {
+	agent := NewAgentService(context.Background(), nil, nil)
+	if err := agent.internalToolsManager.Initialize(); err != nil {
+		t.Fatalf("initialize internal tools: %v", err)
+	}
+	toolCall := func(name, args string) openai.ChatCompletionMessageToolCallUnion {
+		return openai.ChatCompletionMessageToolCallUnion{
+			Function: openai.ChatCompletionMessageFunctionToolCallFunction{Name: name, Arguments: args},
+		}
+	}
+	policy := &model.ToolPolicy{
+		Allow: []string{model.ToolGroupInternal, model.ToolGroupKey(model.ToolGroupSkill, "pdf")},
+		Deny:  []string{skills.ToolNameExecuteScript},
+		Roles: map[model.ToolPolicyRole]*model.ToolPolicy{
+			model.ToolPolicyRoleMember: {Deny: []string{model.ToolGroupKey(model.ToolGroupInternal, "search_memory")}},
+		},
+	}
+	cases := []struct {
+		role    model.ToolPolicyRole
+		call    openai.ChatCompletionMessageToolCallUnion
+		allowed bool
+	}{
+		{model.ToolPolicyRoleAdmin, toolCall("search_memory", "{}"), true},
+		{model.ToolPolicyRoleMember, toolCall("search_memory", "{}"), false},
+		{model.ToolPolicyRoleMember, toolCall("reply", "{}"), true},
+		{model.ToolPolicyRoleAdmin, toolCall(skills.ToolNameActivate, `{"skill_name":"pdf"}`), true},
+		{model.ToolPolicyRoleAdmin, toolCall(skills.ToolNameActivate, `{"skill_name":"shell"}`), false},
+		{model.ToolPolicyRoleAdmin, toolCall(skills.ToolNameExecuteScript, `{"skill_name":"pdf"}`), false},
+		{model.ToolPolicyRoleAdmin, toolCall("amap__search", "{}"), false},
+	}
+	for _, c := range cases {
+		err := agent.checkToolCall(toolPolicy{policy: policy, role: c.role}, c.call)
+		if (err == nil) != c.allowed {
+			t.Errorf("%s %s(%s): expected allowed=%v, got %v", c.role, c.call.Function.Name, c.call.Function.Arguments, c.allowed, err)
+		}
+	}
+	if err := agent.checkToolCall(toolPolicy{role: model.ToolPolicyRoleMember}, toolCall("amap__search", "{}")); err != nil {
+		t.Errorf("no policy should allow every tool, got %v", err)
+	}
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"wechat-robot-client/model"
+	"wechat-robot-client/pkg/airouter"
 	"wechat-robot-client/utils"
 
 	"github.com/openai/openai-go/v3"
@@ -16,17 +18,26 @@ func newOpenAIClient(apiKey, baseURL string) openai.Client {
 	)
 }
 
-func streamChatCompletionMessage(ctx context.Context, client *openai.Client, req openai.ChatCompletionNewParams) (openai.ChatCompletionMessage, error) {
-	stream := client.Chat.Completions.NewStreaming(ctx, req)
-	acc := openai.ChatCompletionAccumulator{}
-	for stream.Next() {
-		acc.AddChunk(stream.Current())
-	}
-	if err := stream.Err(); err != nil {
-		return openai.ChatCompletionMessage{}, err
-	}
-	if len(acc.Choices) == 0 {
-		return openai.ChatCompletionMessage{}, fmt.Errorf("empty response")
-	}
-	return acc.Choices[0].Message, nil
+// streamChatCompletionMessage 通过模型路由流式调用 AI 并汇总完整消息，req.Model 由路由决定，用量按 ctx 上标记的归属记录
+func streamChatCompletionMessage(ctx context.Context, router *airouter.Router, req openai.ChatCompletionNewParams) (openai.ChatCompletionMessage, error) {
+	req.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	var msg openai.ChatCompletionMessage
+	err := router.Do(ctx, airouter.NewRequest(req.Messages, false), func(client *openai.Client, endpoint airouter.Endpoint) error {
+		req.Model = endpoint.Model
+		stream := client.Chat.Completions.NewStreaming(ctx, req)
+		acc := openai.ChatCompletionAccumulator{}
+		for stream.Next() {
+			acc.AddChunk(stream.Current())
+		}
+		if err := stream.Err(); err != nil {
+			return err
+		}
+		recordAIUsage(ctx, aiUsageScopeFrom(ctx, model.AIUsageFeatureOther), endpoint.Model, 1, acc.Usage)
+		if len(acc.Choices) == 0 {
+			return fmt.Errorf("empty response")
+		}
+		msg = acc.Choices[0].Message
+		return nil
+	})
+	return msg, err
 }
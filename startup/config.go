@@ -104,6 +104,23 @@ func loadEnvConfig() {
 		vars.RobotStartTimeout = time.Duration(t) * time.Second
 	}
 
+	// 入站消息处理队列
+	vars.MessageQueueSettings.Workers = getEnvInt("MESSAGE_QUEUE_WORKERS", 16)
+	vars.MessageQueueSettings.Capacity = getEnvInt("MESSAGE_QUEUE_CAPACITY", 2000)
+	vars.MessageQueueSettings.ConversationSize = getEnvInt("MESSAGE_QUEUE_CONVERSATION_SIZE", 100)
+	vars.MessageQueueSettings.OverflowPolicy = os.Getenv("MESSAGE_QUEUE_OVERFLOW_POLICY")
+
+	// AI 流式分段回复
+	vars.AIStreamSettings.MinChunkSize = getEnvInt("AI_STREAM_MIN_CHUNK_SIZE", 60)
+	vars.AIStreamSettings.MinInterval = getEnvInt("AI_STREAM_MIN_INTERVAL", 1500)
+
+	// AI 对话插件限流
+	vars.PluginRateLimitSettings.Interval = getEnvInt("PLUGIN_RATE_LIMIT_INTERVAL", 3)
+	vars.PluginRateLimitSettings.Burst = getEnvInt("PLUGIN_RATE_LIMIT_BURST", 5)
+
+	// AI 执行记录保留天数
+	vars.AgentTraceRetentionDays = getEnvInt("AGENT_TRACE_RETENTION_DAYS", 7)
+
 	vars.ThirdPartyApiKey = os.Getenv("THIRD_PARTY_API_KEY")
 
 	vars.SliderAccessKey = os.Getenv("SLIDER_ACCESS_KEY")
@@ -118,3 +135,15 @@ func loadEnvConfig() {
 		vars.SkillsDir = DefaultSkillsDir
 	}
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Fatalf("%s 配置错误: %s", key, value)
+	}
+	return n
+}
@@ -0,0 +1,134 @@
+package startup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"wechat-robot-client/pkg/robot"
+	"wechat-robot-client/pkg/robot/robottest"
+	"wechat-robot-client/repository"
+	"wechat-robot-client/service"
+	"wechat-robot-client/vars"
+)
+
+// TestMessagePipelineE2E 使用模拟的微信服务端和 OpenAI 接口，验证消息同步、入库、AI 插件回复的完整流程
+// 需要真实的 MySQL 和 Redis，通过 E2E_TEST=1 以及和服务相同的环境变量启用
+// 测试会修改全局配置并写入消息，数据库名必须以 _test 结尾，避免误连生产库
+func TestMessagePipelineE2E(t *testing.T) {
+	if os.Getenv("E2E_TEST") == "" {
+		t.Skip("设置 E2E_TEST=1 以及 MySQL、Redis 环境变量后运行端到端测试")
+	}
+	if os.Getenv("WECHAT_CLIENT_PORT") == "" {
+		t.Setenv("WECHAT_CLIENT_PORT", "9000")
+	}
+	if err := LoadConfig(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	for _, db := range []string{vars.MysqlSettings.Db, vars.MysqlSettings.AdminDb} {
+		if !strings.HasSuffix(db, "_test") {
+			t.Fatalf("端到端测试只能使用专用的测试数据库，数据库名必须以 _test 结尾: %q", db)
+		}
+	}
+	if err := SetupVars(); err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	if err := AutoMigrate(); err != nil {
+		t.Fatalf("自动迁移失败: %v", err)
+	}
+	if err := SeedData(); err != nil {
+		t.Fatalf("种子数据初始化失败: %v", err)
+	}
+	RegisterMessagePlugin()
+	if err := InitAgent(); err != nil {
+		t.Fatalf("初始化智能体失败: %v", err)
+	}
+
+	wechatServer := robottest.NewServer()
+	defer wechatServer.Close()
+	aiServer := robottest.NewOpenAIServer("你好，我是机器人。")
+	defer aiServer.Close()
+
+	vars.RobotRuntime.WxID = "wxid_e2e_robot"
+	vars.RobotRuntime.Client = wechatServer.NewClient()
+
+	ctx := context.Background()
+	globalSettingsRepo := repository.NewGlobalSettingsRepo(ctx, vars.DB)
+	globalSettings, err := globalSettingsRepo.GetGlobalSettings()
+	if err != nil || globalSettings == nil {
+		t.Fatalf("获取全局配置失败: %v", err)
+	}
+	original := *globalSettings
+	t.Cleanup(func() {
+		vars.DB.Save(&original)
+	})
+	enabled := true
+	globalSettings.ChatAIEnabled = &enabled
+	globalSettings.ChatBaseURL = aiServer.BaseURL()
+	globalSettings.ChatAPIKey = "test"
+	globalSettings.ChatModel = "robottest"
+	if err := globalSettingsRepo.Update(globalSettings); err != nil {
+		t.Fatalf("更新全局配置失败: %v", err)
+	}
+
+	friendWxID := fmt.Sprintf("wxid_e2e_%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		vars.DB.Exec("DELETE FROM messages WHERE from_wxid = ? OR to_wxid = ?", friendWxID, friendWxID)
+		vars.DB.Exec("DELETE FROM contacts WHERE wechat_id = ?", friendWxID)
+		vars.DB.Exec("DELETE FROM friend_settings WHERE wechat_id = ?", friendWxID)
+		vars.DB.Exec("DELETE FROM agent_traces WHERE chat_id = ?", friendWxID)
+		vars.DB.Exec("DELETE FROM ai_usages WHERE chat_id = ?", friendWxID)
+	})
+	pushed := wechatServer.PushText(friendWxID, vars.RobotRuntime.WxID, "你好")
+	service.NewMessageService(ctx).SyncMessage()
+
+	calls := wechatServer.WaitCalls(robot.MsgSendTxt, 1, 30*time.Second)
+	if len(calls) == 0 {
+		t.Fatal("AI 没有回复")
+	}
+	var req robot.SendTextMessageRequest
+	if err := calls[0].Decode(&req); err != nil {
+		t.Fatalf("解析发送请求失败: %v", err)
+	}
+	if req.ToWxid != friendWxID || req.Content != "你好，我是机器人。" {
+		t.Fatalf("回复不符合预期: %+v", req)
+	}
+
+	requests := aiServer.Requests()
+	if len(requests) == 0 {
+		t.Fatal("没有请求 AI 接口")
+	}
+	var aiReq struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(requests[0], &aiReq); err != nil || aiReq.Model != "robottest" {
+		t.Fatalf("AI 请求不符合预期: %s", requests[0])
+	}
+	if !strings.Contains(string(requests[0]), "你好") {
+		t.Fatalf("AI 请求缺少用户消息: %s", requests[0])
+	}
+
+	msgRepo := repository.NewMessageRepo(ctx, vars.DB)
+	inbound, err := msgRepo.GetByMsgID(pushed.NewMsgId)
+	if err != nil || inbound == nil {
+		t.Fatalf("收到的消息没有入库: %v", err)
+	}
+	if inbound.FromWxID != friendWxID || inbound.SenderWxID != friendWxID || inbound.Content != "你好" {
+		t.Fatalf("入库的消息不符合预期: %+v", inbound)
+	}
+	// 等待插件处理结束，AI 上下文标记在回复发送之后写入
+	deadline := time.Now().Add(5 * time.Second)
+	for !inbound.IsAIContext && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		if inbound, err = msgRepo.GetByMsgID(pushed.NewMsgId); err != nil || inbound == nil {
+			t.Fatalf("获取消息失败: %v", err)
+		}
+	}
+	if !inbound.IsAIContext {
+		t.Fatal("收到的消息应该被标记为 AI 上下文")
+	}
+}
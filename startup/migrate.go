@@ -40,6 +40,16 @@ func autoMigrateTasks() []migrateTask {
 				&model.Skill{},
 				&model.SystemPrompt{},
 				&model.Contact{},
+				&model.MessageLocation{},
+				&model.MessageRecall{},
+				&model.OutboundMessage{},
+				&model.OutboundMessageAttempt{},
+				&model.ScheduledMessage{},
+				&model.RobotSessionEvent{},
+				&model.ToolApproval{},
+				&model.AgentTrace{},
+				&model.AIUsage{},
+				&model.AIProvider{},
 			},
 		},
 	}
@@ -98,9 +108,100 @@ func enumMigrations() []enumMigration {
 			column: "type",
 			sql:    "ALTER TABLE contacts MODIFY COLUMN type ENUM('friend','chat_room','official_account') NOT NULL COMMENT '联系人类型：friend-好友，chat_room-群组，official_account-公众号'",
 		},
+		{
+			table:  "outbound_messages",
+			column: "type",
+			sql:    "ALTER TABLE outbound_messages MODIFY COLUMN type ENUM('text','long_text','image_url','image_file','file_url','app') NOT NULL",
+		},
 	}
 }
 
+type columnMigration struct {
+	table    string
+	model    any
+	field    string
+	index    string   // 不为空时新增的是模型上定义的索引，而不是列
+	prepare  []string // 新增索引前执行的 SQL
+	conflict string   // 返回冲突数据条数的 SQL，有冲突时跳过新增索引，不自动删除数据
+	replaces string   // 新增索引后删除的旧索引
+}
+
+// columnMigrations 不参与自动迁移的表（如 messages 含联表只读字段）新增的列和索引
+func columnMigrations() []columnMigration {
+	return []columnMigration{
+		{
+			table: "messages",
+			model: &model.Message{},
+			field: "Transcript",
+		},
+		{
+			table: "messages",
+			model: &model.Message{},
+			index: "uk_msg_id",
+			prepare: []string{
+				// 没有 msg_id 的消息改为负数的主键，避免互相冲突
+				"UPDATE messages SET msg_id = -id WHERE msg_id = 0 OR msg_id IS NULL",
+			},
+			conflict: "SELECT COUNT(*) FROM (SELECT msg_id FROM messages WHERE msg_id <> 0 GROUP BY msg_id HAVING COUNT(*) > 1) t",
+			replaces: "idx_messages_msg_id",
+		},
+	}
+}
+
+func migrateAddIndex(db *gorm.DB, m columnMigration) error {
+	// 仅当表存在且索引不存在时才执行
+	if !db.Migrator().HasTable(m.table) || db.Migrator().HasIndex(m.model, m.index) {
+		return nil
+	}
+	if m.conflict != "" {
+		var conflicts int64
+		if err := db.Raw(m.conflict).Scan(&conflicts).Error; err != nil {
+			return fmt.Errorf("检查索引冲突数据失败 [%s.%s]: %w", m.table, m.index, err)
+		}
+		if conflicts > 0 {
+			log.Printf("[column migrate] %s.%s 存在 %d 组重复数据，跳过新增索引，请手动清理后重启", m.table, m.index, conflicts)
+			return nil
+		}
+	}
+	for _, sql := range m.prepare {
+		result := db.Exec(sql)
+		if result.Error != nil {
+			return fmt.Errorf("新增索引前处理数据失败 [%s.%s]: %w", m.table, m.index, result.Error)
+		}
+		log.Printf("[column migrate] %s.%s 预处理影响 %d 行", m.table, m.index, result.RowsAffected)
+	}
+	if err := db.Migrator().CreateIndex(m.model, m.index); err != nil {
+		return fmt.Errorf("新增索引失败 [%s.%s]: %w", m.table, m.index, err)
+	}
+	if m.replaces != "" && db.Migrator().HasIndex(m.model, m.replaces) {
+		if err := db.Migrator().DropIndex(m.model, m.replaces); err != nil {
+			return fmt.Errorf("删除旧索引失败 [%s.%s]: %w", m.table, m.replaces, err)
+		}
+	}
+	log.Printf("[column migrate] %s.%s 索引新增完成", m.table, m.index)
+	return nil
+}
+
+func migrateAddColumns(db *gorm.DB) error {
+	for _, m := range columnMigrations() {
+		if m.index != "" {
+			if err := migrateAddIndex(db, m); err != nil {
+				return err
+			}
+			continue
+		}
+		// 仅当表存在且列不存在时才执行
+		if !db.Migrator().HasTable(m.table) || db.Migrator().HasColumn(m.model, m.field) {
+			continue
+		}
+		if err := db.Migrator().AddColumn(m.model, m.field); err != nil {
+			return fmt.Errorf("新增列失败 [%s.%s]: %w", m.table, m.field, err)
+		}
+		log.Printf("[column migrate] %s.%s 新增完成", m.table, m.field)
+	}
+	return nil
+}
+
 func migrateEnumColumns(db *gorm.DB) error {
 	for _, m := range enumMigrations() {
 		// 仅当表存在时才执行
@@ -135,6 +236,10 @@ func AutoMigrate() error {
 		if err := migrateEnumColumns(db); err != nil {
 			return err
 		}
+
+		if err := migrateAddColumns(db); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -1,6 +1,8 @@
 package startup
 
 import (
+	"time"
+
 	"wechat-robot-client/plugin"
 	"wechat-robot-client/plugin/plugins"
 	"wechat-robot-client/vars"
@@ -8,14 +10,30 @@ import (
 
 func RegisterMessagePlugin() {
 	vars.MessagePlugin = plugin.NewMessagePlugin()
+	// 指令，自动生成 #帮助
+	vars.MessagePlugin.Commands.RobotWxID = func() string {
+		return vars.RobotRuntime.WxID
+	}
+	vars.MessagePlugin.Commands.Register(plugins.NewSwitchChatModelCommand())
+	vars.MessagePlugin.Commands.Register(plugins.NewChatRoomMemberBlacklistCommand())
+	vars.MessagePlugin.Commands.Register(plugins.NewKnowledgeBaseCommand())
+	vars.MessagePlugin.Commands.Register(plugins.NewSliderAccessSecretCommand())
+	vars.MessagePlugin.Commands.Register(plugins.NewPodcastCommand())
+	// 全局拦截器，先注册的位于外层
+	vars.MessagePlugin.Use(
+		plugins.NewTimingInterceptor(),
+		plugins.NewAuditInterceptor(),
+		plugins.NewBlacklistInterceptor(),
+		plugins.NewRateLimitInterceptor(
+			time.Duration(vars.PluginRateLimitSettings.Interval)*time.Second,
+			vars.PluginRateLimitSettings.Burst,
+		),
+	)
 	// 群聊聊天插件
 	vars.MessagePlugin.Register(plugins.NewChatRoomAIChatPlugin())
-	vars.MessagePlugin.Register(plugins.NewChatRoomMemberBlacklistPlugin())
-	vars.MessagePlugin.Register(plugins.NewSwitchChatModelPlugin())
-	vars.MessagePlugin.Register(plugins.NewSliderAccessSecretPlugin())
 	vars.MessagePlugin.Register(plugins.NewChatRoomWxhbNotifyPlugin())
-	vars.MessagePlugin.Register(plugins.NewPodcastPlugin())
-	vars.MessagePlugin.Register(plugins.NewKnowledgeBasePlugin())
+	// 群聊防撤回插件
+	vars.MessagePlugin.Register(plugins.NewChatRoomAntiRecallPlugin())
 	// 朋友聊天插件
 	vars.MessagePlugin.Register(plugins.NewFriendAIChatPlugin())
 	// 群聊拍一拍交互插件
@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"time"
+	"wechat-robot-client/model"
 	"wechat-robot-client/pkg/robot"
 	"wechat-robot-client/service"
 	"wechat-robot-client/vars"
@@ -54,8 +55,10 @@ func InitWechatRobot() error {
 
 	vars.RobotRuntime.Client = client
 
-	systemSettings, err := service.NewSystemSettingService(context.Background()).GetSystemSettings()
+	systemSettingService := service.NewSystemSettingService(context.Background())
+	systemSettings, err := systemSettingService.GetSystemSettings()
 	if err == nil && systemSettings != nil {
+		systemSettingService.ApplyPacingConfig(systemSettings)
 		if systemSettings.WebhookURL != nil {
 			vars.Webhook.URL = *systemSettings.WebhookURL
 		}
@@ -87,11 +90,18 @@ func InitWechatRobot() error {
 					}
 				} else {
 					log.Println("微信机器人服务端未登录")
+					wasOnline := vars.RobotRuntime.Status == model.RobotStatusOnline
 					err := service.NewLoginService(context.Background()).Offline()
 					if err != nil {
 						log.Println("微信机器人服务端未登录，设置离线状态失败:", err)
 						return err
 					}
+					// 上次退出时还在线，说明是意外掉线，尝试自动恢复登录
+					if wasOnline && vars.RobotRuntime.WxID != "" {
+						if err := service.GetRobotSession().Reconnect("客户端启动时登录已失效"); err != nil {
+							log.Println("自动恢复登录失败:", err)
+						}
+					}
 				}
 				return nil
 			} else {
@@ -17,6 +17,7 @@ const (
 	MorningCron               CommonCron = "morning_cron"
 	FriendSyncCron            CommonCron = "friend_sync_cron"
 	SessionSummarizeCron      CommonCron = "session_summarize_cron"
+	AgentTraceCleanupCron     CommonCron = "agent_trace_cleanup_cron"
 )
 
 type TaskHandler func()
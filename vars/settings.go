@@ -34,7 +34,30 @@ type RabbitmqSettingS struct {
 	Vhost    string
 }
 
+// MessageQueueSettingS 入站消息处理队列配置
+type MessageQueueSettingS struct {
+	Workers          int    // 并发处理消息的 worker 数量
+	Capacity         int    // 队列总容量
+	ConversationSize int    // 单个会话的队列容量
+	OverflowPolicy   string // 队列满时的处理策略：block-阻塞等待，drop_newest-丢弃新消息，drop_oldest-丢弃最早的消息
+}
+
+// AIStreamSettingS AI 流式分段回复配置
+type AIStreamSettingS struct {
+	MinChunkSize int // 每段回复的最少字数
+	MinInterval  int // 两段回复之间的最短间隔(毫秒)
+}
+
+// PluginRateLimitSettingS AI 对话插件按发送者限流的配置
+type PluginRateLimitSettingS struct {
+	Interval int // 令牌恢复间隔(秒)
+	Burst    int // 最多连续触发的次数
+}
+
 var MysqlSettings = &MysqlSettingS{}
 var RedisSettings = &RedisSettingS{}
 var QdrantSettings = &QdrantSettingS{}
 var RabbitmqSettings = &RabbitmqSettingS{}
+var MessageQueueSettings = &MessageQueueSettingS{}
+var AIStreamSettings = &AIStreamSettingS{}
+var PluginRateLimitSettings = &PluginRateLimitSettingS{}
@@ -65,6 +65,9 @@ var ThirdPartyApiKey string
 
 var WordCloudUrl string
 
+// AI 执行记录保留天数
+var AgentTraceRetentionDays int
+
 // Pprof 代理目标地址
 var PprofProxyURL string
 